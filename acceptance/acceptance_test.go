@@ -8,10 +8,10 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"io"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -19,6 +19,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	dockertypes "github.com/docker/docker/api/types"
@@ -35,6 +36,7 @@ import (
 	"github.com/buildpack/pack/builder"
 	"github.com/buildpack/pack/cache"
 	"github.com/buildpack/pack/internal/archive"
+	"github.com/buildpack/pack/internal/paths"
 	"github.com/buildpack/pack/style"
 	h "github.com/buildpack/pack/testhelpers"
 )
@@ -157,20 +159,20 @@ lifecycle:
 			combo.lifecycleDescriptor.API.PlatformVersion,
 		)
 
-		bldr := createBuilder(t, runImageMirror, combo.builderTomlPath, combo.packCreateBuilderPath, combo.lifecyclePath, combo.lifecycleDescriptor)
+		bldr, sources := createBuilder(t, runImageMirror, combo.builderTomlPath, combo.packCreateBuilderPath, combo.lifecyclePath, combo.lifecycleDescriptor)
 		//noinspection ALL
 		defer h.DockerRmi(dockerCli, bldr)
 
 		combo := combo
 		suite(k, func(t *testing.T, when spec.G, it spec.S) {
-			testAcceptance(t, when, it, bldr, runImageMirror, combo.packFixturesDir, combo.packPath, combo.lifecycleDescriptor)
+			testAcceptance(t, when, it, bldr, sources, runImageMirror, combo.packFixturesDir, combo.packPath, combo.lifecycleDescriptor)
 		}, spec.Report(report.Terminal{}))
 	}
 
 	suite.Run(t)
 }
 
-func testAcceptance(t *testing.T, when spec.G, it spec.S, builder, runImageMirror, packFixturesDir, packPath string, lifecycleDescriptor builder.LifecycleDescriptor) {
+func testAcceptance(t *testing.T, when spec.G, it spec.S, builder string, sources builderSources, runImageMirror, packFixturesDir, packPath string, lifecycleDescriptor builder.LifecycleDescriptor) {
 
 	var bpDir = buildpacksDir(*lifecycleDescriptor.API.BuildpackVersion)
 
@@ -924,9 +926,13 @@ func testAcceptance(t *testing.T, when spec.G, it spec.S, builder, runImageMirro
 					"platform_api_version":  lifecycleDescriptor.API.PlatformVersion.String(),
 					"run_image_mirror":      runImageMirror,
 					"pack_version":          packVersion,
+					"lifecycle_source":      sources.Lifecycle,
+					"buildpack_sources":     sources.Buildpacks,
 				},
 			)
 
+			// Layer digests depend on how pack writes layers rather than on the files the builder was created from.
+			output = regexp.MustCompile(`Layer Digest: sha256:[0-9a-f]{64}`).ReplaceAllString(output, "Layer Digest: <layer-digest>")
 			h.AssertEq(t, output, expectedOutput)
 		})
 	})
@@ -1170,7 +1176,18 @@ func buildPack(t *testing.T) string {
 	return packPath
 }
 
-func createBuilder(t *testing.T, runImageMirror, builderTOMLPath, packPath, lifecyclePath string, lifecycleDescriptor builder.LifecycleDescriptor) string {
+// builderSources are the files a builder was created from, as recorded in its provenance.
+type builderSources struct {
+	Lifecycle  sourceFile
+	Buildpacks map[string]sourceFile
+}
+
+type sourceFile struct {
+	URI    string
+	Digest string
+}
+
+func createBuilder(t *testing.T, runImageMirror, builderTOMLPath, packPath, lifecyclePath string, lifecycleDescriptor builder.LifecycleDescriptor) (string, builderSources) {
 	t.Log("creating builder image...")
 
 	// CREATE TEMP WORKING DIR
@@ -1217,10 +1234,15 @@ func createBuilder(t *testing.T, runImageMirror, builderTOMLPath, packPath, life
 		"simple-layers-buildpack",
 	}
 
+	sources := builderSources{
+		Lifecycle:  lifecycleSource(t, lifecyclePath, lifecycleDescriptor),
+		Buildpacks: map[string]sourceFile{},
+	}
 	for _, v := range buildpacks {
 		tgz := h.CreateTGZ(t, filepath.Join(buildpacksDir, v), "./", 0755)
 		err := os.Rename(tgz, filepath.Join(tmpDir, v+".tgz"))
 		h.AssertNil(t, err)
+		sources.Buildpacks[v] = fileSource(t, filepath.Join(tmpDir, v+".tgz"))
 	}
 
 	// NAME BUILDER
@@ -1232,7 +1254,45 @@ func createBuilder(t *testing.T, runImageMirror, builderTOMLPath, packPath, life
 	h.AssertContains(t, output, fmt.Sprintf("Successfully created builder image '%s'", bldr))
 	h.AssertNil(t, h.PushImage(dockerCli, bldr, registryConfig))
 
-	return bldr
+	return bldr, sources
+}
+
+// lifecycleSource returns the source of the lifecycle at lifecyclePath, or of the release of the lifecycle version
+// when there is no path.
+func lifecycleSource(t *testing.T, lifecyclePath string, lifecycleDescriptor builder.LifecycleDescriptor) sourceFile {
+	t.Helper()
+	if lifecyclePath != "" {
+		return fileSource(t, lifecyclePath)
+	}
+
+	version := lifecycleDescriptor.Info.Version.String()
+	uri := fmt.Sprintf("https://github.com/buildpack/lifecycle/releases/download/v%s/lifecycle-v%s+linux.x86-64.tgz", version, version)
+	resp, err := http.Get(uri)
+	h.AssertNil(t, err)
+	defer resp.Body.Close()
+	h.AssertEq(t, resp.StatusCode, http.StatusOK)
+
+	return sourceFile{URI: uri, Digest: sha256Digest(t, resp.Body)}
+}
+
+func fileSource(t *testing.T, path string) sourceFile {
+	t.Helper()
+	uri, err := paths.FilePathToURI(path)
+	h.AssertNil(t, err)
+
+	fh, err := os.Open(path)
+	h.AssertNil(t, err)
+	defer fh.Close()
+
+	return sourceFile{URI: uri, Digest: sha256Digest(t, fh)}
+}
+
+func sha256Digest(t *testing.T, r io.Reader) string {
+	t.Helper()
+	hasher := sha256.New()
+	_, err := io.Copy(hasher, r)
+	h.AssertNil(t, err)
+	return fmt.Sprintf("sha256:%x", hasher.Sum(nil))
 }
 
 func createStack(t *testing.T, dockerCli *client.Client, runImageMirror string) {
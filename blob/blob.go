@@ -24,6 +24,25 @@ func NewBlob(path string) Blob {
 	return &blob{path: path}
 }
 
+// downloadedBlob is a blob downloaded over HTTP.
+type downloadedBlob struct {
+	blob
+	etag string
+}
+
+func (b *downloadedBlob) ETag() string {
+	return b.etag
+}
+
+// ETag returns the ETag the server sent for a blob downloaded over HTTP. It is empty for other blobs, and when the
+// server did not send one.
+func ETag(b Blob) string {
+	if e, ok := b.(interface{ ETag() string }); ok {
+		return e.ETag()
+	}
+	return ""
+}
+
 // Open returns an io.ReadCloser whose contents are in tar archive format
 func (b blob) Open() (r io.ReadCloser, err error) {
 	fi, err := os.Stat(b.path)
@@ -0,0 +1,644 @@
+package blob
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+const (
+	cacheDirPrefix = "c"
+	cacheVersion   = "3"
+	tmpFilePrefix  = ".tmp-"
+
+	// pinLockTimeout is how long a downloader may take to lock the pin file it created
+	pinLockTimeout = time.Minute
+)
+
+// versionedDirRegexp matches the directories of all versions of the cache, capturing their version.
+var versionedDirRegexp = regexp.MustCompile(`^` + cacheDirPrefix + `([0-9]+)$`)
+
+// CacheEntry records the blob last downloaded from a URI. Blobs are stored by the SHA-256 digest of their contents,
+// so that a blob is only stored once however many URIs it is downloaded from.
+type CacheEntry struct {
+	URI      string    `json:"uri"`
+	ETag     string    `json:"etag,omitempty"`
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+type PruneOptions struct {
+	// MaxSize removes the least recently used blobs until the cache is no larger than MaxSize bytes.
+	MaxSize int64
+	// OlderThan removes the blobs that have not been used for longer than OlderThan.
+	OlderThan time.Duration
+	// Keep are the digests of blobs that are never removed.
+	Keep []string
+}
+
+type PruneResult struct {
+	Removed int
+	Freed   int64
+}
+
+// Cache is the download cache of a downloader, indexed by the URIs blobs were downloaded from.
+type Cache struct {
+	baseDir string
+}
+
+func NewCache(baseCacheDir string) *Cache {
+	return &Cache{baseDir: baseCacheDir}
+}
+
+// List returns the entries of the cache, most recently used first.
+func (c *Cache) List() ([]CacheEntry, error) {
+	entries, err := c.readEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []CacheEntry
+	for _, entry := range entries {
+		if entry.Digest == "" {
+			continue
+		}
+
+		if exists, err := fileExists(c.blobPath(entry.Digest)); err != nil {
+			return nil, err
+		} else if exists {
+			list = append(list, entry.CacheEntry)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastUsed.After(list[j].LastUsed)
+	})
+	return list, nil
+}
+
+// Prune removes the blobs selected by opts, along with their entries and entries whose blob is missing. Blobs pinned
+// by downloaders that are still in use are never removed. Caches written by older versions of pack, which are not
+// read anymore, are removed entirely.
+func (c *Cache) Prune(opts PruneOptions) (PruneResult, error) {
+	if exists, err := fileExists(c.baseDir); err != nil || !exists {
+		return PruneResult{}, err
+	}
+
+	unlock, err := c.lockCache(true)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	defer unlock()
+
+	if err := c.removeOlderVersions(); err != nil {
+		return PruneResult{}, err
+	}
+
+	if err := c.removeIncompleteFiles(); err != nil {
+		return PruneResult{}, err
+	}
+
+	keep, err := c.pinnedDigests()
+	if err != nil {
+		return PruneResult{}, err
+	}
+	for _, digest := range opts.Keep {
+		keep[digest] = true
+	}
+
+	entries, err := c.readEntries()
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	type cachedBlob struct {
+		digest   string
+		size     int64
+		lastUsed time.Time
+		entries  []string
+	}
+
+	blobs := map[string]*cachedBlob{}
+	files, err := ioutil.ReadDir(c.blobsDir())
+	if err != nil && !os.IsNotExist(err) {
+		return PruneResult{}, errors.Wrap(err, "reading download cache")
+	}
+	for _, fi := range files {
+		if !sha256Regexp.MatchString(fi.Name()) {
+			continue
+		}
+		blobs[fi.Name()] = &cachedBlob{digest: fi.Name(), size: fi.Size(), lastUsed: fi.ModTime()}
+	}
+
+	for _, entry := range entries {
+		b, ok := blobs[entry.Digest]
+		if !ok {
+			if err := os.Remove(entry.path); err != nil {
+				return PruneResult{}, errors.Wrapf(err, "removing cache entry for %s", style.Symbol(entry.URI))
+			}
+			continue
+		}
+
+		if len(b.entries) == 0 || entry.LastUsed.After(b.lastUsed) {
+			b.lastUsed = entry.LastUsed
+		}
+		b.entries = append(b.entries, entry.path)
+	}
+
+	var (
+		sorted []*cachedBlob
+		total  int64
+	)
+	for _, b := range blobs {
+		sorted = append(sorted, b)
+		total += b.size
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].lastUsed.Before(sorted[j].lastUsed)
+	})
+
+	var result PruneResult
+	now := time.Now()
+	for _, b := range sorted {
+		expired := opts.OlderThan > 0 && now.Sub(b.lastUsed) > opts.OlderThan
+		oversized := opts.MaxSize > 0 && total > opts.MaxSize
+		if keep[b.digest] || (!expired && !oversized) {
+			continue
+		}
+
+		for _, path := range append(b.entries, c.blobPath(b.digest)) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return result, errors.Wrapf(err, "removing blob %s", style.Symbol("sha256:"+b.digest))
+			}
+		}
+
+		total -= b.size
+		result.Removed++
+		result.Freed += b.size
+	}
+
+	return result, nil
+}
+
+// Clear removes everything from the cache, including caches written by older versions of pack, except for the blobs
+// pinned by downloaders that are still in use.
+func (c *Cache) Clear() error {
+	if exists, err := fileExists(c.baseDir); err != nil || !exists {
+		return err
+	}
+
+	unlock, err := c.lockCache(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pinned, err := c.pinnedDigests()
+	if err != nil {
+		return err
+	}
+
+	// the cache lock is kept, so that processes waiting for it do not lock a file that no longer exists
+	for _, dir := range []string{c.baseDir, c.versionedDir()} {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return errors.Wrap(err, "reading download cache")
+		}
+
+		for _, fi := range files {
+			path := filepath.Join(dir, fi.Name())
+			switch {
+			case path == c.versionedDir() || path == c.cacheLockPath() || path == c.pinsDir():
+				continue
+			case path == filepath.Dir(c.blobsDir()) && len(pinned) > 0:
+				if err := c.removeBlobs(pinned); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := os.RemoveAll(path); err != nil {
+				return errors.Wrapf(err, "removing %s", style.Symbol(fi.Name()))
+			}
+		}
+	}
+	return nil
+}
+
+// removeOlderVersions removes the caches written by older versions of pack. Caches of newer versions are kept for the
+// versions of pack that use them.
+func (c *Cache) removeOlderVersions() error {
+	files, err := ioutil.ReadDir(c.baseDir)
+	if err != nil {
+		return errors.Wrap(err, "reading download cache")
+	}
+
+	current, err := strconv.Atoi(cacheVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range files {
+		match := versionedDirRegexp.FindStringSubmatch(fi.Name())
+		if !fi.IsDir() || match == nil {
+			continue
+		}
+
+		if version, err := strconv.Atoi(match[1]); err != nil || version >= current {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(c.baseDir, fi.Name())); err != nil {
+			return errors.Wrapf(err, "removing %s", style.Symbol(fi.Name()))
+		}
+	}
+	return nil
+}
+
+// removeBlobs removes all blobs but those in keep.
+func (c *Cache) removeBlobs(keep map[string]bool) error {
+	files, err := ioutil.ReadDir(c.blobsDir())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "reading download cache")
+	}
+
+	for _, fi := range files {
+		if keep[fi.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.blobsDir(), fi.Name())); err != nil {
+			return errors.Wrapf(err, "removing %s", style.Symbol(fi.Name()))
+		}
+	}
+	return nil
+}
+
+// createPinFile creates a pin file and locks it exclusively. The pin file of a downloader lists the digests of the
+// blobs it handed out, which must not be removed for as long as it holds the lock. It must be called while the cache
+// is locked, so that the pin file is not removed as stale before it is locked.
+func (c *Cache) createPinFile() (*os.File, error) {
+	if err := os.MkdirAll(c.pinsDir(), 0755); err != nil {
+		return nil, err
+	}
+
+	fh, err := ioutil.TempFile(c.pinsDir(), "pin-")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating pin file")
+	}
+
+	if err := lockFile(fh, true); err != nil {
+		fh.Close()
+		os.Remove(fh.Name())
+		return nil, errors.Wrap(err, "locking pin file")
+	}
+
+	// pins are otherwise only cleaned up by Prune and Clear. Other downloaders may be creating their pin files at the
+	// same time, so recent pin files are left alone in case they are not locked yet.
+	_, _ = c.readPins(pinLockTimeout)
+
+	return fh, nil
+}
+
+// pinnedDigests returns the digests listed by the pin files of downloaders that are still in use, and removes the pin
+// files of downloaders that are gone. It must only be called while the cache is locked exclusively.
+func (c *Cache) pinnedDigests() (map[string]bool, error) {
+	return c.readPins(0)
+}
+
+// readPins returns the digests listed by the pin files that are locked, and removes the pin files that are not
+// locked and were last modified at least minAge ago.
+func (c *Cache) readPins(minAge time.Duration) (map[string]bool, error) {
+	pinned := map[string]bool{}
+
+	files, err := ioutil.ReadDir(c.pinsDir())
+	if os.IsNotExist(err) {
+		return pinned, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "reading download cache")
+	}
+
+	for _, fi := range files {
+		stale := time.Since(fi.ModTime()) >= minAge
+		if err := readPinFile(filepath.Join(c.pinsDir(), fi.Name()), pinned, stale); err != nil {
+			return nil, err
+		}
+	}
+	return pinned, nil
+}
+
+// readPinFile adds the digests listed by the pin file at path to pinned when the pin file is locked. Otherwise, the
+// pin file is removed if it is stale.
+func readPinFile(path string, pinned map[string]bool, stale bool) error {
+	fh, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "opening pin file")
+	}
+	defer fh.Close()
+
+	acquired, err := tryLockFile(fh, true)
+	if err != nil {
+		return errors.Wrap(err, "locking pin file")
+	}
+	if acquired {
+		_ = unlockFile(fh)
+		fh.Close()
+		if !stale {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "removing stale pin file")
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		pinned[scanner.Text()] = true
+	}
+	return errors.Wrap(scanner.Err(), "reading pin file")
+}
+
+// removeIncompleteFiles removes the temporary files left behind by interrupted writes. It must only be called while the
+// cache is locked exclusively, when no write is in progress.
+func (c *Cache) removeIncompleteFiles() error {
+	for _, dir := range []string{c.blobsDir(), c.entriesDir()} {
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return errors.Wrap(err, "reading download cache")
+		}
+
+		for _, fi := range files {
+			if !strings.HasPrefix(fi.Name(), tmpFilePrefix) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, fi.Name())); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "removing incomplete download")
+			}
+		}
+	}
+	return nil
+}
+
+type indexedEntry struct {
+	CacheEntry
+	path string
+}
+
+func (c *Cache) readEntries() ([]indexedEntry, error) {
+	files, err := ioutil.ReadDir(c.entriesDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "reading download cache")
+	}
+
+	var entries []indexedEntry
+	for _, fi := range files {
+		if strings.HasPrefix(fi.Name(), tmpFilePrefix) {
+			continue
+		}
+
+		path := filepath.Join(c.entriesDir(), fi.Name())
+		entry, err := readEntryFile(path)
+		if err != nil && !isInvalidEntry(err) {
+			return nil, err
+		}
+		// invalid entries have no digest, so they are treated like entries whose blob is missing
+		entries = append(entries, indexedEntry{CacheEntry: entry, path: path})
+	}
+	return entries, nil
+}
+
+// readEntry returns the entry for uri, and false when there is none or its blob is missing.
+func (c *Cache) readEntry(uri string) (CacheEntry, bool, error) {
+	entry, err := readEntryFile(c.entryPath(uri))
+	if os.IsNotExist(errors.Cause(err)) || isInvalidEntry(err) {
+		return CacheEntry{}, false, nil
+	} else if err != nil {
+		return CacheEntry{}, false, err
+	}
+
+	if entry.Digest == "" {
+		return CacheEntry{}, false, nil
+	}
+
+	if exists, err := fileExists(c.blobPath(entry.Digest)); err != nil || !exists {
+		return CacheEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// writeEntry replaces the entry for entry.URI atomically, so that a crash never leaves a partially written entry.
+func (c *Cache) writeEntry(entry CacheEntry) error {
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "marshalling cache entry")
+	}
+
+	_, err = writeAtomically(c.entriesDir(), bytes.NewReader(entryBytes), func() (string, error) {
+		return c.entryPath(entry.URI), nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "writing cache entry")
+	}
+	return nil
+}
+
+// storeBlob writes the contents of reader to the cache by their digest and returns the digest and size. The contents
+// are not stored when expected is not empty and does not match their digest.
+func (c *Cache) storeBlob(reader io.Reader, uri, expected string) (string, int64, error) {
+	var digest string
+	hasher := sha256.New()
+	size, err := writeAtomically(c.blobsDir(), io.TeeReader(reader, hasher), func() (string, error) {
+		digest = hex.EncodeToString(hasher.Sum(nil))
+		if expected != "" {
+			if err := checkSHA256(uri, expected, digest); err != nil {
+				return "", err
+			}
+		}
+		return c.blobPath(digest), nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return digest, size, nil
+}
+
+// verifyBlob returns whether the blob with digest is in the cache and intact. Corrupt blobs are removed.
+func (c *Cache) verifyBlob(digest string) (bool, error) {
+	actual, err := fileSHA256(c.blobPath(digest))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "verifying cached blob %s", style.Symbol("sha256:"+digest))
+	}
+
+	if actual != digest {
+		if err := os.Remove(c.blobPath(digest)); err != nil && !os.IsNotExist(err) {
+			return false, errors.Wrapf(err, "removing corrupt blob %s", style.Symbol("sha256:"+digest))
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// writeAtomically copies reader to a temporary file in dir and syncs it to disk before renaming it to the path
+// returned by target, which must be in dir. Readers therefore only ever see complete files.
+func writeAtomically(dir string, reader io.Reader, target func() (string, error)) (int64, error) {
+	fh, err := ioutil.TempFile(dir, tmpFilePrefix)
+	if err != nil {
+		return 0, errors.Wrap(err, "creating cache file")
+	}
+	defer os.Remove(fh.Name())
+	defer fh.Close()
+
+	size, err := io.Copy(fh, reader)
+	if err != nil {
+		return 0, errors.Wrap(err, "writing cache")
+	}
+	if err := fh.Sync(); err != nil {
+		return 0, errors.Wrap(err, "writing cache")
+	}
+	if err := fh.Close(); err != nil {
+		return 0, errors.Wrap(err, "writing cache")
+	}
+
+	targetPath, err := target()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(fh.Name(), targetPath); err != nil {
+		return 0, errors.Wrap(err, "writing cache")
+	}
+	return size, nil
+}
+
+// lockCache locks the cache, shared by downloads or exclusively by operations that remove blobs. It returns a
+// function that releases the lock.
+func (c *Cache) lockCache(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(c.versionedDir(), 0755); err != nil {
+		return nil, err
+	}
+	return lock(c.cacheLockPath(), exclusive)
+}
+
+// lockURI locks the entry for uri exclusively, so that a URI is downloaded by one process at a time. It returns a
+// function that releases the lock.
+func (c *Cache) lockURI(uri string) (func(), error) {
+	return lock(filepath.Join(c.locksDir(), fmt.Sprintf("%x.lock", sha256.Sum256([]byte(uri)))), true)
+}
+
+func lock(path string, exclusive bool) (func(), error) {
+	fh, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening download cache lock")
+	}
+
+	if err := lockFile(fh, exclusive); err != nil {
+		fh.Close()
+		return nil, errors.Wrap(err, "locking download cache")
+	}
+
+	return func() {
+		_ = unlockFile(fh)
+		fh.Close()
+	}, nil
+}
+
+func (c *Cache) ensureDirs() error {
+	for _, dir := range []string{c.blobsDir(), c.entriesDir(), c.locksDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) versionedDir() string {
+	return filepath.Join(c.baseDir, cacheDirPrefix+cacheVersion)
+}
+
+func (c *Cache) blobsDir() string {
+	return filepath.Join(c.versionedDir(), "blobs", "sha256")
+}
+
+func (c *Cache) blobPath(digest string) string {
+	return filepath.Join(c.blobsDir(), digest)
+}
+
+func (c *Cache) entriesDir() string {
+	return filepath.Join(c.versionedDir(), "entries")
+}
+
+func (c *Cache) locksDir() string {
+	return filepath.Join(c.versionedDir(), "locks")
+}
+
+func (c *Cache) pinsDir() string {
+	return filepath.Join(c.versionedDir(), "pins")
+}
+
+func (c *Cache) cacheLockPath() string {
+	return filepath.Join(c.versionedDir(), "lock")
+}
+
+func (c *Cache) entryPath(uri string) string {
+	return filepath.Join(c.entriesDir(), fmt.Sprintf("%x.json", sha256.Sum256([]byte(uri))))
+}
+
+func readEntryFile(path string) (CacheEntry, error) {
+	var entry CacheEntry
+	entryBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return CacheEntry{}, errors.Wrap(err, "reading cache entry")
+	}
+
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return CacheEntry{}, invalidEntryError{errors.Wrapf(err, "unmarshalling cache entry %s", style.Symbol(path))}
+	}
+	return entry, nil
+}
+
+type invalidEntryError struct {
+	error
+}
+
+func isInvalidEntry(err error) bool {
+	_, ok := err.(invalidEntryError)
+	return ok
+}
+
+func fileExists(file string) (bool, error) {
+	_, err := os.Stat(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
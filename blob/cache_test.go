@@ -0,0 +1,306 @@
+package blob_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heroku/color"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestCache(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Cache", testCache, spec.Sequential(), spec.Report(report.Terminal{}))
+}
+
+func testCache(t *testing.T, when spec.G, it spec.S) {
+	var (
+		cacheDir string
+		server   *ghttp.Server
+		subject  *blob.Cache
+	)
+
+	serve := func(path, contents string) string {
+		server.RouteToHandler("GET", path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("ETag", "etag-"+path)
+			_, _ = w.Write([]byte(contents))
+		})
+		return server.URL() + path
+	}
+
+	download := func(opts ...blob.DownloaderOption) func(uri string) {
+		downloader := blob.NewDownloader(logging.New(ioutil.Discard), cacheDir, opts...)
+		return func(uri string) {
+			_, err := downloader.Download(context.TODO(), uri)
+			h.AssertNil(t, err)
+			h.AssertNil(t, downloader.Close())
+		}
+	}
+
+	// lastUsed rewrites the entry for uri as last used at the given time
+	lastUsed := func(uri string, at time.Time) {
+		path := filepath.Join(cacheDir, "c3", "entries", fmt.Sprintf("%x.json", sha256.Sum256([]byte(uri))))
+		contents, err := ioutil.ReadFile(path)
+		h.AssertNil(t, err)
+
+		var entry blob.CacheEntry
+		h.AssertNil(t, json.Unmarshal(contents, &entry))
+		entry.LastUsed = at
+		contents, err = json.Marshal(entry)
+		h.AssertNil(t, err)
+		h.AssertNil(t, ioutil.WriteFile(path, contents, 0644))
+	}
+
+	uris := func(entries []blob.CacheEntry) []string {
+		var list []string
+		for _, entry := range entries {
+			list = append(list, entry.URI)
+		}
+		return list
+	}
+
+	it.Before(func() {
+		var err error
+		cacheDir, err = ioutil.TempDir("", "cache")
+		h.AssertNil(t, err)
+		server = ghttp.NewServer()
+		subject = blob.NewCache(cacheDir)
+	})
+
+	it.After(func() {
+		server.Close()
+		h.AssertNil(t, os.RemoveAll(cacheDir))
+	})
+
+	when("#List", func() {
+		it("lists the downloads, most recently used first", func() {
+			small := serve("/small", "small")
+			large := serve("/large", strings.Repeat("large", 10))
+
+			download()(small)
+			download()(large)
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, uris(entries), []string{large, small})
+			h.AssertEq(t, entries[0].Size, int64(50))
+			h.AssertEq(t, entries[0].ETag, "etag-/large")
+			h.AssertEq(t, entries[1].Size, int64(5))
+		})
+
+		it("lists nothing when the cache does not exist", func() {
+			entries, err := blob.NewCache(cacheDir + "-missing").List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 0)
+		})
+	})
+
+	when("#Prune", func() {
+		var first, second, third string
+
+		it.Before(func() {
+			first = serve("/first", strings.Repeat("1", 10))
+			second = serve("/second", strings.Repeat("2", 10))
+			third = serve("/third", strings.Repeat("3", 10))
+
+			for _, uri := range []string{first, second, third} {
+				download()(uri)
+			}
+		})
+
+		it("removes the least recently used downloads beyond the max size", func() {
+			download()(first)
+
+			result, err := subject.Prune(blob.PruneOptions{MaxSize: 20})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result, blob.PruneResult{Removed: 1, Freed: 10})
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, uris(entries), []string{first, third})
+		})
+
+		it("removes downloads that have not been used for longer than a duration", func() {
+			lastUsed(first, time.Now().Add(-2*time.Hour))
+			lastUsed(third, time.Now().Add(-2*time.Hour))
+
+			result, err := subject.Prune(blob.PruneOptions{OlderThan: time.Hour})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Removed, 2)
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, uris(entries), []string{second})
+		})
+
+		it("keeps the given blobs", func() {
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+
+			result, err := subject.Prune(blob.PruneOptions{MaxSize: 1, Keep: []string{entries[2].Digest}})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Removed, 2)
+
+			entries, err = subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, uris(entries), []string{first})
+		})
+
+		it("removes files left behind by interrupted downloads", func() {
+			incomplete := filepath.Join(cacheDir, "c3", "blobs", "sha256", ".tmp-123")
+			h.AssertNil(t, ioutil.WriteFile(incomplete, []byte("incomplete"), 0644))
+
+			_, err := subject.Prune(blob.PruneOptions{MaxSize: 100})
+			h.AssertNil(t, err)
+
+			_, err = os.Stat(incomplete)
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
+
+		it("is run by the downloader when the cache grows larger than its max size", func() {
+			fourth := serve("/fourth", strings.Repeat("4", 10))
+			download(blob.WithMaxCacheSize(25))(fourth)
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, uris(entries), []string{fourth, third})
+		})
+
+		it("keeps every blob handed out by the downloader, even when they exceed its max size", func() {
+			downloader := blob.NewDownloader(logging.New(ioutil.Discard), cacheDir, blob.WithMaxCacheSize(15))
+			defer downloader.Close()
+
+			contents := []string{strings.Repeat("4", 10), strings.Repeat("5", 10), strings.Repeat("6", 10)}
+			var blobs []blob.Blob
+			for i, path := range []string{"/fourth", "/fifth", "/sixth"} {
+				b, err := downloader.Download(context.TODO(), serve(path, contents[i]))
+				h.AssertNil(t, err)
+				blobs = append(blobs, b)
+			}
+
+			for i, b := range blobs {
+				rc, err := b.Open()
+				h.AssertNil(t, err)
+				actual, err := ioutil.ReadAll(rc)
+				h.AssertNil(t, err)
+				h.AssertNil(t, rc.Close())
+				h.AssertEq(t, string(actual), contents[i])
+			}
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 3)
+		})
+
+		it("keeps the blobs of downloaders that are still in use", func() {
+			downloader := blob.NewDownloader(logging.New(ioutil.Discard), cacheDir)
+			defer downloader.Close()
+			_, err := downloader.Download(context.TODO(), first)
+			h.AssertNil(t, err)
+
+			result, err := subject.Prune(blob.PruneOptions{MaxSize: 1})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Removed, 2)
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, uris(entries), []string{first})
+
+			h.AssertNil(t, downloader.Close())
+			result, err = subject.Prune(blob.PruneOptions{MaxSize: 1})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Removed, 1)
+		})
+
+		it("removes the pins of downloaders that are gone", func() {
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+
+			stalePin := filepath.Join(cacheDir, "c3", "pins", "pin-stale")
+			h.AssertNil(t, os.MkdirAll(filepath.Dir(stalePin), 0755))
+			h.AssertNil(t, ioutil.WriteFile(stalePin, []byte(entries[0].Digest+"\n"), 0644))
+
+			result, err := subject.Prune(blob.PruneOptions{MaxSize: 1})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Removed, 3)
+
+			_, err = os.Stat(stalePin)
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
+
+		it("removes the caches of older versions but not those of newer versions", func() {
+			h.AssertNil(t, os.MkdirAll(filepath.Join(cacheDir, "c2", "blobs"), 0755))
+			h.AssertNil(t, os.MkdirAll(filepath.Join(cacheDir, "c4"), 0755))
+
+			_, err := subject.Prune(blob.PruneOptions{MaxSize: 100})
+			h.AssertNil(t, err)
+
+			files, err := ioutil.ReadDir(cacheDir)
+			h.AssertNil(t, err)
+			var names []string
+			for _, fi := range files {
+				names = append(names, fi.Name())
+			}
+			h.AssertEq(t, names, []string{"c3", "c4"})
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 3)
+		})
+	})
+
+	when("#Clear", func() {
+		it("removes all downloads, including those of older cache versions", func() {
+			download()(serve("/some", "contents"))
+			h.AssertNil(t, os.MkdirAll(filepath.Join(cacheDir, "c2"), 0755))
+
+			h.AssertNil(t, subject.Clear())
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 0)
+
+			files, err := ioutil.ReadDir(cacheDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(files), 1)
+			h.AssertEq(t, files[0].Name(), "c3")
+		})
+
+		it("keeps the blobs of downloaders that are still in use", func() {
+			downloader := blob.NewDownloader(logging.New(ioutil.Discard), cacheDir)
+			defer downloader.Close()
+			b, err := downloader.Download(context.TODO(), serve("/some", "contents"))
+			h.AssertNil(t, err)
+			download()(serve("/other", "other-contents"))
+
+			h.AssertNil(t, subject.Clear())
+
+			rc, err := b.Open()
+			h.AssertNil(t, err)
+			contents, err := ioutil.ReadAll(rc)
+			h.AssertNil(t, err)
+			h.AssertNil(t, rc.Close())
+			h.AssertEq(t, string(contents), "contents")
+
+			entries, err := subject.List()
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 0)
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// checksumFragment declares the expected SHA-256 digest of the blob at a path or URI, as in
+// https://example.com/bp.tgz#sha256=<hex>.
+const checksumFragment = "#sha256="
+
+var sha256Regexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// WithSHA256 returns pathOrURI with a fragment declaring the expected SHA-256 digest of its contents, which the
+// downloader verifies. It returns pathOrURI unchanged when digest is empty.
+func WithSHA256(pathOrURI, digest string) string {
+	if digest == "" {
+		return pathOrURI
+	}
+	return pathOrURI + checksumFragment + digest
+}
+
+// SplitSHA256 separates the expected SHA-256 digest declared by the fragment of pathOrURI from the path or URI. The
+// digest is empty when pathOrURI declares none.
+func SplitSHA256(pathOrURI string) (string, string) {
+	i := strings.LastIndex(pathOrURI, checksumFragment)
+	if i < 0 {
+		return pathOrURI, ""
+	}
+	return pathOrURI[:i], pathOrURI[i+len(checksumFragment):]
+}
+
+// normalizeSHA256 returns digest as lower case hex, accepting an optional "sha256:" prefix.
+func normalizeSHA256(digest string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(digest, "sha256:"))
+	if !sha256Regexp.MatchString(normalized) {
+		return "", fmt.Errorf("invalid sha256 checksum %s", style.Symbol(digest))
+	}
+	return normalized, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, fh); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func verifyFile(path, expected string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrapf(err, "verifying checksum of %s", style.Symbol(path))
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("checksum of directory %s cannot be verified", style.Symbol(path))
+	}
+
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return errors.Wrapf(err, "verifying checksum of %s", style.Symbol(path))
+	}
+	return checkSHA256(path, expected, actual)
+}
+
+func checkSHA256(source, expected, actual string) error {
+	if expected != actual {
+		return fmt.Errorf(
+			"checksum mismatch for %s: expected sha256 %s, got %s",
+			style.Symbol(source),
+			style.Symbol(expected),
+			style.Symbol(actual),
+		)
+	}
+	return nil
+}
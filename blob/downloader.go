@@ -2,14 +2,14 @@ package blob
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/mitchellh/ioprogress"
 	"github.com/pkg/errors"
@@ -19,24 +19,59 @@ import (
 	"github.com/buildpack/pack/style"
 )
 
-const (
-	cacheDirPrefix = "c"
-	cacheVersion   = "2"
-)
-
 type downloader struct {
 	logger       logging.Logger
-	baseCacheDir string
+	cache        *Cache
+	maxCacheSize int64
+	transport    http.RoundTripper
+
+	// pins is the pin file of the downloader, listing the digests of the blobs it handed out so that they are never
+	// pruned while it is in use. It is created when the first blob is handed out.
+	pins        *os.File
+	pinned      map[string]bool
+	pinnedMutex sync.Mutex
+}
+
+type DownloaderOption func(d *downloader)
+
+// WithMaxCacheSize prunes the least recently used blobs from the download cache whenever a download makes it larger
+// than size bytes. Blobs that are in use are never pruned, so the cache may stay larger than size.
+func WithMaxCacheSize(size int64) DownloaderOption {
+	return func(d *downloader) {
+		d.maxCacheSize = size
+	}
+}
+
+// WithTransport sets the transport used for HTTP downloads, http.DefaultTransport by default.
+func WithTransport(rt http.RoundTripper) DownloaderOption {
+	return func(d *downloader) {
+		d.transport = rt
+	}
 }
 
-func NewDownloader(logger logging.Logger, baseCacheDir string) *downloader { //nolint:golint,gosimple
-	return &downloader{
-		logger:       logger,
-		baseCacheDir: baseCacheDir,
+func NewDownloader(logger logging.Logger, baseCacheDir string, opts ...DownloaderOption) *downloader { //nolint:golint,gosimple
+	d := &downloader{
+		logger: logger,
+		cache:  NewCache(baseCacheDir),
+		pinned: map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	return d
 }
 
+// Download returns the blob at pathOrURI. When pathOrURI ends with a #sha256=<hex> fragment, the contents of the
+// blob are verified against the digest.
 func (d *downloader) Download(ctx context.Context, pathOrURI string) (Blob, error) {
+	pathOrURI, expected := SplitSHA256(pathOrURI)
+	if expected != "" {
+		var err error
+		if expected, err = normalizeSHA256(expected); err != nil {
+			return nil, err
+		}
+	}
+
 	if paths.IsURI(pathOrURI) {
 		parsedURL, err := url.Parse(pathOrURI)
 		if err != nil {
@@ -47,8 +82,11 @@ func (d *downloader) Download(ctx context.Context, pathOrURI string) (Blob, erro
 		switch parsedURL.Scheme {
 		case "file":
 			path, err = paths.URIToFilePath(pathOrURI)
+			if err == nil && expected != "" {
+				err = verifyFile(path, expected)
+			}
 		case "http", "https":
-			path, err = d.handleHTTP(ctx, pathOrURI)
+			return d.handleHTTP(ctx, pathOrURI, expected)
 		default:
 			err = fmt.Errorf("unsupported protocol %s in URI %s", style.Symbol(parsedURL.Scheme), style.Symbol(pathOrURI))
 		}
@@ -60,6 +98,11 @@ func (d *downloader) Download(ctx context.Context, pathOrURI string) (Blob, erro
 	}
 
 	path := d.handleFile(pathOrURI)
+	if expected != "" {
+		if err := verifyFile(path, expected); err != nil {
+			return nil, err
+		}
+	}
 
 	return &blob{path: path}, nil
 }
@@ -73,54 +116,161 @@ func (d *downloader) handleFile(path string) string {
 	return path
 }
 
-func (d *downloader) handleHTTP(ctx context.Context, uri string) (string, error) {
-	cacheDir := d.versionedCacheDir()
+func (d *downloader) handleHTTP(ctx context.Context, uri, expected string) (Blob, error) {
+	if err := d.cache.ensureDirs(); err != nil {
+		return nil, err
+	}
+
+	entry, downloaded, err := d.fetch(ctx, uri, expected)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", err
+	if downloaded && d.maxCacheSize > 0 {
+		result, err := d.cache.Prune(PruneOptions{MaxSize: d.maxCacheSize})
+		if err != nil {
+			return nil, errors.Wrap(err, "pruning download cache")
+		}
+		if result.Removed > 0 {
+			d.logger.Debugf("Pruned %d blob(s) from the download cache, freeing %d bytes", result.Removed, result.Freed)
+		}
 	}
 
-	cachePath := filepath.Join(cacheDir, fmt.Sprintf("%x", sha256.Sum256([]byte(uri))))
+	return &downloadedBlob{blob: blob{path: d.cache.blobPath(entry.Digest)}, etag: entry.ETag}, nil
+}
 
-	etagFile := cachePath + ".etag"
-	etagExists, err := fileExists(etagFile)
+// fetch returns the cache entry of the blob for uri, downloading it unless an intact blob is cached already. The
+// cache is locked for the duration, so that concurrent processes neither download uri at the same time nor see
+// partially written blobs, and the blob is pinned before the lock is released.
+func (d *downloader) fetch(ctx context.Context, uri, expected string) (CacheEntry, bool, error) {
+	unlockCache, err := d.cache.lockCache(false)
 	if err != nil {
-		return "", err
+		return CacheEntry{}, false, err
 	}
+	defer unlockCache()
 
-	etag := ""
-	if etagExists {
-		bytes, err := ioutil.ReadFile(etagFile)
-		if err != nil {
-			return "", err
+	entry, downloaded, err := d.fetchLocked(ctx, uri, expected)
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+	return entry, downloaded, d.pin(entry.Digest)
+}
+
+// fetchLocked is fetch for a locked cache.
+func (d *downloader) fetchLocked(ctx context.Context, uri, expected string) (CacheEntry, bool, error) {
+	unlockURI, err := d.cache.lockURI(uri)
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+	defer unlockURI()
+
+	entry, found, err := d.cache.readEntry(uri)
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+
+	if expected != "" {
+		if intact, err := d.cache.verifyBlob(expected); err != nil {
+			return CacheEntry{}, false, err
+		} else if intact {
+			d.logger.Debugf("Using cached blob %s for %s", style.Symbol("sha256:"+expected), style.Symbol(uri))
+			if !found || entry.Digest != expected {
+				entry = CacheEntry{URI: uri, Digest: expected}
+				if fi, err := os.Stat(d.cache.blobPath(expected)); err == nil {
+					entry.Size = fi.Size()
+				}
+			}
+			return entry, false, d.touch(entry)
 		}
-		etag = string(bytes)
 	}
 
-	reader, etag, err := d.downloadAsStream(ctx, uri, etag)
+	if found {
+		if intact, err := d.cache.verifyBlob(entry.Digest); err != nil {
+			return CacheEntry{}, false, err
+		} else if !intact {
+			d.logger.Debugf("Discarding corrupt cached blob %s for %s", style.Symbol("sha256:"+entry.Digest), style.Symbol(uri))
+			entry = CacheEntry{}
+		}
+	}
+
+	reader, etag, err := d.downloadAsStream(ctx, uri, entry.ETag)
 	if err != nil {
-		return "", err
+		return CacheEntry{}, false, err
 	} else if reader == nil {
-		return cachePath, nil
+		if expected != "" {
+			if err := checkSHA256(uri, expected, entry.Digest); err != nil {
+				return CacheEntry{}, false, err
+			}
+		}
+		return entry, false, d.touch(entry)
 	}
 	defer reader.Close()
 
-	fh, err := os.Create(cachePath)
+	digest, size, err := d.cache.storeBlob(reader, uri, expected)
 	if err != nil {
-		return "", errors.Wrapf(err, "create cache path %s", style.Symbol(cachePath))
+		return CacheEntry{}, false, err
+	}
+
+	entry = CacheEntry{URI: uri, ETag: etag, Digest: digest, Size: size}
+	if err := d.touch(entry); err != nil {
+		return CacheEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// pin adds the blob with digest to the pin file of the downloader. Blobs are opened long after they are downloaded,
+// so they must stay in the cache for as long as the downloader is used, by this process or by any other.
+func (d *downloader) pin(digest string) error {
+	d.pinnedMutex.Lock()
+	defer d.pinnedMutex.Unlock()
+
+	if d.pinned[digest] {
+		return nil
+	}
+
+	if d.pins == nil {
+		fh, err := d.cache.createPinFile()
+		if err != nil {
+			return err
+		}
+		d.pins = fh
+	}
+
+	if _, err := fmt.Fprintln(d.pins, digest); err != nil {
+		return errors.Wrapf(err, "pinning blob %s", style.Symbol("sha256:"+digest))
+	}
+	d.pinned[digest] = true
+	return nil
+}
+
+// Close releases the blobs handed out by the downloader, which may be pruned from then on. Blobs that are not released
+// are released when the process exits.
+func (d *downloader) Close() error {
+	d.pinnedMutex.Lock()
+	defer d.pinnedMutex.Unlock()
+
+	if d.pins == nil {
+		return nil
 	}
-	defer fh.Close()
 
-	_, err = io.Copy(fh, reader)
+	path := d.pins.Name()
+	_ = unlockFile(d.pins)
+	err := d.pins.Close()
+	d.pins, d.pinned = nil, map[string]bool{}
 	if err != nil {
-		return "", errors.Wrap(err, "writing cache")
+		return err
 	}
 
-	if err = ioutil.WriteFile(etagFile, []byte(etag), 0744); err != nil {
-		return "", errors.Wrap(err, "writing etag")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
 	}
+	return nil
+}
 
-	return cachePath, nil
+// touch records entry as used now.
+func (d *downloader) touch(entry CacheEntry) error {
+	entry.LastUsed = time.Now()
+	return d.cache.writeEntry(entry)
 }
 
 func (d *downloader) downloadAsStream(ctx context.Context, uri string, etag string) (io.ReadCloser, string, error) {
@@ -134,7 +284,7 @@ func (d *downloader) downloadAsStream(ctx context.Context, uri string, etag stri
 		req.Header.Set("If-None-Match", etag)
 	}
 
-	resp, err := (&http.Client{}).Do(req) //nolint:bodyclose
+	resp, err := (&http.Client{Transport: d.transport}).Do(req) //nolint:bodyclose
 	if err != nil {
 		return nil, "", err
 	}
@@ -170,18 +320,3 @@ type progressReader struct {
 	*ioprogress.Reader
 	io.Closer
 }
-
-func (d *downloader) versionedCacheDir() string {
-	return filepath.Join(d.baseCacheDir, cacheDirPrefix+cacheVersion)
-}
-
-func fileExists(file string) (bool, error) {
-	_, err := os.Stat(file)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
-	}
-	return true, nil
-}
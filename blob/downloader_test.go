@@ -2,10 +2,16 @@ package blob_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/heroku/color"
@@ -73,6 +79,21 @@ func testDownloader(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 
+			when("a checksum is declared", func() {
+				it("rejects contents that do not match", func() {
+					tgz := h.CreateTGZ(t, relPath, "./", 0777)
+					defer os.Remove(tgz)
+
+					_, err := subject.Download(context.TODO(), tgz+"#sha256="+strings.Repeat("0", 64))
+					h.AssertError(t, err, "checksum mismatch for '"+tgz+"'")
+				})
+
+				it("cannot verify directories", func() {
+					_, err := subject.Download(context.TODO(), relPath+"#sha256="+strings.Repeat("0", 64))
+					h.AssertError(t, err, "cannot be verified")
+				})
+			})
+
 			when("path is a file:// uri", func() {
 				it("resolves the absolute path", func() {
 					absPath, err := filepath.Abs(relPath)
@@ -134,6 +155,132 @@ func testDownloader(t *testing.T, when spec.G, it spec.S) {
 					h.AssertNil(t, err)
 					assertBlob(t, b)
 				})
+
+				it("exposes the ETag of the download", func() {
+					b, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					h.AssertEq(t, blob.ETag(b), "A")
+
+					b, err = subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					h.AssertEq(t, blob.ETag(b), "A")
+				})
+			})
+
+			when("a checksum is declared", func() {
+				var digest string
+
+				it.Before(func() {
+					fh, err := os.Open(tgz)
+					h.AssertNil(t, err)
+					defer fh.Close()
+
+					hasher := sha256.New()
+					_, err = io.Copy(hasher, fh)
+					h.AssertNil(t, err)
+					digest = hex.EncodeToString(hasher.Sum(nil))
+
+					server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+						http.ServeFile(w, r, tgz)
+					})
+				})
+
+				it("verifies the contents", func() {
+					b, err := subject.Download(context.TODO(), uri+"#sha256="+digest)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+				})
+
+				it("rejects contents that do not match", func() {
+					other := strings.Repeat("0", 64)
+					_, err := subject.Download(context.TODO(), uri+"#sha256="+other)
+					h.AssertError(t, err, fmt.Sprintf("checksum mismatch for '%s': expected sha256 '%s', got '%s'", uri, other, digest))
+				})
+
+				it("does not download contents with a cached checksum again from another URI", func() {
+					_, err := subject.Download(context.TODO(), uri+"#sha256="+digest)
+					h.AssertNil(t, err)
+
+					b, err := subject.Download(context.TODO(), server.URL()+"/mirror/somefile.tgz#sha256="+digest)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+					h.AssertEq(t, len(server.ReceivedRequests()), 1)
+				})
+
+				it("rejects invalid checksums", func() {
+					_, err := subject.Download(context.TODO(), uri+"#sha256=abc")
+					h.AssertError(t, err, "invalid sha256 checksum 'abc'")
+				})
+			})
+
+			when("a transport is given", func() {
+				it.Before(func() {
+					server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+						http.ServeFile(w, r, tgz)
+					})
+				})
+
+				it("downloads through the transport", func() {
+					transport := &countingTransport{}
+					subject = blob.NewDownloader(logging.New(ioutil.Discard), cacheDir, blob.WithTransport(transport))
+
+					b, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+					h.AssertEq(t, transport.requests, 1)
+				})
+			})
+
+			when("the cached blob is corrupt", func() {
+				it.Before(func() {
+					server.RouteToHandler("GET", "/downloader/somefile.tgz", func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("ETag", "A")
+						http.ServeFile(w, r, tgz)
+					})
+				})
+
+				it("downloads the blob again", func() {
+					_, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+
+					blobs, err := filepath.Glob(filepath.Join(cacheDir, "c3", "blobs", "sha256", "*"))
+					h.AssertNil(t, err)
+					h.AssertEq(t, len(blobs), 1)
+					h.AssertNil(t, ioutil.WriteFile(blobs[0], []byte("corrupt"), 0644))
+
+					b, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+					h.AssertEq(t, server.ReceivedRequests()[1].Header.Get("If-None-Match"), "")
+				})
+			})
+
+			when("downloads run concurrently", func() {
+				it.Before(func() {
+					server.RouteToHandler("GET", "/downloader/somefile.tgz", func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("ETag", "A")
+						http.ServeFile(w, r, tgz)
+					})
+				})
+
+				it("every download returns a complete blob", func() {
+					var wg sync.WaitGroup
+					blobs := make([]blob.Blob, 5)
+					errs := make([]error, 5)
+					for i := range blobs {
+						wg.Add(1)
+						go func(i int) {
+							defer wg.Done()
+							blobs[i], errs[i] = blob.NewDownloader(logging.New(ioutil.Discard), cacheDir).Download(context.TODO(), uri)
+						}(i)
+					}
+					wg.Wait()
+
+					for i := range blobs {
+						h.AssertNil(t, errs[i])
+						assertBlob(t, blobs[i])
+					}
+				})
 			})
 
 			when("uri is invalid", func() {
@@ -162,6 +309,15 @@ func testDownloader(t *testing.T, when spec.G, it spec.S) {
 	})
 }
 
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 func assertBlob(t *testing.T, b blob.Blob) {
 	t.Helper()
 	r, err := b.Open()
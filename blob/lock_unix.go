@@ -0,0 +1,32 @@
+// +build !windows
+
+package blob
+
+import (
+	"os"
+	"syscall"
+)
+
+func lockFile(fh *os.File, exclusive bool) error {
+	return syscall.Flock(int(fh.Fd()), lockHow(exclusive))
+}
+
+// tryLockFile locks fh unless it is locked already, and returns whether it did.
+func tryLockFile(fh *os.File, exclusive bool) (bool, error) {
+	err := syscall.Flock(int(fh.Fd()), lockHow(exclusive)|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func unlockFile(fh *os.File) error {
+	return syscall.Flock(int(fh.Fd()), syscall.LOCK_UN)
+}
+
+func lockHow(exclusive bool) int {
+	if exclusive {
+		return syscall.LOCK_EX
+	}
+	return syscall.LOCK_SH
+}
@@ -0,0 +1,59 @@
+package blob
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+
+	errorLockViolation syscall.Errno = 33
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+func lockFile(fh *os.File, exclusive bool) error {
+	var flags uintptr
+	if exclusive {
+		flags = lockfileExclusiveLock
+	}
+	if r, _, err := procLockFileEx.Call(fh.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(lockRange()))); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// tryLockFile locks fh unless it is locked already, and returns whether it did.
+func tryLockFile(fh *os.File, exclusive bool) (bool, error) {
+	flags := uintptr(lockfileFailImmediately)
+	if exclusive {
+		flags |= lockfileExclusiveLock
+	}
+	if r, _, err := procLockFileEx.Call(fh.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(lockRange()))); r == 0 {
+		if err == errorLockViolation {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func unlockFile(fh *os.File) error {
+	if r, _, err := procUnlockFileEx.Call(fh.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(lockRange()))); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// lockRange returns the byte range that is locked. Locks on Windows are mandatory, so they cover a byte far beyond
+// the end of the file, which keeps the contents of locked files readable.
+func lockRange() *syscall.Overlapped {
+	return &syscall.Overlapped{Offset: 0xffffffff, OffsetHigh: 0x7fffffff}
+}
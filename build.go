@@ -16,8 +16,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/blob"
 	"github.com/buildpack/pack/build"
 	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/buildpackage"
 	"github.com/buildpack/pack/cmd"
 	"github.com/buildpack/pack/dist"
 	"github.com/buildpack/pack/internal/archive"
@@ -88,7 +90,7 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		return errors.Wrapf(err, "invalid run-image '%s'", runImage)
 	}
 
-	fetchedBps, group, err := c.processBuildpacks(ctx, opts.Buildpacks)
+	fetchedBps, group, err := c.processBuildpacks(ctx, bldr.GetBuildpacks(), opts.Buildpacks)
 	if err != nil {
 		return errors.Wrap(err, "invalid buildpack")
 	}
@@ -122,16 +124,17 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 	}
 
 	return c.lifecycle.Execute(ctx, build.LifecycleOptions{
-		AppPath:    appPath,
-		Image:      imageRef,
-		Builder:    ephemeralBuilder,
-		RunImage:   runImage,
-		ClearCache: opts.ClearCache,
-		Publish:    opts.Publish,
-		HTTPProxy:  proxyConfig.HTTPProxy,
-		HTTPSProxy: proxyConfig.HTTPSProxy,
-		NoProxy:    proxyConfig.NoProxy,
-		Network:    opts.ContainerConfig.Network,
+		AppPath:     appPath,
+		Image:       imageRef,
+		Builder:     ephemeralBuilder,
+		RunImage:    runImage,
+		ClearCache:  opts.ClearCache,
+		Publish:     opts.Publish,
+		HTTPProxy:   proxyConfig.HTTPProxy,
+		HTTPSProxy:  proxyConfig.HTTPSProxy,
+		NoProxy:     proxyConfig.NoProxy,
+		Network:     opts.ContainerConfig.Network,
+		CACertsPath: c.registry.CACertsPath,
 	})
 }
 
@@ -240,11 +243,16 @@ func (c *Client) processProxyConfig(config *ProxyConfig) ProxyConfig {
 	}
 }
 
-func (c *Client) processBuildpacks(ctx context.Context, buildpacks []string) ([]dist.Buildpack, dist.OrderEntry, error) {
+// processBuildpacks fetches the buildpacks given by path or URI and returns them with the group of all buildpacks.
+// Versions of buildpacks given by ID, which may be ranges, are resolved against the buildpacks of the builder and
+// the fetched buildpacks.
+func (c *Client) processBuildpacks(ctx context.Context, builderBPs []builder.BuildpackMetadata, buildpacks []string) ([]dist.Buildpack, dist.OrderEntry, error) {
 	group := dist.OrderEntry{Group: []dist.BuildpackRef{}}
 	var bps []dist.Buildpack
+	var idRefs []int
 	for _, bp := range buildpacks {
 		if isBuildpackID(bp) {
+			idRefs = append(idRefs, len(group.Group))
 			id, version := c.parseBuildpack(bp)
 			group.Group = append(group.Group, dist.BuildpackRef{
 				BuildpackInfo: dist.BuildpackInfo{
@@ -265,6 +273,20 @@ func (c *Client) processBuildpacks(ctx context.Context, buildpacks []string) ([]
 				return nil, dist.OrderEntry{}, errors.Wrapf(err, "downloading buildpack from %s", style.Symbol(bp))
 			}
 
+			if buildpackage.IsFile(bp) {
+				md, packageBPs, err := readBuildpackageFile(bp, blob)
+				if err != nil {
+					return nil, dist.OrderEntry{}, err
+				}
+
+				bps = append(bps, packageBPs...)
+
+				group.Group = append(group.Group, dist.BuildpackRef{
+					BuildpackInfo: md.BuildpackInfo,
+				})
+				continue
+			}
+
 			fetchedBP, err := dist.NewBuildpack(blob)
 			if err != nil {
 				return nil, dist.OrderEntry{}, errors.Wrapf(err, "creating buildpack from %s", style.Symbol(bp))
@@ -277,10 +299,34 @@ func (c *Client) processBuildpacks(ctx context.Context, buildpacks []string) ([]
 			})
 		}
 	}
+
+	for _, i := range idRefs {
+		ref := &group.Group[i]
+
+		var available []string
+		for _, bp := range builderBPs {
+			if bp.ID == ref.ID {
+				available = append(available, bp.Version)
+			}
+		}
+		for _, bp := range bps {
+			if info := bp.Descriptor().Info; info.ID == ref.ID {
+				available = append(available, info.Version)
+			}
+		}
+
+		version, err := builder.ResolveVersion(ref.ID, ref.Version, available)
+		if err != nil {
+			return nil, dist.OrderEntry{}, err
+		}
+		ref.Version = version
+	}
+
 	return bps, group, nil
 }
 
 func isBuildpackID(bp string) bool {
+	bp, _ = blob.SplitSHA256(bp)
 	if !paths.IsURI(bp) {
 		if _, err := os.Stat(bp); err != nil {
 			return true
@@ -290,6 +336,7 @@ func isBuildpackID(bp string) bool {
 }
 
 func ensureBPSupport(bpPath string) (err error) {
+	bpPath, _ = blob.SplitSHA256(bpPath)
 	p := bpPath
 	if paths.IsURI(bpPath) {
 		var u *url.URL
@@ -29,6 +29,7 @@ type Lifecycle struct {
 	httpsProxy   string
 	noProxy      string
 	version      string
+	caCertsPath  string
 	LayersVolume string
 	AppVolume    string
 }
@@ -47,16 +48,17 @@ func NewLifecycle(docker *client.Client, logger logging.Logger) *Lifecycle {
 }
 
 type LifecycleOptions struct {
-	AppPath    string
-	Image      name.Reference
-	Builder    *builder.Builder
-	RunImage   string
-	ClearCache bool
-	Publish    bool
-	HTTPProxy  string
-	HTTPSProxy string
-	NoProxy    string
-	Network    string
+	AppPath     string
+	Image       name.Reference
+	Builder     *builder.Builder
+	RunImage    string
+	ClearCache  bool
+	Publish     bool
+	HTTPProxy   string
+	HTTPSProxy  string
+	NoProxy     string
+	Network     string
+	CACertsPath string
 }
 
 func (l *Lifecycle) Execute(ctx context.Context, opts LifecycleOptions) error {
@@ -118,6 +120,7 @@ func (l *Lifecycle) Setup(opts LifecycleOptions) {
 	l.httpProxy = opts.HTTPProxy
 	l.httpsProxy = opts.HTTPSProxy
 	l.noProxy = opts.NoProxy
+	l.caCertsPath = opts.CACertsPath
 	l.version = opts.Builder.GetLifecycleDescriptor().Info.Version.String()
 }
 
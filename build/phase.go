@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"runtime"
 	"sync"
 
@@ -43,7 +44,7 @@ func (l *Lifecycle) NewPhase(name string, ops ...func(*Phase) (*Phase, error)) (
 			fmt.Sprintf("%s:%s", l.AppVolume, appDir),
 		},
 	}
-	ctrConf.Cmd = []string{"/lifecycle/" + name}
+	ctrConf.Cmd = []string{path.Join(l.builder.LifecyclePath(), name)}
 	phase := &Phase{
 		ctrConf:  ctrConf,
 		hostConf: hostConf,
@@ -113,6 +114,16 @@ func WithRegistryAccess(repos ...string) func(*Phase) (*Phase, error) {
 	}
 }
 
+// WithCACerts mounts an additional CA bundle where the lifecycle will trust it alongside the system roots.
+func WithCACerts(path string) func(*Phase) (*Phase, error) {
+	return func(phase *Phase) (*Phase, error) {
+		if path != "" {
+			phase.hostConf.Binds = append(phase.hostConf.Binds, fmt.Sprintf("%s:%s:ro", path, caCertsPath))
+		}
+		return phase, nil
+	}
+}
+
 func WithNetwork(networkMode string) func(*Phase) (*Phase, error) {
 	return func(phase *Phase) (*Phase, error) {
 		phase.hostConf.NetworkMode = dcontainer.NetworkMode(networkMode)
@@ -13,6 +13,7 @@ const (
 	cacheDir       = "/cache"
 	launchCacheDir = "/launch-cache"
 	platformDir    = "/platform"
+	caCertsPath    = "/etc/ssl/certs/pack-ca-certs.pem"
 )
 
 func (l *Lifecycle) Detect(ctx context.Context, networkMode string) error {
@@ -74,6 +75,7 @@ func (l *Lifecycle) newAnalyze(repoName string, publish, clearCache bool) (*Phas
 		return l.NewPhase(
 			"analyzer",
 			WithRegistryAccess(repoName),
+			WithCACerts(l.caCertsPath),
 			WithArgs(args...),
 		)
 	}
@@ -126,6 +128,7 @@ func (l *Lifecycle) newExport(repoName, runImage string, publish bool, launchCac
 		return l.NewPhase(
 			"exporter",
 			WithRegistryAccess(repoName, runImage),
+			WithCACerts(l.caCertsPath),
 			WithArgs(
 				l.withLogLevel(
 					"-image", runImage,
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -570,6 +571,47 @@ func testBuild(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 
+			when("a version range is provided", func() {
+				it.Before(func() {
+					label, err := defaultBuilderImage.Label("io.buildpacks.builder.metadata")
+					h.AssertNil(t, err)
+					var md builder.Metadata
+					h.AssertNil(t, json.Unmarshal([]byte(label), &md))
+					for _, version := range []string{"1.2.0", "1.3.0", "2.0.0"} {
+						md.Buildpacks = append(md.Buildpacks, builder.BuildpackMetadata{BuildpackInfo: dist.BuildpackInfo{ID: "buildpack.id", Version: version}})
+					}
+					contents, err := json.Marshal(md)
+					h.AssertNil(t, err)
+					h.AssertNil(t, defaultBuilderImage.SetLabel("io.buildpacks.builder.metadata", string(contents)))
+				})
+
+				it("uses the highest version of the builder matching the range", func() {
+					h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+						Image:      "some/app",
+						Builder:    builderName,
+						ClearCache: true,
+						Buildpacks: []string{"buildpack.id@^1.2"},
+					}))
+
+					bldr, err := builder.GetBuilder(defaultBuilderImage)
+					h.AssertNil(t, err)
+					h.AssertEq(t, bldr.GetOrder(), dist.Order{
+						{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "buildpack.id", Version: "1.3.0"}}}},
+					})
+				})
+
+				it("returns an error when no version matches", func() {
+					h.AssertError(t, subject.Build(context.TODO(), BuildOptions{
+						Image:      "some/app",
+						Builder:    builderName,
+						ClearCache: true,
+						Buildpacks: []string{"buildpack.id@^3"},
+					}),
+						"no version of buildpack 'buildpack.id' matches '^3'",
+					)
+				})
+			})
+
 			when("latest is explicitly provided", func() {
 				it("resolves version and prints a warning", func() {
 					h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
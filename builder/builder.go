@@ -12,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"time"
 
@@ -42,6 +43,9 @@ const (
 	metadataLabel = "io.buildpacks.builder.metadata"
 	stackLabel    = "io.buildpacks.stack.id"
 
+	// whiteoutPrefix marks an entry of a layer that deletes the file of the same name from the layers below.
+	whiteoutPrefix = ".wh."
+
 	envUID = "CNB_USER_ID"
 	envGID = "CNB_GROUP_ID"
 )
@@ -57,6 +61,20 @@ type Builder struct {
 	StackID              string
 	replaceOrder         bool
 	order                dist.Order
+	bpLayers             BuildpackLayers
+	compat               *bool
+	provenance           *Provenance
+	// existing is set when the image already is a builder. Its layers that are not affected by changes are not
+	// added again.
+	existing          bool
+	replaceStack      bool
+	replaceEnv        bool
+	removedBuildpacks []dist.BuildpackInfo
+}
+
+// createdAtSetter is implemented by images whose creation time can be fixed, so that saving them is reproducible.
+type createdAtSetter interface {
+	SetCreatedAt(time.Time) error
 }
 
 type orderTOML struct {
@@ -71,19 +89,20 @@ func GetBuilder(img imgutil.Image) (*Builder, error) {
 	} else if !ok {
 		return nil, fmt.Errorf("builder %s missing label %s -- try recreating builder", style.Symbol(img.Name()), style.Symbol(metadataLabel))
 	}
-	return constructBuilder(img, "", metadata)
+	return constructBuilder(img, "", metadata, true)
 }
 
 // New constructs a new builder from base image
 func New(baseImage imgutil.Image, name string) (*Builder, error) {
 	var metadata Metadata
-	if _, err := dist.GetLabel(baseImage, metadataLabel, &metadata); err != nil {
+	found, err := dist.GetLabel(baseImage, metadataLabel, &metadata)
+	if err != nil {
 		return nil, err
 	}
-	return constructBuilder(baseImage, name, metadata)
+	return constructBuilder(baseImage, name, metadata, found)
 }
 
-func constructBuilder(img imgutil.Image, newName string, metadata Metadata) (*Builder, error) {
+func constructBuilder(img imgutil.Image, newName string, metadata Metadata, metadataFound bool) (*Builder, error) {
 	uid, gid, err := userAndGroupIDs(img)
 	if err != nil {
 		return nil, err
@@ -116,18 +135,36 @@ func constructBuilder(img imgutil.Image, newName string, metadata Metadata) (*Bu
 		platformAPIVersion = metadata.Lifecycle.API.PlatformVersion
 	}
 
+	if metadataFound && metadata.Compat == nil {
+		compat := true
+		metadata.Compat = &compat
+	}
+
 	var order dist.Order
 	if _, err := dist.GetLabel(img, OrderLabel, &order); err != nil {
 		return nil, err
 	}
 
+	bpLayers := BuildpackLayers{}
+	if _, err := dist.GetLabel(img, BuildpackLayersLabel, &bpLayers); err != nil {
+		return nil, err
+	}
+
+	var provenance *Provenance
+	if _, err := dist.GetLabel(img, ProvenanceLabel, &provenance); err != nil {
+		return nil, err
+	}
+
 	return &Builder{
-		image:    img,
-		metadata: metadata,
-		order:    order,
-		UID:      uid,
-		GID:      gid,
-		StackID:  stackID,
+		image:      img,
+		metadata:   metadata,
+		order:      order,
+		bpLayers:   bpLayers,
+		provenance: provenance,
+		existing:   metadataFound,
+		UID:        uid,
+		GID:        gid,
+		StackID:    stackID,
 		lifecycleDescriptor: LifecycleDescriptor{
 			Info: LifecycleInfo{
 				Version: lifecycleVersion,
@@ -161,6 +198,32 @@ func (b *Builder) GetOrder() dist.Order {
 	return b.order
 }
 
+// GetProvenance returns the provenance of the builder, or nil if it was not recorded.
+func (b *Builder) GetProvenance() *Provenance {
+	return b.provenance
+}
+
+func (b *Builder) GetBuildpackLayers() BuildpackLayers {
+	return b.bpLayers
+}
+
+// CompatPaths returns the paths of the compat layer if the builder contains them.
+func (b *Builder) CompatPaths() []string {
+	if b.metadata.Compat == nil || !*b.metadata.Compat {
+		return nil
+	}
+	return []string{compatBuildpacksDir, compatStackPath, compatLifecycleDir}
+}
+
+// LifecyclePath returns the directory of the lifecycle binaries. The compat path is used for builders that contain
+// it, as older builders may not have the lifecycle under /cnb.
+func (b *Builder) LifecyclePath() string {
+	if len(b.CompatPaths()) > 0 {
+		return compatLifecycleDir
+	}
+	return lifecycleDir
+}
+
 func (b *Builder) Name() string {
 	return b.image.Name()
 }
@@ -171,19 +234,119 @@ func (b *Builder) GetStackInfo() StackMetadata {
 
 func (b *Builder) AddBuildpack(bp dist.Buildpack) {
 	b.additionalBuildpacks = append(b.additionalBuildpacks, bp)
+	for _, existing := range b.metadata.Buildpacks {
+		if existing.BuildpackInfo == bp.Descriptor().Info {
+			return
+		}
+	}
 	b.metadata.Buildpacks = append(b.metadata.Buildpacks, BuildpackMetadata{
 		BuildpackInfo: bp.Descriptor().Info,
 	})
 }
 
+// RemoveBuildpack removes a buildpack from the builder. The version may be omitted when the builder only has one
+// version of the buildpack. As layers of an image cannot be removed, the layer of the buildpack remains in the image
+// and its files are deleted by a whiteout layer added on save.
+func (b *Builder) RemoveBuildpack(info dist.BuildpackInfo) error {
+	var matches []dist.BuildpackInfo
+	for _, bp := range b.metadata.Buildpacks {
+		if bp.ID == info.ID && (info.Version == "" || bp.Version == info.Version) {
+			matches = append(matches, bp.BuildpackInfo)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("buildpack %s not found on the builder", style.Symbol(buildpackRef(info)))
+	case 1:
+		info = matches[0]
+	default:
+		return fmt.Errorf("unable to resolve version: multiple versions of %s - must specify an explicit version", style.Symbol(info.ID))
+	}
+
+	versions := 0
+	for _, bp := range b.metadata.Buildpacks {
+		if bp.ID == info.ID {
+			versions++
+		}
+	}
+
+	for _, group := range b.order {
+		for _, ref := range group.Group {
+			if ref.ID == info.ID && (ref.Version == info.Version || ref.Version == "" && versions == 1) {
+				return fmt.Errorf("buildpack %s is used by the order of the builder", style.Symbol(buildpackRef(info)))
+			}
+		}
+	}
+
+	for id, versions := range b.bpLayers {
+		for version, layer := range versions {
+			for _, group := range layer.Order {
+				for _, ref := range group.Group {
+					if ref.BuildpackInfo == info {
+						return fmt.Errorf(
+							"buildpack %s is used by buildpack %s",
+							style.Symbol(buildpackRef(info)),
+							style.Symbol(buildpackRef(dist.BuildpackInfo{ID: id, Version: version})),
+						)
+					}
+				}
+			}
+		}
+	}
+
+	var buildpacks []BuildpackMetadata
+	for _, bp := range b.metadata.Buildpacks {
+		if bp.BuildpackInfo != info {
+			buildpacks = append(buildpacks, bp)
+		}
+	}
+	b.metadata.Buildpacks = buildpacks
+
+	var additional []dist.Buildpack
+	for _, bp := range b.additionalBuildpacks {
+		if bp.Descriptor().Info != info {
+			additional = append(additional, bp)
+		}
+	}
+	b.additionalBuildpacks = additional
+
+	delete(b.bpLayers[info.ID], info.Version)
+	if len(b.bpLayers[info.ID]) == 0 {
+		delete(b.bpLayers, info.ID)
+	}
+
+	b.removedBuildpacks = append(b.removedBuildpacks, info)
+	return nil
+}
+
+func buildpackRef(info dist.BuildpackInfo) string {
+	if info.Version == "" {
+		return info.ID
+	}
+	return info.ID + "@" + info.Version
+}
+
 func (b *Builder) SetLifecycle(lifecycle Lifecycle) error {
 	b.lifecycle = lifecycle
 	b.lifecycleDescriptor = lifecycle.Descriptor()
 	return nil
 }
 
+// SetCompat forces the compat layer to be added or omitted, regardless of whether the lifecycle requires it.
+func (b *Builder) SetCompat(enabled bool) {
+	b.compat = &enabled
+}
+
+// SetProvenance sets the provenance recorded on the builder when it is saved. The layer digests of its buildpacks
+// are filled in on save, and buildpacks that are no longer on the builder are dropped from it.
+func (b *Builder) SetProvenance(provenance Provenance) {
+	b.provenance = &provenance
+}
+
 func (b *Builder) SetEnv(env map[string]string) {
 	b.env = env
+	b.replaceEnv = true
 }
 
 func (b *Builder) SetOrder(order dist.Order) {
@@ -195,6 +358,12 @@ func (b *Builder) SetDescription(description string) {
 	b.metadata.Description = description
 }
 
+// SetRunImageMirrors replaces the run image mirrors of the builder.
+func (b *Builder) SetRunImageMirrors(mirrors []string) {
+	b.metadata.Stack.RunImage.Mirrors = mirrors
+	b.replaceStack = true
+}
+
 func (b *Builder) SetStackInfo(stackConfig StackConfig) {
 	b.metadata.Stack = StackMetadata{
 		RunImage: RunImageMetadata{
@@ -202,6 +371,7 @@ func (b *Builder) SetStackInfo(stackConfig StackConfig) {
 			Mirrors: stackConfig.RunImageMirrors,
 		},
 	}
+	b.replaceStack = true
 }
 
 func (b *Builder) Save(logger logging.Logger) error {
@@ -210,6 +380,10 @@ func (b *Builder) Save(logger logging.Logger) error {
 		return errors.Wrap(err, "processing order")
 	}
 
+	if err := validateOrders(resolvedOrder, b.layersWithAdditionalBuildpacks(), b.StackID); err != nil {
+		return errors.Wrap(err, "validating order")
+	}
+
 	processMetadata(&b.metadata)
 
 	tmpDir, err := ioutil.TempDir("", "create-builder-scratch")
@@ -218,12 +392,14 @@ func (b *Builder) Save(logger logging.Logger) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	dirsTar, err := b.defaultDirsLayer(tmpDir)
-	if err != nil {
-		return err
-	}
-	if err := b.image.AddLayer(dirsTar); err != nil {
-		return errors.Wrap(err, "adding default dirs layer")
+	if !b.existing {
+		dirsTar, err := b.defaultDirsLayer(tmpDir)
+		if err != nil {
+			return err
+		}
+		if err := b.image.AddLayer(dirsTar); err != nil {
+			return errors.Wrap(err, "adding default dirs layer")
+		}
 	}
 
 	if b.lifecycle != nil {
@@ -242,10 +418,17 @@ func (b *Builder) Save(logger logging.Logger) error {
 		return errors.Wrap(err, "validating buildpacks")
 	}
 
-	bpLayers := BuildpackLayers{}
-	if _, err := dist.GetLabel(b.image, BuildpackLayersLabel, &bpLayers); err != nil {
+	removedTar, err := b.removedBuildpacksLayer(tmpDir)
+	if err != nil {
 		return err
 	}
+	if removedTar != "" {
+		if err := b.image.AddLayer(removedTar); err != nil {
+			return errors.Wrap(err, "adding removed buildpacks layer")
+		}
+	}
+
+	bpLayers := b.bpLayers
 
 	for _, bp := range b.additionalBuildpacks {
 		bpLayerTar, err := dist.BuildpackLayer(tmpDir, b.UID, b.GID, bp)
@@ -277,6 +460,7 @@ func (b *Builder) Save(logger logging.Logger) error {
 		bpLayers[bpInfo.ID][bpInfo.Version] = BuildpackLayerInfo{
 			LayerDigest: "sha256:" + sha,
 			Order:       bp.Descriptor().Order,
+			Stacks:      bp.Descriptor().Stacks,
 		}
 	}
 
@@ -284,6 +468,21 @@ func (b *Builder) Save(logger logging.Logger) error {
 		return err
 	}
 
+	if b.provenance != nil {
+		buildpacks := []BuildpackProvenance{}
+		for _, bp := range b.provenance.Buildpacks {
+			if layer, ok := bpLayers[bp.ID][bp.Version]; ok {
+				bp.LayerDigest = layer.LayerDigest
+				buildpacks = append(buildpacks, bp)
+			}
+		}
+		b.provenance.Buildpacks = buildpacks
+
+		if err := dist.SetLabel(b.image, ProvenanceLabel, b.provenance); err != nil {
+			return err
+		}
+	}
+
 	if b.replaceOrder {
 		orderTar, err := b.orderLayer(resolvedOrder, tmpDir)
 		if err != nil {
@@ -298,29 +497,42 @@ func (b *Builder) Save(logger logging.Logger) error {
 		}
 	}
 
-	stackTar, err := b.stackLayer(tmpDir)
-	if err != nil {
-		return err
-	}
-	if err := b.image.AddLayer(stackTar); err != nil {
-		return errors.Wrap(err, "adding stack.tar layer")
+	if !b.existing || b.replaceStack {
+		stackTar, err := b.stackLayer(tmpDir)
+		if err != nil {
+			return err
+		}
+		if err := b.image.AddLayer(stackTar); err != nil {
+			return errors.Wrap(err, "adding stack.tar layer")
+		}
 	}
 
-	compatTar, err := b.compatLayer(resolvedOrder, tmpDir)
+	compat, err := b.useCompat(logger)
 	if err != nil {
 		return err
 	}
+	compatChanged := !b.existing || len(b.CompatPaths()) == 0 || b.lifecycle != nil || len(b.additionalBuildpacks) > 0 || b.replaceStack
+	b.metadata.Compat = &compat
 
-	if err := b.image.AddLayer(compatTar); err != nil {
-		return errors.Wrap(err, "adding compat.tar layer")
-	}
+	if compat && compatChanged {
+		compatTar, err := b.compatLayer(resolvedOrder, tmpDir)
+		if err != nil {
+			return err
+		}
 
-	envTar, err := b.envLayer(tmpDir, b.env)
-	if err != nil {
-		return err
+		if err := b.image.AddLayer(compatTar); err != nil {
+			return errors.Wrap(err, "adding compat.tar layer")
+		}
 	}
-	if err := b.image.AddLayer(envTar); err != nil {
-		return errors.Wrap(err, "adding env layer")
+
+	if !b.existing || b.replaceEnv {
+		envTar, err := b.envLayer(tmpDir, b.env)
+		if err != nil {
+			return err
+		}
+		if err := b.image.AddLayer(envTar); err != nil {
+			return errors.Wrap(err, "adding env layer")
+		}
 	}
 
 	b.metadata.CreatedBy = CreatorMetadata{
@@ -336,10 +548,39 @@ func (b *Builder) Save(logger logging.Logger) error {
 		return errors.Wrap(err, "failed to set working dir")
 	}
 
+	if img, ok := b.image.(createdAtSetter); ok {
+		if err := img.SetCreatedAt(archive.NormalizedDateTime); err != nil {
+			return errors.Wrap(err, "failed to set created time")
+		}
+	}
+
 	_, err = b.image.Save()
 	return err
 }
 
+// useCompat returns whether the compat layer should be added. Builders that already contain compat paths keep them
+// up to date, as layers of the image cannot be removed.
+func (b *Builder) useCompat(logger logging.Logger) (bool, error) {
+	required := requiresCompat(b.lifecycleDescriptor)
+	if b.compat == nil {
+		return required || len(b.CompatPaths()) > 0, nil
+	}
+
+	if !*b.compat && required {
+		version := AssumedLifecycleVersion
+		if b.lifecycleDescriptor.Info.Version != nil {
+			version = b.lifecycleDescriptor.Info.Version.String()
+		}
+		return false, fmt.Errorf("lifecycle %s requires the compat layer, it cannot be disabled", style.Symbol(version))
+	}
+
+	if !*b.compat && len(b.CompatPaths()) > 0 {
+		logger.Warnf("builder %s already contains compat paths, they cannot be removed", style.Symbol(b.Name()))
+		return true, nil
+	}
+	return *b.compat, nil
+}
+
 func sha256ForFile(path string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -360,28 +601,18 @@ func processOrder(buildpacks []BuildpackMetadata, order dist.Order) (dist.Order,
 		resolvedOrder = append(resolvedOrder, dist.OrderEntry{})
 
 		for _, bpRef := range g.Group {
-			var matchingBps []dist.BuildpackInfo
+			var available []string
 			for _, bp := range buildpacks {
 				if bpRef.ID == bp.ID {
-					matchingBps = append(matchingBps, bp.BuildpackInfo)
-				}
-			}
-
-			if len(matchingBps) == 0 {
-				return dist.Order{}, fmt.Errorf("no versions of buildpack %s were found on the builder", style.Symbol(bpRef.ID))
-			}
-
-			if bpRef.Version == "" {
-				if len(matchingBps) > 1 {
-					return dist.Order{}, fmt.Errorf("unable to resolve version: multiple versions of %s - must specify an explicit version", style.Symbol(bpRef.ID))
+					available = append(available, bp.Version)
 				}
-
-				bpRef.Version = matchingBps[0].Version
 			}
 
-			if !hasBuildpackWithVersion(matchingBps, bpRef.Version) {
-				return dist.Order{}, fmt.Errorf("buildpack %s with version %s was not found on the builder", style.Symbol(bpRef.ID), style.Symbol(bpRef.Version))
+			version, err := ResolveVersion(bpRef.ID, bpRef.Version, available)
+			if err != nil {
+				return dist.Order{}, err
 			}
+			bpRef.Version = version
 
 			resolvedOrder[gi].Group = append(resolvedOrder[gi].Group, bpRef)
 		}
@@ -390,23 +621,7 @@ func processOrder(buildpacks []BuildpackMetadata, order dist.Order) (dist.Order,
 	return resolvedOrder, nil
 }
 
-func hasBuildpackWithVersion(bps []dist.BuildpackInfo, version string) bool {
-	for _, bp := range bps {
-		if bp.Version == version {
-			return true
-		}
-	}
-	return false
-}
-
 func validateBuildpacks(stackID string, lifecycleDescriptor LifecycleDescriptor, bps []dist.Buildpack) error {
-	bpLookup := map[string]interface{}{}
-
-	for _, bp := range bps {
-		bpInfo := bp.Descriptor().Info
-		bpLookup[bpInfo.ID+"@"+bpInfo.Version] = nil
-	}
-
 	for _, bp := range bps {
 		bpd := bp.Descriptor()
 
@@ -420,31 +635,32 @@ func validateBuildpacks(stackID string, lifecycleDescriptor LifecycleDescriptor,
 			)
 		}
 
-		if len(bpd.Stacks) >= 1 { // standard buildpack
-			if !bpd.SupportsStack(stackID) {
-				return fmt.Errorf(
-					"buildpack %s does not support stack %s",
-					style.Symbol(bpd.Info.ID+"@"+bpd.Info.Version),
-					style.Symbol(stackID),
-				)
-			}
-		} else { // order buildpack
-			for _, g := range bpd.Order {
-				for _, r := range g.Group {
-					if _, ok := bpLookup[r.ID+"@"+r.Version]; !ok {
-						return fmt.Errorf(
-							"buildpack %s not found on the builder",
-							style.Symbol(r.ID+"@"+r.Version),
-						)
-					}
-				}
-			}
+		if len(bpd.Stacks) >= 1 && !bpd.SupportsStack(stackID) {
+			return fmt.Errorf(
+				"buildpack %s does not support stack %s",
+				style.Symbol(bpd.Info.ID+"@"+bpd.Info.Version),
+				style.Symbol(stackID),
+			)
 		}
 	}
 
 	return nil
 }
 
+// layersWithAdditionalBuildpacks returns the buildpack layers of the builder as they will be once the added
+// buildpacks are saved, without their layer digests.
+func (b *Builder) layersWithAdditionalBuildpacks() BuildpackLayers {
+	layers := mergeLayers(b.metadata.Buildpacks, b.bpLayers)
+	for _, bp := range b.additionalBuildpacks {
+		bpd := bp.Descriptor()
+		if _, ok := layers[bpd.Info.ID]; !ok {
+			layers[bpd.Info.ID] = map[string]BuildpackLayerInfo{}
+		}
+		layers[bpd.Info.ID][bpd.Info.Version] = BuildpackLayerInfo{Order: bpd.Order, Stacks: bpd.Stacks}
+	}
+	return layers
+}
+
 func userAndGroupIDs(img imgutil.Image) (int, int, error) {
 	sUID, err := img.Env(envUID)
 	if err != nil {
@@ -582,39 +798,83 @@ func (b *Builder) embedLifecycleTar(tw *tar.Writer) error {
 		return errors.Wrap(err, "failed to open lifecycle")
 	}
 	defer lr.Close()
-	tr := tar.NewReader(lr)
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break
+
+	return archive.ReadSortedTarEntries(lr, func(header *tar.Header, contents io.Reader) error {
+		pathMatches := regex.FindStringSubmatch(path.Clean(header.Name))
+		if pathMatches == nil {
+			return nil
 		}
-		if err != nil {
-			return errors.Wrap(err, "failed to get next tar entry")
+		binaryName := pathMatches[1]
+
+		header.Name = lifecycleDir + "/" + binaryName
+		archive.NormalizeHeader(header)
+		if err := tw.WriteHeader(header); err != nil {
+			return errors.Wrapf(err, "failed to write header for '%s'", header.Name)
 		}
 
-		pathMatches := regex.FindStringSubmatch(path.Clean(header.Name))
-		if pathMatches != nil {
-			binaryName := pathMatches[1]
+		if _, err := io.Copy(tw, contents); err != nil {
+			return errors.Wrapf(err, "failed to write contents to '%s'", header.Name)
+		}
+		return nil
+	})
+}
 
-			header.Name = lifecycleDir + "/" + binaryName
-			err = tw.WriteHeader(header)
-			if err != nil {
-				return errors.Wrapf(err, "failed to write header for '%s'", header.Name)
-			}
+// removedBuildpacksLayer returns a layer of whiteouts that delete the files of the removed buildpacks, or an empty
+// path when there is nothing to delete. Buildpacks that were added back are kept.
+func (b *Builder) removedBuildpacksLayer(dest string) (string, error) {
+	bpDirs := []string{dist.BuildpacksDir}
+	if len(b.CompatPaths()) > 0 {
+		bpDirs = append(bpDirs, compatBuildpacksDir)
+	}
 
-			buf, err := ioutil.ReadAll(tr)
-			if err != nil {
-				return errors.Wrapf(err, "failed to read contents of '%s'", header.Name)
-			}
+	layers := b.layersWithAdditionalBuildpacks()
+	whiteouts := map[string]bool{}
+	for _, info := range b.removedBuildpacks {
+		if _, ok := layers[info.ID][info.Version]; ok {
+			continue
+		}
 
-			_, err = tw.Write(buf)
-			if err != nil {
-				return errors.Wrapf(err, "failed to write contents to '%s'", header.Name)
+		escapedID := (&dist.BuildpackDescriptor{Info: info}).EscapedID()
+		for _, dir := range bpDirs {
+			if len(layers[info.ID]) == 0 {
+				whiteouts[path.Join(dir, whiteoutPrefix+escapedID)] = true
+			} else {
+				whiteouts[path.Join(dir, escapedID, whiteoutPrefix+info.Version)] = true
 			}
 		}
 	}
 
-	return nil
+	if len(whiteouts) == 0 {
+		return "", nil
+	}
+
+	var names []string
+	for name := range whiteouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fh, err := os.Create(filepath.Join(dest, "removed-buildpacks.tar"))
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	tw := tar.NewWriter(fh)
+	defer tw.Close()
+
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			ModTime:  archive.NormalizedDateTime,
+		}); err != nil {
+			return "", errors.Wrapf(err, "creating whiteout %s in layer", style.Symbol(name))
+		}
+	}
+
+	return fh.Name(), nil
 }
 
 func (b *Builder) envLayer(dest string, env map[string]string) (string, error) {
@@ -627,7 +887,14 @@ func (b *Builder) envLayer(dest string, env map[string]string) (string, error) {
 	tw := tar.NewWriter(fh)
 	defer tw.Close()
 
-	for k, v := range env {
+	var names []string
+	for k := range env {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		v := env[k]
 		if err := tw.WriteHeader(&tar.Header{
 			Name:    path.Join(platformDir, "env", k),
 			Size:    int64(len(v)),
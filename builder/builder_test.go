@@ -2,19 +2,29 @@ package builder_test
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Masterminds/semver"
 	"github.com/buildpack/imgutil"
 	"github.com/buildpack/imgutil/fakes"
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/heroku/color"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
@@ -23,6 +33,7 @@ import (
 	"github.com/buildpack/pack/builder"
 	"github.com/buildpack/pack/builder/testmocks"
 	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/image"
 	"github.com/buildpack/pack/internal/archive"
 	ifakes "github.com/buildpack/pack/internal/fakes"
 	"github.com/buildpack/pack/logging"
@@ -197,6 +208,156 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 				h.AssertEq(t, baseImage.Name(), "some/builder")
 			})
 
+			it("creates identical layers and labels when saved twice with the same inputs", func() {
+				saveBuilder := func(img *fakes.Image) {
+					h.AssertNil(t, img.SetEnv("CNB_USER_ID", "1234"))
+					h.AssertNil(t, img.SetEnv("CNB_GROUP_ID", "4321"))
+					h.AssertNil(t, img.SetLabel("io.buildpacks.stack.id", "some.stack.id"))
+
+					lifecycle := testmocks.NewMockLifecycle(mockController)
+					lifecycle.EXPECT().Open().Return(archive.ReadDirAsTar(
+						filepath.Join("testdata", "lifecycle"), ".", 0, 0, 0755), nil)
+					lifecycle.EXPECT().Descriptor().Return(mockLifecycle.Descriptor()).AnyTimes()
+
+					bldr, err := builder.New(img, "some/builder")
+					h.AssertNil(t, err)
+					h.AssertNil(t, bldr.SetLifecycle(lifecycle))
+					bldr.AddBuildpack(bp2v1)
+					bldr.AddBuildpack(bp1v1)
+					bldr.SetOrder(dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: bp1v1.Descriptor().Info}}}})
+					bldr.SetEnv(map[string]string{
+						"A_KEY": "a-value", "B_KEY": "b-value", "C_KEY": "c-value", "D_KEY": "d-value",
+						"E_KEY": "e-value", "F_KEY": "f-value", "G_KEY": "g-value", "H_KEY": "h-value",
+					})
+					h.AssertNil(t, bldr.Save(logger))
+				}
+
+				otherImage := fakes.NewImage("other/image", "", "")
+				defer otherImage.Cleanup()
+
+				saveBuilder(baseImage)
+				saveBuilder(otherImage)
+
+				for _, p := range []string{
+					"/workspace",
+					"/cnb/lifecycle/detector",
+					"/cnb/buildpacks/buildpack-1-id/buildpack-1-version-1",
+					"/cnb/buildpacks/buildpack-2-id/buildpack-2-version-1",
+					"/cnb/order.toml",
+					"/cnb/stack.toml",
+					"/platform/env/A_KEY",
+				} {
+					h.AssertEq(t, layerDigest(t, baseImage, p), layerDigest(t, otherImage, p))
+				}
+
+				for _, label := range []string{
+					"io.buildpacks.builder.metadata",
+					"io.buildpacks.buildpack.order",
+					"io.buildpacks.buildpack.layers",
+				} {
+					expected, err := baseImage.Label(label)
+					h.AssertNil(t, err)
+					actual, err := otherImage.Label(label)
+					h.AssertNil(t, err)
+					h.AssertEq(t, actual, expected)
+				}
+			})
+
+			it("creates images with the same digest when saved twice with the same inputs", func() {
+				server := httptest.NewServer(registry.New())
+				defer server.Close()
+				registryHost := strings.TrimPrefix(server.URL, "http://")
+
+				buildImage, err := mutate.Config(empty.Image, v1.Config{
+					Env:    []string{"CNB_USER_ID=1234", "CNB_GROUP_ID=4321"},
+					Labels: map[string]string{"io.buildpacks.stack.id": "some.stack.id"},
+				})
+				h.AssertNil(t, err)
+				buildImageRef, err := name.ParseReference(registryHost+"/build/image", name.WeakValidation)
+				h.AssertNil(t, err)
+				h.AssertNil(t, remote.Write(buildImageRef, buildImage, remote.WithAuth(authn.Anonymous)))
+
+				saveBuilder := func(builderName string) string {
+					img, err := image.NewRemoteImage(registryHost+"/build/image", authn.DefaultKeychain, nil)
+					h.AssertNil(t, err)
+
+					lifecycle := testmocks.NewMockLifecycle(mockController)
+					lifecycle.EXPECT().Open().Return(archive.ReadDirAsTar(
+						filepath.Join("testdata", "lifecycle"), ".", 0, 0, 0755), nil)
+					lifecycle.EXPECT().Descriptor().Return(mockLifecycle.Descriptor()).AnyTimes()
+
+					bldr, err := builder.New(img, registryHost+"/"+builderName)
+					h.AssertNil(t, err)
+					h.AssertNil(t, bldr.SetLifecycle(lifecycle))
+					bldr.AddBuildpack(bp1v1)
+					bldr.SetOrder(dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: bp1v1.Descriptor().Info}}}})
+					h.AssertNil(t, bldr.Save(logger))
+
+					createdAt, err := img.CreatedAt()
+					h.AssertNil(t, err)
+					h.AssertEq(t, createdAt, archive.NormalizedDateTime)
+
+					digest, err := img.Digest()
+					h.AssertNil(t, err)
+					return digest
+				}
+
+				h.AssertEq(t, saveBuilder("some/builder"), saveBuilder("other/builder"))
+			})
+
+			it("records the provenance with the layer digests of the buildpacks", func() {
+				subject.AddBuildpack(bp1v1)
+				subject.SetProvenance(builder.Provenance{
+					BuildImage: builder.ImageProvenance{Name: "some/build-image", Digest: "sha256:build-image"},
+					Lifecycle:  builder.SourceProvenance{URI: "file:///some-lifecycle", Digest: "sha256:lifecycle"},
+					Buildpacks: []builder.BuildpackProvenance{{
+						BuildpackInfo: bp1v1.Descriptor().Info,
+						URI:           "https://example.com/bp1.tgz",
+						Digest:        "sha256:bp1",
+					}},
+					Parameters: builder.ProvenanceParameters{BuilderName: "some/builder", Publish: true},
+				})
+				h.AssertNil(t, subject.Save(logger))
+
+				bldr, err := builder.GetBuilder(baseImage)
+				h.AssertNil(t, err)
+
+				provenance := bldr.GetProvenance()
+				h.AssertNotNil(t, provenance)
+				h.AssertEq(t, provenance.BuildImage.Digest, "sha256:build-image")
+				h.AssertEq(t, provenance.Lifecycle.URI, "file:///some-lifecycle")
+				h.AssertEq(t, provenance.Buildpacks[0].URI, "https://example.com/bp1.tgz")
+				h.AssertEq(t, provenance.Buildpacks[0].LayerDigest, bldr.GetBuildpackLayers()["buildpack-1-id"]["buildpack-1-version-1"].LayerDigest)
+				h.AssertEq(t, provenance.Parameters, builder.ProvenanceParameters{BuilderName: "some/builder", Publish: true})
+			})
+
+			it("drops the provenance of buildpacks that are not on the builder", func() {
+				subject.AddBuildpack(bp1v1)
+				subject.SetProvenance(builder.Provenance{
+					Buildpacks: []builder.BuildpackProvenance{
+						{BuildpackInfo: bp1v1.Descriptor().Info, URI: "https://example.com/bp1.tgz"},
+						{BuildpackInfo: bp1v2.Descriptor().Info, URI: "https://example.com/bp1-v2.tgz"},
+					},
+				})
+				h.AssertNil(t, subject.Save(logger))
+
+				bldr, err := builder.GetBuilder(baseImage)
+				h.AssertNil(t, err)
+
+				provenance := bldr.GetProvenance()
+				h.AssertNotNil(t, provenance)
+				h.AssertEq(t, len(provenance.Buildpacks), 1)
+				h.AssertEq(t, provenance.Buildpacks[0].BuildpackInfo, bp1v1.Descriptor().Info)
+			})
+
+			it("doesn't add a provenance label when no provenance is set", func() {
+				h.AssertNil(t, subject.Save(logger))
+
+				label, err := baseImage.Label("io.buildpacks.builder.provenance")
+				h.AssertNil(t, err)
+				h.AssertEq(t, label, "")
+			})
+
 			it("adds creator metadata", func() {
 				h.AssertNil(t, subject.Save(logger))
 				h.AssertEq(t, baseImage.IsSaved(), true)
@@ -374,6 +535,46 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 					})
 				})
 
+				when("order uses a version range", func() {
+					it.Before(func() {
+						for _, version := range []string{"1.2.0", "1.3.1", "2.0.0"} {
+							subject.AddBuildpack(&fakeBuildpack{descriptor: dist.BuildpackDescriptor{
+								API:    api.MustParse("0.2"),
+								Info:   dist.BuildpackInfo{ID: "semver-bp", Version: version},
+								Stacks: []dist.Stack{{ID: "some.stack.id"}},
+							}})
+						}
+					})
+
+					it("should resolve the highest matching version", func() {
+						subject.SetOrder(dist.Order{{
+							Group: []dist.BuildpackRef{
+								{BuildpackInfo: dist.BuildpackInfo{ID: "semver-bp", Version: "^1.2"}}},
+						}})
+
+						h.AssertNil(t, subject.Save(logger))
+
+						layerTar, err := baseImage.FindLayerWithPath("/cnb/order.toml")
+						h.AssertNil(t, err)
+						h.AssertOnTarEntry(t, layerTar, "/cnb/order.toml", h.ContentEquals(`[[order]]
+
+  [[order.group]]
+    id = "semver-bp"
+    version = "1.3.1"
+`))
+					})
+
+					it("should error when no version matches", func() {
+						subject.SetOrder(dist.Order{{
+							Group: []dist.BuildpackRef{
+								{BuildpackInfo: dist.BuildpackInfo{ID: "semver-bp", Version: ">=3.0 <4"}}},
+						}})
+
+						err := subject.Save(logger)
+						h.AssertError(t, err, "no version of buildpack 'semver-bp' matches '>=3.0 <4' (available versions: '1.2.0', '1.3.1', '2.0.0')")
+					})
+				})
+
 				when("has multiple buildpacks with same ID", func() {
 					it.Before(func() {
 						subject.AddBuildpack(bp1v1)
@@ -473,6 +674,48 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 						h.AssertError(t, err, "buildpack 'buildpack-1-id@buildpack-1-version-1' (Buildpack API version 0.1) is incompatible with lifecycle '1.2.3' (Buildpack API version 0.2)")
 					})
 				})
+
+				when("order buildpacks reference each other", func() {
+					it("returns an error with the cycle", func() {
+						subject.AddBuildpack(&fakeBuildpack{descriptor: dist.BuildpackDescriptor{
+							API:   api.MustParse("0.2"),
+							Info:  dist.BuildpackInfo{ID: "meta-a", Version: "1"},
+							Order: dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "meta-b", Version: "1"}}}}},
+						}})
+						subject.AddBuildpack(&fakeBuildpack{descriptor: dist.BuildpackDescriptor{
+							API:   api.MustParse("0.2"),
+							Info:  dist.BuildpackInfo{ID: "meta-b", Version: "1"},
+							Order: dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "meta-a", Version: "1"}}}}},
+						}})
+
+						err := subject.Save(logger)
+
+						h.AssertError(t, err, "buildpack order contains a cycle: 'meta-a@1' -> 'meta-b@1' -> 'meta-a@1'")
+						h.AssertEq(t, baseImage.IsSaved(), false)
+					})
+				})
+
+				when("a nested buildpack already on the builder does not support the stack", func() {
+					it("returns an error with the buildpacks that require it", func() {
+						h.AssertNil(t, baseImage.SetLabel("io.buildpacks.buildpack.layers", `{"buildpack-2-id": {"buildpack-2-version-1": {"layerDigest": "sha256:bp2", "stacks": [{"id": "other.stack.id"}]}}}`))
+						bldr, err := builder.New(baseImage, "some/builder")
+						h.AssertNil(t, err)
+						h.AssertNil(t, bldr.SetLifecycle(mockLifecycle))
+						bldr.AddBuildpack(bp1v1)
+						bldr.AddBuildpack(bpOrder)
+
+						err = bldr.Save(logger)
+
+						h.AssertError(t, err, "buildpack 'buildpack-2-id@buildpack-2-version-1' does not support stack 'some.stack.id' (required by 'order-buildpack-id@order-buildpack-version')")
+					})
+				})
+
+				it("records the stacks of added buildpacks in the buildpack layers label", func() {
+					subject.AddBuildpack(bp1v1)
+					h.AssertNil(t, subject.Save(logger))
+
+					h.AssertEq(t, subject.GetBuildpackLayers()["buildpack-1-id"]["buildpack-1-version-1"].Stacks, []dist.Stack{{ID: "some.stack.id"}})
+				})
 			})
 		})
 
@@ -726,6 +969,112 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("#RemoveBuildpack", func() {
+			it.Before(func() {
+				h.AssertNil(t, baseImage.SetLabel(
+					"io.buildpacks.builder.metadata",
+					`{"buildpacks": [{"id": "buildpack-1-id", "version": "buildpack-1-version-1"}, {"id": "buildpack-1-id", "version": "buildpack-1-version-2"}, {"id": "buildpack-2-id", "version": "buildpack-2-version-1"}]}`,
+				))
+				h.AssertNil(t, baseImage.SetLabel(
+					"io.buildpacks.buildpack.layers",
+					`{"buildpack-1-id": {"buildpack-1-version-1": {"layerDigest": "sha256:bp-1-v1-sha"}, "buildpack-1-version-2": {"layerDigest": "sha256:bp-1-v2-sha"}}, "buildpack-2-id": {"buildpack-2-version-1": {"layerDigest": "sha256:bp-2-v1-sha"}}}`,
+				))
+
+				var err error
+				subject, err = builder.New(baseImage, "some/builder")
+				h.AssertNil(t, err)
+			})
+
+			it("removes the buildpack from the metadata and layers label", func() {
+				h.AssertNil(t, subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-1-id", Version: "buildpack-1-version-1"}))
+				h.AssertNil(t, subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-2-id"}))
+				h.AssertNil(t, subject.Save(logger))
+
+				label, err := baseImage.Label("io.buildpacks.builder.metadata")
+				h.AssertNil(t, err)
+				var metadata builder.Metadata
+				h.AssertNil(t, json.Unmarshal([]byte(label), &metadata))
+				h.AssertEq(t, len(metadata.Buildpacks), 1)
+				h.AssertEq(t, metadata.Buildpacks[0].Version, "buildpack-1-version-2")
+				h.AssertEq(t, metadata.Buildpacks[0].Latest, true)
+
+				label, err = baseImage.Label("io.buildpacks.buildpack.layers")
+				h.AssertNil(t, err)
+				var layers builder.BuildpackLayers
+				h.AssertNil(t, json.Unmarshal([]byte(label), &layers))
+				h.AssertEq(t, layers, builder.BuildpackLayers{
+					"buildpack-1-id": {"buildpack-1-version-2": {LayerDigest: "sha256:bp-1-v2-sha"}},
+				})
+			})
+
+			it("adds a layer that deletes the files of the buildpack", func() {
+				h.AssertNil(t, subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-1-id", Version: "buildpack-1-version-1"}))
+				h.AssertNil(t, subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-2-id"}))
+				h.AssertNil(t, subject.Save(logger))
+
+				layerTar, err := baseImage.FindLayerWithPath("/cnb/buildpacks/.wh.buildpack-2-id")
+				h.AssertNil(t, err)
+				for _, whiteout := range []string{
+					"/cnb/buildpacks/buildpack-1-id/.wh.buildpack-1-version-1",
+					"/cnb/buildpacks/.wh.buildpack-2-id",
+					"/buildpacks/buildpack-1-id/.wh.buildpack-1-version-1",
+					"/buildpacks/.wh.buildpack-2-id",
+				} {
+					h.AssertOnTarEntry(t, layerTar, whiteout, h.HasModTime(archive.NormalizedDateTime))
+				}
+				_, err = baseImage.FindLayerWithPath("/cnb/buildpacks/buildpack-1-id/.wh.buildpack-1-version-2")
+				h.AssertNotNil(t, err)
+			})
+
+			it("doesn't delete the files of a buildpack that is added back", func() {
+				h.AssertNil(t, subject.SetLifecycle(mockLifecycle))
+				h.AssertNil(t, subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-2-id"}))
+				subject.AddBuildpack(bp2v1)
+				h.AssertNil(t, subject.Save(logger))
+
+				_, err := baseImage.FindLayerWithPath("/cnb/buildpacks/.wh.buildpack-2-id")
+				h.AssertNotNil(t, err)
+			})
+
+			when("the buildpack is in the order of the builder", func() {
+				it("returns an error", func() {
+					subject.SetOrder(dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "buildpack-2-id"}}}}})
+
+					err := subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-2-id"})
+					h.AssertError(t, err, "buildpack 'buildpack-2-id@buildpack-2-version-1' is used by the order of the builder")
+				})
+			})
+
+			when("the buildpack does not exist", func() {
+				it("returns an error", func() {
+					err := subject.RemoveBuildpack(dist.BuildpackInfo{ID: "missing-id"})
+					h.AssertError(t, err, "buildpack 'missing-id' not found on the builder")
+				})
+			})
+
+			when("the version is ambiguous", func() {
+				it("returns an error", func() {
+					err := subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-1-id"})
+					h.AssertError(t, err, "multiple versions of 'buildpack-1-id' - must specify an explicit version")
+				})
+			})
+
+			when("the buildpack is used by an order buildpack", func() {
+				it("returns an error", func() {
+					subject.AddBuildpack(bpOrder)
+					subject.AddBuildpack(bp2v1)
+					h.AssertNil(t, subject.SetLifecycle(mockLifecycle))
+					h.AssertNil(t, subject.Save(logger))
+
+					bldr, err := builder.GetBuilder(baseImage)
+					h.AssertNil(t, err)
+
+					err = bldr.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-2-id"})
+					h.AssertError(t, err, "buildpack 'buildpack-2-id@buildpack-2-version-1' is used by buildpack 'order-buildpack-id@order-buildpack-version'")
+				})
+			})
+		})
+
 		when("#SetOrder", func() {
 			when("the buildpacks exist in the image", func() {
 				it.Before(func() {
@@ -838,6 +1187,20 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("#SetRunImageMirrors", func() {
+			it("replaces the run image mirrors", func() {
+				subject.SetStackInfo(builder.StackConfig{
+					RunImage:        "some/run",
+					RunImageMirrors: []string{"some/mirror"},
+				})
+				subject.SetRunImageMirrors([]string{"other/mirror"})
+				h.AssertNil(t, subject.Save(logger))
+
+				h.AssertEq(t, subject.GetStackInfo().RunImage.Image, "some/run")
+				h.AssertEq(t, subject.GetStackInfo().RunImage.Mirrors, []string{"other/mirror"})
+			})
+		})
+
 		when("#SetEnv", func() {
 			it.Before(func() {
 				subject.SetEnv(map[string]string{
@@ -929,6 +1292,18 @@ func (f *fakeBuildpack) Open() (io.ReadCloser, error) {
 	return archive.ReadDirAsTar(filepath.Join("testdata", "buildpack"), ".", 0, 0, 0755), nil
 }
 
+func layerDigest(t *testing.T, image *fakes.Image, path string) string {
+	t.Helper()
+
+	layerTar, err := image.FindLayerWithPath(path)
+	h.AssertNil(t, err)
+
+	contents, err := ioutil.ReadFile(layerTar)
+	h.AssertNil(t, err)
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(contents))
+}
+
 func assertImageHasBPLayer(t *testing.T, image *fakes.Image, bp dist.Buildpack) {
 	dirPath := fmt.Sprintf("/cnb/buildpacks/%s/%s", bp.Descriptor().Info.ID, bp.Descriptor().Info.Version)
 	layerTar, err := image.FindLayerWithPath(dirPath)
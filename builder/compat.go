@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sort"
 
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
@@ -22,6 +23,20 @@ const (
 	compatStackPath     = "/buildpacks/stack.toml"
 )
 
+// minCompatFreeBuildpackAPI and minCompatFreePlatformAPI are the first API versions of lifecycles that find
+// buildpacks, the lifecycle and stack.toml under /cnb.
+var (
+	minCompatFreeBuildpackAPI = api.MustParse("0.2")
+	minCompatFreePlatformAPI  = api.MustParse("0.2")
+)
+
+// requiresCompat returns whether a lifecycle relies on the paths of the compat layer.
+func requiresCompat(descriptor LifecycleDescriptor) bool {
+	bpAPI, platformAPI := descriptor.API.BuildpackVersion, descriptor.API.PlatformVersion
+	return bpAPI == nil || bpAPI.Compare(minCompatFreeBuildpackAPI) < 0 ||
+		platformAPI == nil || platformAPI.Compare(minCompatFreePlatformAPI) < 0
+}
+
 func (b *Builder) compatLayer(order dist.Order, dest string) (string, error) {
 	compatTar := path.Join(dest, "compat.tar")
 	fh, err := os.Create(compatTar)
@@ -59,7 +74,16 @@ func (b *Builder) compatBuildpacks(tw *tar.Writer) error {
 	if err := tw.WriteHeader(b.rootOwnedDir(compatBuildpacksDir, ts)); err != nil {
 		return errors.Wrapf(err, "creating %s dir in layer", style.Symbol(dist.BuildpacksDir))
 	}
-	for _, bp := range b.additionalBuildpacks {
+	bps := append([]dist.Buildpack{}, b.additionalBuildpacks...)
+	sort.SliceStable(bps, func(i, j int) bool {
+		left, right := bps[i].Descriptor(), bps[j].Descriptor()
+		if left.EscapedID() != right.EscapedID() {
+			return left.EscapedID() < right.EscapedID()
+		}
+		return left.Info.Version < right.Info.Version
+	})
+
+	for _, bp := range bps {
 		descriptor := bp.Descriptor()
 
 		compatDir := path.Join(compatBuildpacksDir, descriptor.EscapedID())
@@ -1,6 +1,7 @@
 package builder_test
 
 import (
+	"bytes"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
@@ -245,14 +246,14 @@ func testCompat(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
-	when("lifecycle >= 0.4.0", func() {
+	when("lifecycle 0.4.0", func() {
 		it.Before(func() {
 			mockLifecycle.EXPECT().Descriptor().Return(builder.LifecycleDescriptor{
 				Info: builder.LifecycleInfo{
 					Version: &builder.Version{Version: *semver.MustParse("0.4.0")},
 				},
 				API: builder.LifecycleAPI{
-					PlatformVersion:  api.MustParse("0.2"),
+					PlatformVersion:  api.MustParse("0.1"),
 					BuildpackVersion: api.MustParse("0.2"),
 				},
 			}).AnyTimes()
@@ -282,12 +283,12 @@ func testCompat(t *testing.T, when spec.G, it spec.S) {
 					},
 				}},
 			})
+		})
 
+		it("doesn't add the latest buildpack symlink", func() {
 			h.AssertNil(t, subject.Save(logger))
 			h.AssertEq(t, baseImage.IsSaved(), true)
-		})
 
-		it("doesn't add the latest buildpack symlink", func() {
 			layerTar, err := baseImage.FindLayerWithPath("/buildpacks/buildpack-1-id/buildpack-1-version-1")
 			h.AssertNil(t, err)
 
@@ -299,13 +300,42 @@ func testCompat(t *testing.T, when spec.G, it spec.S) {
 				}
 			}
 		})
+
+		it("should create a compat lifecycle symlink", func() {
+			h.AssertNil(t, subject.Save(logger))
+			h.AssertEq(t, baseImage.IsSaved(), true)
+
+			layerTar, err := baseImage.FindLayerWithPath("/lifecycle")
+			h.AssertNil(t, err)
+			h.AssertOnTarEntry(t, layerTar, "/lifecycle",
+				h.SymlinksTo("/cnb/lifecycle"),
+				h.HasModTime(archive.NormalizedDateTime),
+			)
+		})
+
+		it("records the compat paths", func() {
+			h.AssertNil(t, subject.Save(logger))
+
+			h.AssertEq(t, subject.CompatPaths(), []string{"/buildpacks", "/buildpacks/stack.toml", "/lifecycle"})
+			h.AssertEq(t, subject.LifecyclePath(), "/lifecycle")
+		})
+
+		when("compat is disabled", func() {
+			it("returns an error", func() {
+				subject.SetCompat(false)
+
+				err := subject.Save(logger)
+				h.AssertError(t, err, "lifecycle '0.4.0' requires the compat layer, it cannot be disabled")
+				h.AssertEq(t, baseImage.IsSaved(), false)
+			})
+		})
 	})
 
-	when("always", func() {
+	when("lifecycle supports Buildpack API 0.2 and Platform API 0.2", func() {
 		it.Before(func() {
 			mockLifecycle.EXPECT().Descriptor().Return(builder.LifecycleDescriptor{
 				Info: builder.LifecycleInfo{
-					Version: &builder.Version{Version: *semver.MustParse("0.4.0")},
+					Version: &builder.Version{Version: *semver.MustParse("0.5.0")},
 				},
 				API: builder.LifecycleAPI{
 					PlatformVersion:  api.MustParse("0.2"),
@@ -314,18 +344,63 @@ func testCompat(t *testing.T, when spec.G, it spec.S) {
 			}).AnyTimes()
 
 			h.AssertNil(t, subject.SetLifecycle(mockLifecycle))
+			subject.AddBuildpack(updateFakeAPIVersion(bp1v1, api.MustParse("0.2")))
 		})
 
-		it("should create a compat lifecycle symlink", func() {
+		it("doesn't add the compat layer", func() {
 			h.AssertNil(t, subject.Save(logger))
 			h.AssertEq(t, baseImage.IsSaved(), true)
 
-			layerTar, err := baseImage.FindLayerWithPath("/lifecycle")
+			for _, p := range []string{"/buildpacks", "/buildpacks/stack.toml", "/lifecycle"} {
+				_, err := baseImage.FindLayerWithPath(p)
+				h.AssertError(t, err, "Could not find")
+			}
+
+			h.AssertEq(t, len(subject.CompatPaths()), 0)
+			h.AssertEq(t, subject.LifecyclePath(), "/cnb/lifecycle")
+
+			bldr, err := builder.GetBuilder(baseImage)
 			h.AssertNil(t, err)
-			h.AssertOnTarEntry(t, layerTar, "/lifecycle",
-				h.SymlinksTo("/cnb/lifecycle"),
-				h.HasModTime(archive.NormalizedDateTime),
+			h.AssertEq(t, len(bldr.CompatPaths()), 0)
+		})
+
+		when("compat is enabled", func() {
+			it("adds the compat layer", func() {
+				subject.SetCompat(true)
+				h.AssertNil(t, subject.Save(logger))
+
+				layerTar, err := baseImage.FindLayerWithPath("/lifecycle")
+				h.AssertNil(t, err)
+				h.AssertOnTarEntry(t, layerTar, "/lifecycle", h.SymlinksTo("/cnb/lifecycle"))
+				h.AssertEq(t, subject.CompatPaths(), []string{"/buildpacks", "/buildpacks/stack.toml", "/lifecycle"})
+			})
+		})
+
+		when("the builder already contains compat paths", func() {
+			var (
+				existing *builder.Builder
+				buf      bytes.Buffer
 			)
+
+			it.Before(func() {
+				h.AssertNil(t, baseImage.SetLabel("io.buildpacks.builder.metadata", `{"buildpacks": []}`))
+
+				var err error
+				existing, err = builder.GetBuilder(baseImage)
+				h.AssertNil(t, err)
+				h.AssertNil(t, existing.SetLifecycle(mockLifecycle))
+			})
+
+			it("keeps them up to date", func() {
+				h.AssertEq(t, existing.LifecyclePath(), "/lifecycle")
+
+				existing.SetCompat(false)
+				h.AssertNil(t, existing.Save(logging.New(&buf)))
+
+				_, err := baseImage.FindLayerWithPath("/buildpacks/stack.toml")
+				h.AssertNil(t, err)
+				h.AssertContains(t, buf.String(), "builder 'some/builder' already contains compat paths, they cannot be removed")
+			})
 		})
 	})
 }
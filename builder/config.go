@@ -9,22 +9,42 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
 
+	"github.com/buildpack/pack/blob"
 	"github.com/buildpack/pack/dist"
 	"github.com/buildpack/pack/internal/paths"
 	"github.com/buildpack/pack/style"
 )
 
 type Config struct {
+	Base        BaseConfig        `toml:"base"`
 	Description string            `toml:"description"`
 	Buildpacks  []BuildpackConfig `toml:"buildpacks"`
 	Order       dist.Order        `toml:"order"`
 	Stack       StackConfig       `toml:"stack"`
 	Lifecycle   LifecycleConfig   `toml:"lifecycle"`
+	// Compat forces the compat layer to be added or omitted. When unset, it is added only if the lifecycle
+	// requires it.
+	Compat *bool `toml:"compat,omitempty"`
+}
+
+const (
+	OrderMergeAppend  = "append"
+	OrderMergePrepend = "prepend"
+	OrderMergeReplace = "replace"
+)
+
+// BaseConfig declares an existing builder to build on top of. Its buildpacks, stack and lifecycle are inherited
+// and its order is merged with the order of the config according to OrderMerge, which defaults to appending.
+type BaseConfig struct {
+	Builder    string `toml:"builder"`
+	OrderMerge string `toml:"order-merge"`
 }
 
 type BuildpackConfig struct {
 	dist.BuildpackInfo
 	URI string `toml:"uri"`
+	// SHA256 is the expected digest of the contents at URI. It is verified when it is set.
+	SHA256 string `toml:"sha256,omitempty"`
 }
 
 type StackConfig struct {
@@ -35,8 +55,13 @@ type StackConfig struct {
 }
 
 type LifecycleConfig struct {
-	URI     string `toml:"uri"`
+	URI string `toml:"uri"`
+	// SHA256 is the expected digest of the contents at URI. It is verified when it is set.
+	SHA256  string `toml:"sha256,omitempty"`
 	Version string `toml:"version"`
+	// URIs maps platforms, as <os>/<architecture>, to the URI of the lifecycle for that platform. They take
+	// precedence over URI and Version. Their expected digests may be declared with a #sha256=<hex> fragment.
+	URIs map[string]string `toml:"uris,omitempty"`
 }
 
 // ReadConfig reads a builder configuration from the file path provided and returns the
@@ -66,7 +91,7 @@ func ReadConfig(path string) (config Config, warnings []string, err error) {
 		return Config{}, nil, errors.Wrapf(err, "parse contents of '%s'", path)
 	}
 
-	if len(config.Order) == 0 {
+	if len(config.Order) == 0 && config.Base.Builder == "" {
 		warnings = append(warnings, fmt.Sprintf("empty %s definition", style.Symbol("order")))
 	}
 
@@ -127,5 +152,14 @@ func parseConfig(reader io.Reader, relativeToDir string) (Config, error) {
 		builderConfig.Lifecycle.URI = uri
 	}
 
+	for platform, uri := range builderConfig.Lifecycle.URIs {
+		uri, digest := blob.SplitSHA256(uri)
+		absURI, err := paths.ToAbsolute(uri, relativeToDir)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "transforming lifecycle URI for platform %s", style.Symbol(platform))
+		}
+		builderConfig.Lifecycle.URIs[platform] = blob.WithSHA256(absURI, digest)
+	}
+
 	return builderConfig, nil
 }
@@ -58,6 +58,89 @@ func testConfig(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("lifecycle URIs are provided per platform", func() {
+			it.Before(func() {
+				h.AssertNil(t, ioutil.WriteFile(builderConfigPath, []byte(`
+[lifecycle]
+  version = "0.5.0"
+
+[lifecycle.uris]
+  "linux/amd64" = "https://example.com/lifecycle-amd64.tgz"
+  "linux/arm64" = "lifecycle-arm64.tgz#sha256=some-digest"
+`), 0666))
+			})
+
+			it("returns the URIs with relative paths resolved", func() {
+				builderConfig, _, err := builder.ReadConfig(builderConfigPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, builderConfig.Lifecycle.Version, "0.5.0")
+				h.AssertEq(t, builderConfig.Lifecycle.URIs["linux/amd64"], "https://example.com/lifecycle-amd64.tgz")
+				h.AssertContains(t, builderConfig.Lifecycle.URIs["linux/arm64"], "file://")
+				h.AssertContains(t, builderConfig.Lifecycle.URIs["linux/arm64"], "lifecycle-arm64.tgz#sha256=some-digest")
+			})
+		})
+
+		when("checksums are declared", func() {
+			it.Before(func() {
+				h.AssertNil(t, ioutil.WriteFile(builderConfigPath, []byte(`
+[[buildpacks]]
+  id = "some.buildpack"
+  uri = "https://example.com/buildpack.tgz"
+  sha256 = "buildpack-digest"
+
+[lifecycle]
+  uri = "https://example.com/lifecycle.tgz"
+  sha256 = "lifecycle-digest"
+`), 0666))
+			})
+
+			it("returns the checksums", func() {
+				builderConfig, _, err := builder.ReadConfig(builderConfigPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, builderConfig.Buildpacks[0].SHA256, "buildpack-digest")
+				h.AssertEq(t, builderConfig.Lifecycle.SHA256, "lifecycle-digest")
+			})
+		})
+
+		when("a base builder is declared", func() {
+			it.Before(func() {
+				h.AssertNil(t, ioutil.WriteFile(builderConfigPath, []byte(`
+[base]
+  builder = "vendor/builder:tag"
+  order-merge = "prepend"
+
+[[buildpacks]]
+  id = "some.buildpack"
+`), 0666))
+			})
+
+			it("returns the base builder without warning about the empty order", func() {
+				builderConfig, warns, err := builder.ReadConfig(builderConfigPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, len(warns), 0)
+				h.AssertEq(t, builderConfig.Base, builder.BaseConfig{Builder: "vendor/builder:tag", OrderMerge: "prepend"})
+			})
+		})
+
+		when("compat is set", func() {
+			it.Before(func() {
+				h.AssertNil(t, ioutil.WriteFile(builderConfigPath, []byte(`
+compat = false
+
+[[order]]
+[[order.group]]
+  id = "some.buildpack"
+`), 0666))
+			})
+
+			it("returns the compat setting", func() {
+				builderConfig, _, err := builder.ReadConfig(builderConfigPath)
+				h.AssertNil(t, err)
+				h.AssertNotNil(t, builderConfig.Compat)
+				h.AssertEq(t, *builderConfig.Compat, false)
+			})
+		})
+
 		when("an error occurs while reading", func() {
 			it("bubbles up the error", func() {
 				_, _, err := builder.ReadConfig(builderConfigPath)
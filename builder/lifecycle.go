@@ -2,6 +2,10 @@ package builder
 
 import (
 	"archive/tar"
+	"bytes"
+	"debug/elf"
+	"debug/pe"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"path"
@@ -13,6 +17,7 @@ import (
 	"github.com/buildpack/pack/api"
 	"github.com/buildpack/pack/dist"
 	"github.com/buildpack/pack/internal/archive"
+	"github.com/buildpack/pack/style"
 )
 
 const (
@@ -143,3 +148,102 @@ func (l *lifecycle) validateBinaries() error {
 	}
 	return nil
 }
+
+// ValidateLifecyclePlatform returns an error when a lifecycle binary is an executable for a different operating system
+// or architecture than the one provided. Binaries whose format is not recognized are not checked.
+func ValidateLifecyclePlatform(lifecycle Blob, os, arch string) error {
+	rc, err := lifecycle.Open()
+	if err != nil {
+		return errors.Wrap(err, "create lifecycle blob reader")
+	}
+	defer rc.Close()
+
+	regex := regexp.MustCompile(`^[^/]+/([^/]+)$`)
+	binaries := map[string]bool{}
+	for _, b := range lifecycleBinaries {
+		binaries[b] = true
+	}
+
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to get next tar entry")
+		}
+
+		pathMatches := regex.FindStringSubmatch(path.Clean(header.Name))
+		if pathMatches == nil || !binaries[pathMatches[1]] {
+			continue
+		}
+
+		buf := make([]byte, 4096)
+		n, err := io.ReadFull(tr, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return errors.Wrapf(err, "reading lifecycle binary %s", style.Symbol(pathMatches[1]))
+		}
+
+		binaryOS, binaryArch, ok := executablePlatform(buf[:n])
+		if !ok {
+			continue
+		}
+		if binaryOS != os || binaryArch != arch {
+			return fmt.Errorf(
+				"lifecycle binary %s is built for %s but the build image is %s",
+				style.Symbol(pathMatches[1]),
+				style.Symbol(binaryOS+"/"+binaryArch),
+				style.Symbol(os+"/"+arch),
+			)
+		}
+	}
+	return nil
+}
+
+var (
+	elfArchitectures = map[elf.Machine]string{
+		elf.EM_X86_64:  "amd64",
+		elf.EM_386:     "386",
+		elf.EM_AARCH64: "arm64",
+		elf.EM_ARM:     "arm",
+		elf.EM_S390:    "s390x",
+	}
+	peArchitectures = map[uint16]string{
+		pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+		pe.IMAGE_FILE_MACHINE_I386:  "386",
+		pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+		pe.IMAGE_FILE_MACHINE_ARMNT: "arm",
+	}
+)
+
+// executablePlatform reads the operating system and architecture from the header of an ELF or PE executable.
+func executablePlatform(header []byte) (os, arch string, ok bool) {
+	switch {
+	case len(header) >= 20 && bytes.HasPrefix(header, []byte(elf.ELFMAG)):
+		var order binary.ByteOrder = binary.LittleEndian
+		if elf.Data(header[elf.EI_DATA]) == elf.ELFDATA2MSB {
+			order = binary.BigEndian
+		}
+
+		machine := elf.Machine(order.Uint16(header[18:20]))
+		if machine == elf.EM_PPC64 {
+			if order == binary.LittleEndian {
+				return "linux", "ppc64le", true
+			}
+			return "linux", "ppc64", true
+		}
+
+		arch, ok := elfArchitectures[machine]
+		return "linux", arch, ok
+	case len(header) >= 0x40 && bytes.HasPrefix(header, []byte("MZ")):
+		offset := int(binary.LittleEndian.Uint32(header[0x3c:0x40]))
+		if offset < 0 || offset+6 > len(header) || !bytes.Equal(header[offset:offset+4], []byte("PE\x00\x00")) {
+			return "", "", false
+		}
+
+		arch, ok := peArchitectures[binary.LittleEndian.Uint16(header[offset+4:offset+6])]
+		return "windows", arch, ok
+	}
+	return "", "", false
+}
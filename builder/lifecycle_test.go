@@ -2,6 +2,9 @@ package builder_test
 
 import (
 	"archive/tar"
+	"debug/elf"
+	"debug/pe"
+	"encoding/binary"
 	"io"
 	"io/ioutil"
 	"os"
@@ -76,6 +79,71 @@ func testLifecycle(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 	})
+
+	when("#ValidateLifecyclePlatform", func() {
+		var tmpDir string
+
+		writeLifecycle := func(header []byte) {
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpDir, "lifecycle"), os.ModePerm))
+			for _, name := range []string{"detector", "restorer", "analyzer", "builder", "exporter", "cacher", "launcher"} {
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "lifecycle", name), header, os.ModePerm))
+			}
+		}
+
+		elfHeader := func(machine elf.Machine) []byte {
+			header := make([]byte, 64)
+			copy(header, elf.ELFMAG)
+			header[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+			header[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+			binary.LittleEndian.PutUint16(header[18:], uint16(machine))
+			return header
+		}
+
+		peHeader := func(machine uint16) []byte {
+			header := make([]byte, 0x100)
+			copy(header, "MZ")
+			binary.LittleEndian.PutUint32(header[0x3c:], 0x80)
+			copy(header[0x80:], "PE\x00\x00")
+			binary.LittleEndian.PutUint16(header[0x84:], machine)
+			return header
+		}
+
+		it.Before(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "lifecycle-platform")
+			h.AssertNil(t, err)
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.RemoveAll(tmpDir))
+		})
+
+		it("accepts binaries built for the platform", func() {
+			writeLifecycle(elfHeader(elf.EM_AARCH64))
+			h.AssertNil(t, builder.ValidateLifecyclePlatform(blob.NewBlob(tmpDir), "linux", "arm64"))
+		})
+
+		it("rejects linux binaries built for another architecture", func() {
+			writeLifecycle(elfHeader(elf.EM_X86_64))
+			h.AssertError(t,
+				builder.ValidateLifecyclePlatform(blob.NewBlob(tmpDir), "linux", "arm64"),
+				"is built for 'linux/amd64' but the build image is 'linux/arm64'",
+			)
+		})
+
+		it("rejects windows binaries for linux build images", func() {
+			writeLifecycle(peHeader(pe.IMAGE_FILE_MACHINE_AMD64))
+			h.AssertError(t,
+				builder.ValidateLifecyclePlatform(blob.NewBlob(tmpDir), "linux", "amd64"),
+				"is built for 'windows/amd64' but the build image is 'linux/amd64'",
+			)
+		})
+
+		it("skips binaries in an unknown format", func() {
+			writeLifecycle([]byte("#!/bin/sh"))
+			h.AssertNil(t, builder.ValidateLifecyclePlatform(blob.NewBlob(tmpDir), "linux", "arm64"))
+		})
+	})
 }
 
 type fakeEmptyBlob struct {
@@ -8,8 +8,9 @@ const BuildpackLayersLabel = "io.buildpacks.buildpack.layers"
 type BuildpackLayers map[string]map[string]BuildpackLayerInfo
 
 type BuildpackLayerInfo struct {
-	LayerDigest string     `json:"layerDigest"`
-	Order       dist.Order `json:"order,omitempty"`
+	LayerDigest string       `json:"layerDigest"`
+	Order       dist.Order   `json:"order,omitempty"`
+	Stacks      []dist.Stack `json:"stacks,omitempty"`
 }
 
 type Metadata struct {
@@ -18,6 +19,9 @@ type Metadata struct {
 	Stack       StackMetadata       `json:"stack"`
 	Lifecycle   LifecycleMetadata   `json:"lifecycle"`
 	CreatedBy   CreatorMetadata     `json:"createdBy"`
+	// Compat records whether the builder contains the compat paths needed by older lifecycles. It is missing from
+	// builders created before it was introduced, all of which contain them.
+	Compat *bool `json:"compat,omitempty"`
 }
 
 type CreatorMetadata struct {
@@ -53,8 +57,6 @@ func processMetadata(md *Metadata) {
 			}
 		}
 
-		if len(matchingBps) == 1 {
-			md.Buildpacks[i].Latest = true
-		}
+		md.Buildpacks[i].Latest = len(matchingBps) == 1
 	}
 }
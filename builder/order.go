@@ -0,0 +1,356 @@
+package builder
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/style"
+)
+
+// MaxFlattenedGroups is the largest number of groups FlattenOrder expands an order into. Every order buildpack
+// multiplies the number of groups by the number of groups of its own order, so larger orders are not expanded.
+const MaxFlattenedGroups = 1000
+
+// ErrTooManyGroups is returned by FlattenOrder when the flattened order would have more than MaxFlattenedGroups
+// groups.
+var ErrTooManyGroups = fmt.Errorf("flattened order has more than %d groups", MaxFlattenedGroups)
+
+// FlattenOrder expands the order buildpacks in order into the groups of their own orders, recursively, the way the
+// lifecycle does during detection. Buildpacks are resolved from layers; a reference without a version is resolved
+// when only one version of the buildpack exists. An error is returned when a buildpack is missing, when orders
+// reference each other in a cycle, or when the flattened order would be larger than MaxFlattenedGroups.
+func FlattenOrder(order dist.Order, layers BuildpackLayers) (dist.Order, error) {
+	if err := ValidateOrder(order, layers, ""); err != nil {
+		return nil, err
+	}
+	return flattenOrder(order, layers, nil)
+}
+
+// FlattenBuilderOrder flattens the order of a builder. Buildpacks of older builders, which are missing from the
+// buildpack layers label, are treated as buildpacks without an order.
+func FlattenBuilderOrder(order dist.Order, buildpacks []BuildpackMetadata, layers BuildpackLayers) (dist.Order, error) {
+	return FlattenOrder(order, mergeLayers(buildpacks, layers))
+}
+
+// mergeLayers returns a copy of layers that also contains buildpacks missing from it, without an order or stacks.
+func mergeLayers(buildpacks []BuildpackMetadata, layers BuildpackLayers) BuildpackLayers {
+	merged := BuildpackLayers{}
+	for _, bp := range buildpacks {
+		if _, ok := merged[bp.ID]; !ok {
+			merged[bp.ID] = map[string]BuildpackLayerInfo{}
+		}
+		merged[bp.ID][bp.Version] = BuildpackLayerInfo{}
+	}
+
+	for id, versions := range layers {
+		if _, ok := merged[id]; !ok {
+			merged[id] = map[string]BuildpackLayerInfo{}
+		}
+		for version, info := range versions {
+			merged[id][version] = info
+		}
+	}
+	return merged
+}
+
+// ValidateBuilderOrder checks the order of a builder like FlattenBuilderOrder, without expanding it.
+func ValidateBuilderOrder(order dist.Order, buildpacks []BuildpackMetadata, layers BuildpackLayers) error {
+	return ValidateOrder(order, mergeLayers(buildpacks, layers), "")
+}
+
+// ValidateOrder checks that every buildpack referenced by order, directly or through the orders of order
+// buildpacks, exists in layers and supports stackID, unless stackID is empty, and that orders don't reference each
+// other in a cycle. Buildpacks are resolved from layers as in FlattenOrder.
+func ValidateOrder(order dist.Order, layers BuildpackLayers, stackID string) error {
+	return validateOrder(order, layers, stackID, nil, map[string]bool{})
+}
+
+// validateOrders checks the order of every order buildpack in layers, as well as the builder order, as in
+// ValidateOrder. Buildpacks whose stacks are unknown are not checked.
+func validateOrders(order dist.Order, layers BuildpackLayers, stackID string) error {
+	var keys []string
+	for id, versions := range layers {
+		for version, info := range versions {
+			if len(info.Order) > 0 {
+				keys = append(keys, id+"@"+version)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	validated := map[string]bool{}
+	for _, key := range keys {
+		if validated[key] {
+			continue
+		}
+
+		parts := strings.SplitN(key, "@", 2)
+		if err := validateOrder(layers[parts[0]][parts[1]].Order, layers, stackID, []string{key}, validated); err != nil {
+			return err
+		}
+		validated[key] = true
+	}
+
+	return validateOrder(order, layers, stackID, nil, validated)
+}
+
+// validateOrder walks the buildpacks referenced by order depth first. The orders of the order buildpacks in
+// validated were already checked and are skipped, so each order is checked once regardless of how often it is
+// referenced.
+func validateOrder(order dist.Order, layers BuildpackLayers, stackID string, path []string, validated map[string]bool) error {
+	for _, entry := range order {
+		for _, ref := range entry.Group {
+			resolved, info, err := resolveLayerRef(ref, layers, path)
+			if err != nil {
+				return err
+			}
+
+			key := resolved.ID + "@" + resolved.Version
+			if len(info.Order) == 0 {
+				if stackID != "" && len(info.Stacks) > 0 {
+					descriptor := dist.BuildpackDescriptor{Stacks: info.Stacks}
+					if !descriptor.SupportsStack(stackID) {
+						return fmt.Errorf("buildpack %s does not support stack %s%s", style.Symbol(key), style.Symbol(stackID), requiredBy(path))
+					}
+				}
+				continue
+			}
+
+			for i, p := range path {
+				if p == key {
+					return fmt.Errorf("buildpack order contains a cycle: %s", refPath(append(path[i:len(path):len(path)], key)))
+				}
+			}
+
+			if validated[key] {
+				continue
+			}
+
+			if err := validateOrder(info.Order, layers, stackID, append(path[:len(path):len(path)], key), validated); err != nil {
+				return err
+			}
+			validated[key] = true
+		}
+	}
+	return nil
+}
+
+// flattenOrder expands an order that was validated by validateOrder.
+func flattenOrder(order dist.Order, layers BuildpackLayers, path []string) (dist.Order, error) {
+	flattened := dist.Order{}
+	for _, entry := range order {
+		groups, err := flattenGroup(entry, layers, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(flattened)+len(groups) > MaxFlattenedGroups {
+			return nil, ErrTooManyGroups
+		}
+		flattened = append(flattened, groups...)
+	}
+	return flattened, nil
+}
+
+// flattenGroup returns one group for each combination of the flattened groups of the order buildpacks in entry.
+func flattenGroup(entry dist.OrderEntry, layers BuildpackLayers, path []string) (dist.Order, error) {
+	groups := dist.Order{{}}
+	for _, ref := range entry.Group {
+		resolved, info, err := resolveLayerRef(ref, layers, path)
+		if err != nil {
+			return nil, err
+		}
+
+		alternatives := dist.Order{{Group: []dist.BuildpackRef{resolved}}}
+		if len(info.Order) > 0 {
+			alternatives, err = flattenOrder(info.Order, layers, append(path[:len(path):len(path)], resolved.ID+"@"+resolved.Version))
+			if err != nil {
+				return nil, err
+			}
+
+			if resolved.Optional {
+				for _, alternative := range alternatives {
+					for i := range alternative.Group {
+						alternative.Group[i].Optional = true
+					}
+				}
+			}
+		}
+
+		if len(groups)*len(alternatives) > MaxFlattenedGroups {
+			return nil, ErrTooManyGroups
+		}
+
+		var next dist.Order
+		for _, group := range groups {
+			for _, alternative := range alternatives {
+				combined := append(append([]dist.BuildpackRef{}, group.Group...), alternative.Group...)
+				next = append(next, dist.OrderEntry{Group: combined})
+			}
+		}
+		groups = next
+	}
+	return groups, nil
+}
+
+func resolveLayerRef(ref dist.BuildpackRef, layers BuildpackLayers, path []string) (dist.BuildpackRef, BuildpackLayerInfo, error) {
+	versions := layers[ref.ID]
+	if ref.Version == "" {
+		switch len(versions) {
+		case 0:
+			return ref, BuildpackLayerInfo{}, fmt.Errorf("no versions of buildpack %s were found on the builder%s", style.Symbol(ref.ID), requiredBy(path))
+		case 1:
+			for version := range versions {
+				ref.Version = version
+			}
+		default:
+			return ref, BuildpackLayerInfo{}, fmt.Errorf("unable to resolve version: multiple versions of %s - must specify an explicit version%s", style.Symbol(ref.ID), requiredBy(path))
+		}
+	}
+
+	info, ok := versions[ref.Version]
+	if !ok && len(path) == 0 {
+		// only the builder order may use version ranges, the lifecycle expects exact versions in nested orders
+		var available []string
+		for version := range versions {
+			available = append(available, version)
+		}
+
+		if version, err := ResolveVersion(ref.ID, ref.Version, available); err == nil {
+			ref.Version = version
+			info, ok = versions[version]
+		}
+	}
+	if !ok {
+		return ref, BuildpackLayerInfo{}, fmt.Errorf("buildpack %s not found on the builder%s", style.Symbol(ref.ID+"@"+ref.Version), requiredBy(path))
+	}
+	return ref, info, nil
+}
+
+func requiredBy(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return " (required by " + refPath(path) + ")"
+}
+
+func refPath(path []string) string {
+	var refs []string
+	for _, p := range path {
+		refs = append(refs, style.Symbol(p))
+	}
+	return strings.Join(refs, " -> ")
+}
+
+// MergeOrder merges order into the order of a base builder using strategy, one of OrderMergeAppend (the default),
+// OrderMergePrepend or OrderMergeReplace.
+func MergeOrder(base, order dist.Order, strategy string) (dist.Order, error) {
+	switch strategy {
+	case "", OrderMergeAppend:
+		return append(append(dist.Order{}, base...), order...), nil
+	case OrderMergePrepend:
+		return append(append(dist.Order{}, order...), base...), nil
+	case OrderMergeReplace:
+		return append(dist.Order{}, order...), nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown order merge strategy %s, must be one of %s, %s or %s",
+			style.Symbol(strategy), style.Symbol(OrderMergeAppend), style.Symbol(OrderMergePrepend), style.Symbol(OrderMergeReplace),
+		)
+	}
+}
+
+// constraintSeparator matches whitespace between two constraints of a version range.
+var constraintSeparator = regexp.MustCompile(`([0-9A-Za-z*])\s+([<>=!~^]|v?[0-9*xX])`)
+
+// partialVersion matches versions missing their minor or patch number.
+var partialVersion = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)?$`)
+
+// ResolveVersion returns the version of buildpack id, out of the available versions, that version refers to.
+// version may be empty when a single version is available, an exact version, or a semantic version range such as
+// "^1.2", "~2.0.1" or ">=1.0 <2", in which case the highest matching version is returned.
+func ResolveVersion(id, version string, available []string) (string, error) {
+	if len(available) == 0 {
+		return "", fmt.Errorf("no versions of buildpack %s were found on the builder", style.Symbol(id))
+	}
+
+	if version == "" {
+		if len(available) > 1 {
+			return "", fmt.Errorf("unable to resolve version: multiple versions of %s - must specify an explicit version", style.Symbol(id))
+		}
+		return available[0], nil
+	}
+
+	for _, v := range available {
+		if v == version {
+			return v, nil
+		}
+	}
+
+	var constraints *semver.Constraints
+	if _, err := semver.NewVersion(version); err != nil {
+		constraints, _ = semver.NewConstraint(normalizeConstraint(version))
+	}
+	if constraints == nil {
+		return "", fmt.Errorf(
+			"buildpack %s with version %s was not found on the builder (available versions: %s)",
+			style.Symbol(id), style.Symbol(version), availableVersions(available),
+		)
+	}
+
+	var (
+		best        *semver.Version
+		bestVersion string
+	)
+	for _, v := range available {
+		sv, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+
+		if constraints.Check(sv) && (best == nil || sv.GreaterThan(best)) {
+			best, bestVersion = sv, v
+		}
+	}
+
+	if best == nil {
+		return "", fmt.Errorf(
+			"no version of buildpack %s matches %s (available versions: %s)",
+			style.Symbol(id), style.Symbol(version), availableVersions(available),
+		)
+	}
+	return bestVersion, nil
+}
+
+// normalizeConstraint separates the constraints of a range with commas and completes partial versions of "<"
+// constraints, which the semver library would otherwise compare as wildcards ("<2" matching "2.1.0").
+func normalizeConstraint(constraint string) string {
+	var parts []string
+	for _, part := range strings.Split(constraintSeparator.ReplaceAllString(strings.TrimSpace(constraint), "$1,$2"), ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "<") && !strings.HasPrefix(part, "<=") {
+			v := strings.TrimSpace(strings.TrimPrefix(part, "<"))
+			if partialVersion.MatchString(v) {
+				part = "<" + v + strings.Repeat(".0", 2-strings.Count(v, "."))
+			}
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
+
+func availableVersions(versions []string) string {
+	sorted := append([]string{}, versions...)
+	sort.Strings(sorted)
+
+	var symbols []string
+	for _, v := range sorted {
+		symbols = append(symbols, style.Symbol(v))
+	}
+	return strings.Join(symbols, ", ")
+}
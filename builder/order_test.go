@@ -0,0 +1,210 @@
+package builder_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/dist"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestOrder(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "testOrder", testOrder, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testOrder(t *testing.T, when spec.G, it spec.S) {
+	ref := func(id, version string, optional bool) dist.BuildpackRef {
+		return dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: id, Version: version}, Optional: optional}
+	}
+
+	when("#FlattenOrder", func() {
+		var layers builder.BuildpackLayers
+
+		it.Before(func() {
+			layers = builder.BuildpackLayers{
+				"bp.a": {"1.0.0": {LayerDigest: "sha256:a"}},
+				"bp.b": {"1.0.0": {LayerDigest: "sha256:b"}},
+				"bp.c": {"1.0.0": {LayerDigest: "sha256:c"}},
+				"bp.inner": {"1.0.0": {
+					LayerDigest: "sha256:inner",
+					Order: dist.Order{
+						{Group: []dist.BuildpackRef{ref("bp.b", "1.0.0", false)}},
+						{Group: []dist.BuildpackRef{ref("bp.c", "", false)}},
+					},
+				}},
+				"bp.outer": {"1.0.0": {
+					LayerDigest: "sha256:outer",
+					Order: dist.Order{
+						{Group: []dist.BuildpackRef{ref("bp.a", "1.0.0", false), ref("bp.inner", "1.0.0", true)}},
+					},
+				}},
+			}
+		})
+
+		it("expands nested orders into one group per combination", func() {
+			flattened, err := builder.FlattenOrder(dist.Order{
+				{Group: []dist.BuildpackRef{ref("bp.outer", "1.0.0", false), ref("bp.c", "1.0.0", false)}},
+			}, layers)
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, flattened, dist.Order{
+				{Group: []dist.BuildpackRef{ref("bp.a", "1.0.0", false), ref("bp.b", "1.0.0", true), ref("bp.c", "1.0.0", false)}},
+				{Group: []dist.BuildpackRef{ref("bp.a", "1.0.0", false), ref("bp.c", "1.0.0", true), ref("bp.c", "1.0.0", false)}},
+			})
+		})
+
+		when("orders reference each other", func() {
+			it("reports the cycle", func() {
+				layers["bp.b"]["1.0.0"] = builder.BuildpackLayerInfo{Order: dist.Order{
+					{Group: []dist.BuildpackRef{ref("bp.outer", "1.0.0", false)}},
+				}}
+
+				_, err := builder.FlattenOrder(dist.Order{{Group: []dist.BuildpackRef{ref("bp.outer", "1.0.0", false)}}}, layers)
+				h.AssertError(t, err, "buildpack order contains a cycle: 'bp.outer@1.0.0' -> 'bp.inner@1.0.0' -> 'bp.b@1.0.0' -> 'bp.outer@1.0.0'")
+			})
+		})
+
+		when("a nested buildpack is missing", func() {
+			it("reports the buildpacks that require it", func() {
+				delete(layers, "bp.b")
+
+				_, err := builder.FlattenOrder(dist.Order{{Group: []dist.BuildpackRef{ref("bp.outer", "1.0.0", false)}}}, layers)
+				h.AssertError(t, err, "buildpack 'bp.b@1.0.0' not found on the builder (required by 'bp.outer@1.0.0' -> 'bp.inner@1.0.0')")
+			})
+		})
+	})
+
+	when("#ValidateOrder", func() {
+		var layers builder.BuildpackLayers
+
+		// each order buildpack has two groups referencing the next one, doubling the number of flattened groups
+		chainedLayers := func(depth int) builder.BuildpackLayers {
+			layers := builder.BuildpackLayers{
+				"bp.leaf": {"1.0.0": {Stacks: []dist.Stack{{ID: "some.stack.id"}}}},
+			}
+			next := ref("bp.leaf", "1.0.0", false)
+			for i := 0; i < depth; i++ {
+				id := fmt.Sprintf("bp.order-%d", i)
+				layers[id] = map[string]builder.BuildpackLayerInfo{"1.0.0": {Order: dist.Order{
+					{Group: []dist.BuildpackRef{next, next}},
+					{Group: []dist.BuildpackRef{next}},
+				}}}
+				next = ref(id, "1.0.0", false)
+			}
+			return layers
+		}
+
+		it.Before(func() {
+			layers = chainedLayers(40)
+		})
+
+		it("checks orders whose flattened order is too large to expand", func() {
+			order := dist.Order{{Group: []dist.BuildpackRef{ref("bp.order-39", "1.0.0", false)}}}
+			h.AssertNil(t, builder.ValidateOrder(order, layers, "some.stack.id"))
+
+			_, err := builder.FlattenOrder(order, layers)
+			h.AssertSameInstance(t, err, builder.ErrTooManyGroups)
+		})
+
+		it("reports buildpacks that don't support the stack", func() {
+			err := builder.ValidateOrder(dist.Order{{Group: []dist.BuildpackRef{ref("bp.order-1", "1.0.0", false)}}}, layers, "other.stack.id")
+			h.AssertError(t, err, "buildpack 'bp.leaf@1.0.0' does not support stack 'other.stack.id' (required by 'bp.order-1@1.0.0' -> 'bp.order-0@1.0.0')")
+		})
+
+		it("reports cycles", func() {
+			layers["bp.order-0"]["1.0.0"] = builder.BuildpackLayerInfo{Order: dist.Order{
+				{Group: []dist.BuildpackRef{ref("bp.order-2", "1.0.0", false)}},
+			}}
+
+			err := builder.ValidateOrder(dist.Order{{Group: []dist.BuildpackRef{ref("bp.order-3", "1.0.0", false)}}}, layers, "")
+			h.AssertError(t, err, "buildpack order contains a cycle: 'bp.order-2@1.0.0' -> 'bp.order-1@1.0.0' -> 'bp.order-0@1.0.0' -> 'bp.order-2@1.0.0'")
+		})
+	})
+
+	when("#ResolveVersion", func() {
+		available := []string{"1.2.0", "1.10.1", "2.0.1", "2.0.5", "2.1.0", "3.0.0-rc.1"}
+
+		it("returns an exact version", func() {
+			version, err := builder.ResolveVersion("bp.a", "2.0.1", available)
+			h.AssertNil(t, err)
+			h.AssertEq(t, version, "2.0.1")
+		})
+
+		it("returns the highest version matching a caret range", func() {
+			version, err := builder.ResolveVersion("bp.a", "^1.2", available)
+			h.AssertNil(t, err)
+			h.AssertEq(t, version, "1.10.1")
+		})
+
+		it("returns the highest version matching a tilde range", func() {
+			version, err := builder.ResolveVersion("bp.a", "~2.0.1", available)
+			h.AssertNil(t, err)
+			h.AssertEq(t, version, "2.0.5")
+		})
+
+		it("supports constraints separated by whitespace", func() {
+			version, err := builder.ResolveVersion("bp.a", ">=1.0 <2", available)
+			h.AssertNil(t, err)
+			h.AssertEq(t, version, "1.10.1")
+
+			version, err = builder.ResolveVersion("bp.a", ">= 2.0, < 3", available)
+			h.AssertNil(t, err)
+			h.AssertEq(t, version, "2.1.0")
+		})
+
+		it("resolves an empty version when a single version is available", func() {
+			version, err := builder.ResolveVersion("bp.a", "", []string{"1.0.0"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, version, "1.0.0")
+		})
+
+		when("no version matches the range", func() {
+			it("lists the available versions", func() {
+				_, err := builder.ResolveVersion("bp.a", "^4", available)
+				h.AssertError(t, err, "no version of buildpack 'bp.a' matches '^4' (available versions: '1.10.1', '1.2.0', '2.0.1', '2.0.5', '2.1.0', '3.0.0-rc.1')")
+			})
+		})
+
+		when("the exact version is missing", func() {
+			it("lists the available versions", func() {
+				_, err := builder.ResolveVersion("bp.a", "1.2.3", []string{"1.2.0"})
+				h.AssertError(t, err, "buildpack 'bp.a' with version '1.2.3' was not found on the builder (available versions: '1.2.0')")
+			})
+		})
+	})
+
+	when("#MergeOrder", func() {
+		base := dist.Order{{Group: []dist.BuildpackRef{ref("bp.a", "1.0.0", false)}}}
+		order := dist.Order{{Group: []dist.BuildpackRef{ref("bp.b", "1.0.0", false)}}}
+
+		it("appends the order by default", func() {
+			merged, err := builder.MergeOrder(base, order, "")
+			h.AssertNil(t, err)
+			h.AssertEq(t, merged, dist.Order{base[0], order[0]})
+		})
+
+		it("prepends the order", func() {
+			merged, err := builder.MergeOrder(base, order, builder.OrderMergePrepend)
+			h.AssertNil(t, err)
+			h.AssertEq(t, merged, dist.Order{order[0], base[0]})
+		})
+
+		it("replaces the order", func() {
+			merged, err := builder.MergeOrder(base, order, builder.OrderMergeReplace)
+			h.AssertNil(t, err)
+			h.AssertEq(t, merged, order)
+		})
+
+		it("fails for an unknown strategy", func() {
+			_, err := builder.MergeOrder(base, order, "shuffle")
+			h.AssertError(t, err, "unknown order merge strategy 'shuffle'")
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/dist"
+)
+
+const ProvenanceLabel = "io.buildpacks.builder.provenance"
+
+// Provenance records where the contents of a builder came from.
+type Provenance struct {
+	BuildImage ImageProvenance       `json:"buildImage"`
+	Lifecycle  SourceProvenance      `json:"lifecycle"`
+	Buildpacks []BuildpackProvenance `json:"buildpacks"`
+	Parameters ProvenanceParameters  `json:"parameters"`
+}
+
+type ImageProvenance struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// SourceProvenance is where a part of a builder was downloaded from. ETag is the ETag the server sent with the
+// download, if any.
+type SourceProvenance struct {
+	URI    string `json:"uri"`
+	Digest string `json:"digest"`
+	ETag   string `json:"etag,omitempty"`
+}
+
+type BuildpackProvenance struct {
+	dist.BuildpackInfo
+	URI         string `json:"uri"`
+	Digest      string `json:"digest"`
+	ETag        string `json:"etag,omitempty"`
+	LayerDigest string `json:"layerDigest"`
+}
+
+// ProvenanceParameters are the parameters the builder was created with.
+type ProvenanceParameters struct {
+	BuilderName string `json:"builderName"`
+	Publish     bool   `json:"publish"`
+	NoPull      bool   `json:"noPull"`
+}
+
+// BlobDigest returns the sha256 digest of the contents of blob.
+func BlobDigest(blob Blob) (string, error) {
+	rc, err := blob.Open()
+	if err != nil {
+		return "", errors.Wrap(err, "open blob")
+	}
+	defer rc.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, rc); err != nil {
+		return "", errors.Wrap(err, "read blob")
+	}
+	return fmt.Sprintf("sha256:%x", hasher.Sum(nil)), nil
+}
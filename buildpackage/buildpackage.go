@@ -5,9 +5,19 @@ import "github.com/buildpack/pack/dist"
 const MetadataLabel = "io.buildpacks.buildpackage.metadata"
 
 type Config struct {
-	Default dist.BuildpackInfo `toml:"default"`
-	Blobs   []dist.BlobConfig  `toml:"blobs"`
-	Stacks  []dist.Stack       `toml:"stacks"`
+	Default      dist.BuildpackInfo `toml:"default"`
+	Blobs        []dist.BlobConfig  `toml:"blobs"`
+	Dependencies []Dependency       `toml:"dependencies"`
+	Stacks       []dist.Stack       `toml:"stacks"`
+}
+
+// Dependency is another buildpackage whose buildpacks are included in a package. It is referenced either by the path
+// or URI of a buildpackage file or by the name of a buildpackage image.
+type Dependency struct {
+	URI string `toml:"uri"`
+	// SHA256 is the expected digest of the contents at URI. It is verified when it is set.
+	SHA256 string `toml:"sha256,omitempty"`
+	Image  string `toml:"image"`
 }
 
 type Metadata struct {
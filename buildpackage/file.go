@@ -0,0 +1,343 @@
+package buildpackage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/ioutils"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/internal/archive"
+	"github.com/buildpack/pack/internal/paths"
+	"github.com/buildpack/pack/style"
+)
+
+// FileExtension is the extension of buildpackage files.
+const FileExtension = ".cnb"
+
+const (
+	layoutFile    = "oci-layout"
+	indexFile     = "index.json"
+	layoutVersion = `{"imageLayoutVersion":"1.0.0"}`
+)
+
+// IsFile returns true when pathOrURI refers to a buildpackage file rather than to a buildpack.
+func IsFile(pathOrURI string) bool {
+	pathOrURI, _ = blob.SplitSHA256(pathOrURI)
+	if paths.IsURI(pathOrURI) {
+		if u, err := url.Parse(pathOrURI); err == nil {
+			pathOrURI = u.Path
+		}
+	}
+	return strings.EqualFold(path.Ext(pathOrURI), FileExtension)
+}
+
+// FileImage writes a buildpackage file, a tar archive containing an OCI image layout with a single image. It is
+// populated the same way as a buildpackage image.
+type FileImage struct {
+	path   string
+	labels map[string]string
+	layers []string
+}
+
+func NewFileImage(path string) *FileImage {
+	return &FileImage{
+		path:   path,
+		labels: map[string]string{},
+	}
+}
+
+func (f *FileImage) SetLabel(name, value string) error {
+	f.labels[name] = value
+	return nil
+}
+
+func (f *FileImage) AddLayer(path string) error {
+	f.layers = append(f.layers, path)
+	return nil
+}
+
+// Save writes the buildpackage file and returns the digest of the image manifest.
+func (f *FileImage) Save() (string, error) {
+	manifest := v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+	}
+	config := v1.ConfigFile{
+		Architecture: "amd64",
+		OS:           "linux",
+		Created:      v1.Time{Time: archive.NormalizedDateTime},
+		RootFS:       v1.RootFS{Type: "layers"},
+		Config:       v1.Config{Labels: f.labels},
+	}
+
+	for _, layer := range f.layers {
+		digest, size, err := fileSHA256(layer)
+		if err != nil {
+			return "", errors.Wrapf(err, "computing digest of layer %s", style.Symbol(layer))
+		}
+
+		manifest.Layers = append(manifest.Layers, v1.Descriptor{
+			MediaType: types.OCIUncompressedLayer,
+			Size:      size,
+			Digest:    digest,
+		})
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, digest)
+	}
+
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling image config")
+	}
+	manifest.Config, err = jsonDescriptor(types.OCIConfigJSON, configBytes)
+	if err != nil {
+		return "", err
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling image manifest")
+	}
+	manifestDescriptor, err := jsonDescriptor(types.OCIManifestSchema1, manifestBytes)
+	if err != nil {
+		return "", err
+	}
+
+	indexBytes, err := json.Marshal(v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+		Manifests:     []v1.Descriptor{manifestDescriptor},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling image index")
+	}
+
+	// the file is written next to its destination and renamed into place once complete, so that a failed save
+	// neither leaves a partial buildpackage file behind nor destroys an existing one
+	fh, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp-")
+	if err != nil {
+		return "", errors.Wrapf(err, "creating buildpackage file %s", style.Symbol(f.path))
+	}
+	defer os.Remove(fh.Name())
+	defer fh.Close()
+
+	tw := tar.NewWriter(fh)
+	entries := []struct {
+		path     string
+		contents []byte
+	}{
+		{layoutFile, []byte(layoutVersion)},
+		{indexFile, indexBytes},
+		{blobPath(manifestDescriptor.Digest), manifestBytes},
+		{blobPath(manifest.Config.Digest), configBytes},
+	}
+	for _, entry := range entries {
+		if err := archive.AddFileToTar(tw, entry.path, string(entry.contents)); err != nil {
+			return "", errors.Wrapf(err, "writing %s", style.Symbol(entry.path))
+		}
+	}
+
+	for i, layer := range f.layers {
+		if err := addLayerToTar(tw, blobPath(manifest.Layers[i].Digest), layer); err != nil {
+			return "", errors.Wrapf(err, "writing layer %s", style.Symbol(layer))
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := fh.Chmod(0644); err != nil {
+		return "", err
+	}
+	if err := fh.Sync(); err != nil {
+		return "", err
+	}
+	if err := fh.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(fh.Name(), f.path); err != nil {
+		return "", errors.Wrapf(err, "writing buildpackage file %s", style.Symbol(f.path))
+	}
+	return manifestDescriptor.Digest.String(), nil
+}
+
+// File is a buildpackage file read from a blob. Like a buildpackage image, it provides the labels of the image and
+// its layers by diff ID.
+type File struct {
+	blob     dist.Blob
+	manifest v1.Manifest
+	config   v1.ConfigFile
+}
+
+// OpenFile reads the image manifest and config of the buildpackage file in blob.
+func OpenFile(blob dist.Blob) (*File, error) {
+	var index v1.IndexManifest
+	if err := readJSONEntry(blob, indexFile, &index); err != nil {
+		return nil, err
+	}
+
+	if len(index.Manifests) != 1 {
+		return nil, fmt.Errorf("buildpackage file must contain exactly one image, found %d", len(index.Manifests))
+	}
+
+	f := &File{blob: blob}
+	if err := readJSONEntry(blob, blobPath(index.Manifests[0].Digest), &f.manifest); err != nil {
+		return nil, errors.Wrap(err, "reading image manifest")
+	}
+
+	if err := readJSONEntry(blob, blobPath(f.manifest.Config.Digest), &f.config); err != nil {
+		return nil, errors.Wrap(err, "reading image config")
+	}
+
+	if len(f.config.RootFS.DiffIDs) != len(f.manifest.Layers) {
+		return nil, errors.New("image config and manifest of buildpackage file have a different number of layers")
+	}
+
+	if _, ok := f.config.Config.Labels[MetadataLabel]; !ok {
+		return nil, errors.Errorf("file is not a buildpackage: missing label %s", style.Symbol(MetadataLabel))
+	}
+
+	return f, nil
+}
+
+func (f *File) Label(name string) (string, error) {
+	return f.config.Config.Labels[name], nil
+}
+
+// GetLayer returns the uncompressed contents of the layer with diffID.
+func (f *File) GetLayer(diffID string) (io.ReadCloser, error) {
+	for i, layerDiffID := range f.config.RootFS.DiffIDs {
+		if layerDiffID.String() != diffID {
+			continue
+		}
+
+		layer := f.manifest.Layers[i]
+		rc, err := openEntry(f.blob, blobPath(layer.Digest))
+		if err != nil {
+			return nil, err
+		}
+
+		if layer.MediaType != types.OCILayer && layer.MediaType != types.DockerLayer {
+			return rc, nil
+		}
+
+		gzr, err := gzip.NewReader(rc)
+		if err != nil {
+			rc.Close()
+			return nil, errors.Wrapf(err, "decompressing layer %s", style.Symbol(diffID))
+		}
+		return ioutils.NewReadCloserWrapper(gzr, func() error {
+			defer rc.Close()
+			return gzr.Close()
+		}), nil
+	}
+
+	return nil, fmt.Errorf("buildpackage file has no layer with diff ID %s", style.Symbol(diffID))
+}
+
+func blobPath(digest v1.Hash) string {
+	return path.Join("blobs", digest.Algorithm, digest.Hex)
+}
+
+func jsonDescriptor(mediaType types.MediaType, contents []byte) (v1.Descriptor, error) {
+	digest, size, err := v1.SHA256(bytes.NewReader(contents))
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mediaType, Size: size, Digest: digest}, nil
+}
+
+func fileSHA256(path string) (v1.Hash, int64, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return v1.Hash{}, 0, err
+	}
+	defer fh.Close()
+
+	return v1.SHA256(fh)
+}
+
+func addLayerToTar(tw *tar.Writer, entryPath, layerPath string) error {
+	fh, err := os.Open(layerPath)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	fi, err := fh.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    entryPath,
+		Size:    fi.Size(),
+		Mode:    0644,
+		ModTime: archive.NormalizedDateTime,
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, fh)
+	return err
+}
+
+func readJSONEntry(blob dist.Blob, entryPath string, obj interface{}) error {
+	rc, err := blob.Open()
+	if err != nil {
+		return errors.Wrap(err, "opening buildpackage file")
+	}
+	defer rc.Close()
+
+	_, contents, err := archive.ReadTarEntry(rc, entryPath)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(contents, obj); err != nil {
+		return errors.Wrapf(err, "unmarshalling %s", style.Symbol(entryPath))
+	}
+	return nil
+}
+
+// openEntry returns the contents of the tar entry at entryPath without reading the whole entry into memory.
+func openEntry(blob dist.Blob, entryPath string) (io.ReadCloser, error) {
+	rc, err := blob.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "opening buildpackage file")
+	}
+
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rc.Close()
+			return nil, errors.Wrap(err, "reading buildpackage file")
+		}
+
+		if path.Clean(header.Name) == entryPath {
+			return ioutils.NewReadCloserWrapper(tr, rc.Close), nil
+		}
+	}
+
+	rc.Close()
+	return nil, errors.Wrapf(archive.ErrEntryNotExist, "could not find entry path '%s'", entryPath)
+}
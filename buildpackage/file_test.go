@@ -0,0 +1,258 @@
+package buildpackage_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/buildpackage"
+	"github.com/buildpack/pack/internal/archive"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestFile(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "File", testFile, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testFile(t *testing.T, when spec.G, it spec.S) {
+	var tmpDir string
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "buildpackage-file-test")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	when("#FileImage", func() {
+		when("#Save", func() {
+			var (
+				packagePath string
+				layers      [][]byte
+				subject     *buildpackage.FileImage
+			)
+
+			it.Before(func() {
+				packagePath = filepath.Join(tmpDir, "package.cnb")
+				layers = [][]byte{
+					layerTar(t, "first-file", "first-contents"),
+					layerTar(t, "second-file", "second-contents"),
+				}
+
+				subject = buildpackage.NewFileImage(packagePath)
+				h.AssertNil(t, subject.SetLabel(buildpackage.MetadataLabel, `{"id": "some.bp"}`))
+				h.AssertNil(t, subject.SetLabel("some-label", "some-value"))
+				for i, layer := range layers {
+					layerPath := filepath.Join(tmpDir, fmt.Sprintf("layer-%d.tar", i))
+					h.AssertNil(t, ioutil.WriteFile(layerPath, layer, 0644))
+					h.AssertNil(t, subject.AddLayer(layerPath))
+				}
+			})
+
+			it("writes a buildpackage file with the labels and layers of the image", func() {
+				digest, err := subject.Save()
+				h.AssertNil(t, err)
+				h.AssertContains(t, digest, "sha256:")
+
+				file, err := buildpackage.OpenFile(blob.NewBlob(packagePath))
+				h.AssertNil(t, err)
+
+				label, err := file.Label("some-label")
+				h.AssertNil(t, err)
+				h.AssertEq(t, label, "some-value")
+
+				for _, layer := range layers {
+					h.AssertEq(t, readLayer(t, file, diffID(t, layer).String()), layer)
+				}
+			})
+
+			it("replaces an existing file without leaving temporary files behind", func() {
+				h.AssertNil(t, ioutil.WriteFile(packagePath, []byte("some-old-contents"), 0644))
+
+				_, err := subject.Save()
+				h.AssertNil(t, err)
+
+				_, err = buildpackage.OpenFile(blob.NewBlob(packagePath))
+				h.AssertNil(t, err)
+
+				fi, err := os.Stat(packagePath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, fi.Mode().Perm()&0044, os.FileMode(0044))
+
+				matches, err := filepath.Glob(filepath.Join(tmpDir, "package.cnb.tmp-*"))
+				h.AssertNil(t, err)
+				h.AssertEq(t, len(matches), 0)
+			})
+		})
+	})
+
+	when("#OpenFile", func() {
+		var (
+			packagePath string
+			layer       []byte
+		)
+
+		it.Before(func() {
+			packagePath = filepath.Join(tmpDir, "package.cnb")
+			layer = layerTar(t, "some-file", "some-contents")
+		})
+
+		it("reads gzip-compressed layers", func() {
+			compressed := &bytes.Buffer{}
+			gzw := gzip.NewWriter(compressed)
+			_, err := gzw.Write(layer)
+			h.AssertNil(t, err)
+			h.AssertNil(t, gzw.Close())
+
+			writePackageFile(t, packagePath, 1, map[string]string{buildpackage.MetadataLabel: `{"id": "some.bp"}`}, packageLayer{
+				mediaType: types.OCILayer,
+				contents:  compressed.Bytes(),
+				diffID:    diffID(t, layer),
+			})
+
+			file, err := buildpackage.OpenFile(blob.NewBlob(packagePath))
+			h.AssertNil(t, err)
+			h.AssertEq(t, readLayer(t, file, diffID(t, layer).String()), layer)
+		})
+
+		it("fails when the file contains several images", func() {
+			writePackageFile(t, packagePath, 2, map[string]string{buildpackage.MetadataLabel: `{"id": "some.bp"}`}, packageLayer{
+				mediaType: types.OCIUncompressedLayer,
+				contents:  layer,
+				diffID:    diffID(t, layer),
+			})
+
+			_, err := buildpackage.OpenFile(blob.NewBlob(packagePath))
+			h.AssertError(t, err, "buildpackage file must contain exactly one image, found 2")
+		})
+
+		it("fails when the image is not a buildpackage", func() {
+			writePackageFile(t, packagePath, 1, map[string]string{"some-label": "some-value"})
+
+			_, err := buildpackage.OpenFile(blob.NewBlob(packagePath))
+			h.AssertError(t, err, "file is not a buildpackage: missing label 'io.buildpacks.buildpackage.metadata'")
+		})
+
+		when("#GetLayer", func() {
+			it("fails when the file has no layer with the diff ID", func() {
+				writePackageFile(t, packagePath, 1, map[string]string{buildpackage.MetadataLabel: `{"id": "some.bp"}`}, packageLayer{
+					mediaType: types.OCIUncompressedLayer,
+					contents:  layer,
+					diffID:    diffID(t, layer),
+				})
+
+				file, err := buildpackage.OpenFile(blob.NewBlob(packagePath))
+				h.AssertNil(t, err)
+
+				_, err = file.GetLayer("sha256:0000000000000000000000000000000000000000000000000000000000000000")
+				h.AssertError(t, err, "buildpackage file has no layer with diff ID")
+			})
+		})
+	})
+}
+
+type packageLayer struct {
+	mediaType types.MediaType
+	contents  []byte
+	diffID    v1.Hash
+}
+
+// writePackageFile writes a buildpackage file at path whose index lists its image the given number of times.
+func writePackageFile(t *testing.T, path string, manifests int, labels map[string]string, layers ...packageLayer) {
+	t.Helper()
+
+	entries := map[string][]byte{}
+	addBlob := func(mediaType types.MediaType, contents []byte) v1.Descriptor {
+		digest, size, err := v1.SHA256(bytes.NewReader(contents))
+		h.AssertNil(t, err)
+		entries[filepath.ToSlash(filepath.Join("blobs", digest.Algorithm, digest.Hex))] = contents
+		return v1.Descriptor{MediaType: mediaType, Size: size, Digest: digest}
+	}
+
+	config := v1.ConfigFile{
+		OS:     "linux",
+		RootFS: v1.RootFS{Type: "layers"},
+		Config: v1.Config{Labels: labels},
+	}
+	manifest := v1.Manifest{SchemaVersion: 2, MediaType: types.OCIManifestSchema1}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, addBlob(layer.mediaType, layer.contents))
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, layer.diffID)
+	}
+	manifest.Config = addBlob(types.OCIConfigJSON, marshal(t, config))
+	manifestDescriptor := addBlob(types.OCIManifestSchema1, marshal(t, manifest))
+
+	index := v1.IndexManifest{SchemaVersion: 2, MediaType: types.OCIImageIndex}
+	for i := 0; i < manifests; i++ {
+		index.Manifests = append(index.Manifests, manifestDescriptor)
+	}
+	entries["index.json"] = marshal(t, index)
+	entries["oci-layout"] = []byte(`{"imageLayoutVersion":"1.0.0"}`)
+
+	fh, err := os.Create(path)
+	h.AssertNil(t, err)
+	defer fh.Close()
+
+	tw := tar.NewWriter(fh)
+	for name, contents := range entries {
+		h.AssertNil(t, archive.AddFileToTar(tw, name, string(contents)))
+	}
+	h.AssertNil(t, tw.Close())
+}
+
+// layerTar returns an uncompressed layer containing a single file.
+func layerTar(t *testing.T, name, contents string) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	h.AssertNil(t, archive.AddFileToTar(tw, name, contents))
+	h.AssertNil(t, tw.Close())
+	return buf.Bytes()
+}
+
+func readLayer(t *testing.T, file *buildpackage.File, diffID string) []byte {
+	t.Helper()
+
+	rc, err := file.GetLayer(diffID)
+	h.AssertNil(t, err)
+	defer rc.Close()
+
+	contents, err := ioutil.ReadAll(rc)
+	h.AssertNil(t, err)
+	return contents
+}
+
+func diffID(t *testing.T, layer []byte) v1.Hash {
+	t.Helper()
+
+	hash, _, err := v1.SHA256(bytes.NewReader(layer))
+	h.AssertNil(t, err)
+	return hash
+}
+
+func marshal(t *testing.T, obj interface{}) []byte {
+	t.Helper()
+
+	contents, err := json.Marshal(obj)
+	h.AssertNil(t, err)
+	return contents
+}
@@ -1,6 +1,7 @@
 package pack
 
 import (
+	"net/http"
 	"os"
 	"path/filepath"
 
@@ -17,12 +18,16 @@ import (
 )
 
 type Client struct {
-	logger       logging.Logger
-	imageFetcher ImageFetcher
-	downloader   Downloader
-	lifecycle    Lifecycle
-	docker       *dockerClient.Client
-	imageFactory ImageFactory
+	logger          logging.Logger
+	imageFetcher    ImageFetcher
+	layerFetcher    LayerFetcher
+	platformFetcher PlatformFetcher
+	downloader      Downloader
+	lifecycle       Lifecycle
+	docker          *dockerClient.Client
+	imageFactory    ImageFactory
+	registry        image.RegistryConfig
+	maxCacheSize    int64
 }
 
 type ClientOption func(c *Client)
@@ -48,6 +53,20 @@ func WithFetcher(f ImageFetcher) ClientOption {
 	}
 }
 
+// WithLayerFetcher supply your own layer fetcher.
+func WithLayerFetcher(f LayerFetcher) ClientOption {
+	return func(c *Client) {
+		c.layerFetcher = f
+	}
+}
+
+// WithPlatformFetcher supply your own platform fetcher.
+func WithPlatformFetcher(f PlatformFetcher) ClientOption {
+	return func(c *Client) {
+		c.platformFetcher = f
+	}
+}
+
 // WithDownloader supply your own downloader.
 func WithDownloader(d Downloader) ClientOption {
 	return func(c *Client) {
@@ -64,6 +83,14 @@ func WithCacheDir(path string) ClientOption {
 	}
 }
 
+// WithDownloadCacheMaxSize prune the least recently used blobs from the default download cache when it grows larger
+// than size bytes.
+func WithDownloadCacheMaxSize(size int64) ClientOption {
+	return func(c *Client) {
+		c.maxCacheSize = size
+	}
+}
+
 // WithDockerClient supply your own docker client.
 func WithDockerClient(docker *dockerClient.Client) ClientOption {
 	return func(c *Client) {
@@ -71,6 +98,30 @@ func WithDockerClient(docker *dockerClient.Client) ClientOption {
 	}
 }
 
+// WithInsecureRegistries supply registries which may be reached without TLS verification. Only the registry calls
+// made by pack itself skip verification, the lifecycle still verifies the registries it reaches during a build.
+func WithInsecureRegistries(registries ...string) ClientOption {
+	return func(c *Client) {
+		c.registry.InsecureRegistries = registries
+	}
+}
+
+// WithCACerts supply a PEM bundle of additional CAs to trust when talking to registries.
+func WithCACerts(path string) ClientOption {
+	return func(c *Client) {
+		c.registry.CACertsPath = path
+	}
+}
+
+// Close releases the blobs the client downloaded, so that they may be pruned from the download cache. Blobs the
+// client still uses must not be read after it is closed.
+func (c *Client) Close() error {
+	if c.downloader == nil {
+		return nil
+	}
+	return c.downloader.Close()
+}
+
 func NewClient(opts ...ClientOption) (*Client, error) {
 	var client Client
 
@@ -90,22 +141,53 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		}
 	}
 
+	transport := http.DefaultTransport
+	if len(client.registry.InsecureRegistries) > 0 || client.registry.CACertsPath != "" {
+		if client.registry.CACertsPath != "" {
+			caCertsPath, err := filepath.Abs(client.registry.CACertsPath)
+			if err != nil {
+				return nil, errors.Wrap(err, "resolving ca-certs path")
+			}
+			client.registry.CACertsPath = caCertsPath
+		}
+
+		var err error
+		transport, err = image.NewTransport(client.registry)
+		if err != nil {
+			return nil, errors.Wrap(err, "configuring registry transport")
+		}
+	}
+
 	if client.downloader == nil {
-		packHome, err := config.PackHome()
+		cachePath, err := config.DownloadCachePath()
 		if err != nil {
-			return nil, errors.Wrap(err, "getting pack home")
+			return nil, err
 		}
-		client.downloader = blob.NewDownloader(client.logger, filepath.Join(packHome, "download-cache"))
+		client.downloader = blob.NewDownloader(
+			client.logger,
+			cachePath,
+			blob.WithMaxCacheSize(client.maxCacheSize),
+			blob.WithTransport(transport),
+		)
 	}
 
 	if client.imageFetcher == nil {
-		client.imageFetcher = image.NewFetcher(client.logger, client.docker)
+		client.imageFetcher = image.NewFetcher(client.logger, client.docker, image.WithTransport(transport))
+	}
+
+	if client.layerFetcher == nil {
+		client.layerFetcher = image.NewFetcher(client.logger, client.docker, image.WithTransport(transport))
+	}
+
+	if client.platformFetcher == nil {
+		client.platformFetcher = image.NewFetcher(client.logger, client.docker, image.WithTransport(transport))
 	}
 
 	if client.imageFactory == nil {
 		client.imageFactory = &DefaultImageFactory{
 			dockerClient: client.docker,
 			keychain:     authn.DefaultKeychain,
+			transport:    transport,
 		}
 	}
 
@@ -117,6 +199,7 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 type DefaultImageFactory struct {
 	dockerClient *dockerClient.Client
 	keychain     authn.Keychain
+	transport    http.RoundTripper
 }
 
 func (f *DefaultImageFactory) NewImage(repoName string, local bool) (imgutil.Image, error) {
@@ -124,5 +207,5 @@ func (f *DefaultImageFactory) NewImage(repoName string, local bool) (imgutil.Ima
 		return imgutil.EmptyLocalImage(repoName, f.dockerClient), nil
 	}
 
-	return imgutil.NewRemoteImage(repoName, f.keychain)
+	return image.NewRemoteImage(repoName, f.keychain, f.transport)
 }
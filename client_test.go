@@ -0,0 +1,72 @@
+package pack
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/blob"
+	ifakes "github.com/buildpack/pack/internal/fakes"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestClient(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Client", testClient, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testClient(t *testing.T, when spec.G, it spec.S) {
+	var (
+		cacheDir string
+		server   *httptest.Server
+		subject  *Client
+	)
+
+	it.Before(func() {
+		var err error
+		cacheDir, err = ioutil.TempDir("", "client-test")
+		h.AssertNil(t, err)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("some-contents"))
+		}))
+
+		subject, err = NewClient(WithLogger(ifakes.NewFakeLogger(ioutil.Discard)), WithCacheDir(cacheDir))
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		server.Close()
+		h.AssertNil(t, os.RemoveAll(cacheDir))
+	})
+
+	when("#Close", func() {
+		it("lets the blobs the client downloaded be pruned", func() {
+			_, err := subject.downloader.Download(context.TODO(), server.URL+"/some-blob")
+			h.AssertNil(t, err)
+
+			cache := blob.NewCache(cacheDir)
+			result, err := cache.Prune(blob.PruneOptions{MaxSize: 1})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Removed, 0)
+
+			h.AssertNil(t, subject.Close())
+
+			result, err = cache.Prune(blob.PruneOptions{MaxSize: 1})
+			h.AssertNil(t, err)
+			h.AssertEq(t, result.Removed, 1)
+		})
+
+		it("does nothing when nothing was downloaded", func() {
+			h.AssertNil(t, subject.Close())
+		})
+	})
+}
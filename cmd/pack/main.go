@@ -46,7 +46,7 @@ func main() {
 				}
 			}
 
-			packClient = initClient(logger)
+			packClient = initClient(logger, cfg)
 		},
 	}
 
@@ -61,18 +61,28 @@ func main() {
 	rootCmd.AddCommand(commands.Rebase(logger, cfg, &packClient))
 
 	rootCmd.AddCommand(commands.CreateBuilder(logger, &packClient))
+	rootCmd.AddCommand(commands.ModifyBuilder(logger, &packClient))
 	rootCmd.AddCommand(commands.CreatePackage(logger, &packClient))
 	rootCmd.AddCommand(commands.SetRunImagesMirrors(logger, cfg))
 	rootCmd.AddCommand(commands.InspectBuilder(logger, cfg, &packClient))
+	rootCmd.AddCommand(commands.InspectImage(logger, cfg, &packClient))
+	rootCmd.AddCommand(commands.InspectBuildpack(logger, &packClient))
+	rootCmd.AddCommand(commands.LintBuildpack(logger, &packClient))
+	rootCmd.AddCommand(commands.DiffBuilder(logger, &packClient))
 	rootCmd.AddCommand(commands.SetDefaultBuilder(logger, cfg, &packClient))
 	rootCmd.AddCommand(commands.SuggestBuilders(logger, &packClient))
+	rootCmd.AddCommand(commands.DownloadCache(logger, cfg, downloadCachePath(logger)))
 
 	rootCmd.AddCommand(commands.SuggestStacks(logger))
 	rootCmd.AddCommand(commands.Version(logger, cmd.Version))
 
 	rootCmd.AddCommand(commands.CompletionCommand(logger))
 
-	if err := rootCmd.Execute(); err != nil {
+	err = rootCmd.Execute()
+	if closeErr := packClient.Close(); closeErr != nil {
+		logger.Debugf("Releasing downloaded blobs: %s", closeErr)
+	}
+	if err != nil {
 		if commands.IsSoftError(err) {
 			os.Exit(2)
 		}
@@ -93,14 +103,34 @@ func initConfig() (config.Config, error) {
 	return cfg, nil
 }
 
-func initClient(logger logging.Logger) pack.Client {
-	client, err := pack.NewClient(pack.WithLogger(logger))
+func initClient(logger logging.Logger, cfg config.Config) pack.Client {
+	// an invalid limit shouldn't break commands that don't download anything, so the cache is left unlimited instead
+	maxCacheSize, err := config.DownloadCacheMaxSize(cfg)
+	if err != nil {
+		logger.Warnf("Ignoring download cache size limit: %s", err)
+		maxCacheSize = 0
+	}
+
+	client, err := pack.NewClient(
+		pack.WithLogger(logger),
+		pack.WithInsecureRegistries(cfg.InsecureRegistries...),
+		pack.WithCACerts(cfg.CACertsPath),
+		pack.WithDownloadCacheMaxSize(maxCacheSize),
+	)
 	if err != nil {
 		exitError(logger, err)
 	}
 	return *client
 }
 
+func downloadCachePath(logger logging.Logger) string {
+	path, err := config.DownloadCachePath()
+	if err != nil {
+		exitError(logger, err)
+	}
+	return path
+}
+
 func exitError(logger logging.Logger, err error) {
 	logger.Error(err.Error())
 	os.Exit(1)
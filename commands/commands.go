@@ -11,14 +11,22 @@ import (
 
 	"github.com/buildpack/pack"
 	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/dist"
 	"github.com/buildpack/pack/logging"
 )
 
 //go:generate mockgen -package mocks -destination mocks/pack_client.go github.com/buildpack/pack/commands PackClient
 type PackClient interface {
 	InspectBuilder(string, bool) (*pack.BuilderInfo, error)
-	Rebase(context.Context, pack.RebaseOptions) error
+	InspectImage(context.Context, string, bool) (*pack.ImageInfo, error)
+	InspectImageLayers(context.Context, string, bool) ([]pack.ImageLayer, error)
+	InspectBuildpack(context.Context, pack.InspectBuildpackOptions) (*pack.InspectedBuildpack, error)
+	LintBuildpack(context.Context, pack.LintBuildpackOptions) ([]dist.Finding, error)
+	DiffBuilders(pack.DiffBuildersOptions) (*pack.BuilderDiff, error)
+	RebaseWithReport(context.Context, pack.RebaseOptions) (*pack.RebaseReport, error)
+	RebaseMany(context.Context, pack.RebaseManyOptions) []pack.RebaseResult
 	CreateBuilder(context.Context, pack.CreateBuilderOptions) error
+	ModifyBuilder(context.Context, pack.ModifyBuilderOptions) error
 	CreatePackage(ctx context.Context, opts pack.CreatePackageOptions) error
 	Build(context.Context, pack.BuildOptions) error
 }
@@ -47,7 +55,7 @@ func multiValueHelp(name string) string {
 }
 
 func createCancellableContext() context.Context {
-	signals := make(chan os.Signal)
+	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 	ctx, cancel := context.WithCancel(context.Background())
 
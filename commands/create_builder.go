@@ -16,6 +16,7 @@ type CreateBuilderFlags struct {
 	BuilderTomlPath string
 	Publish         bool
 	NoPull          bool
+	Lint            bool
 }
 
 func CreateBuilder(logger logging.Logger, client PackClient) *cobra.Command {
@@ -40,6 +41,7 @@ func CreateBuilder(logger logging.Logger, client PackClient) *cobra.Command {
 				BuilderConfig: builderConfig,
 				Publish:       flags.Publish,
 				NoPull:        flags.NoPull,
+				Lint:          flags.Lint,
 			}); err != nil {
 				return err
 			}
@@ -52,6 +54,7 @@ func CreateBuilder(logger logging.Logger, client PackClient) *cobra.Command {
 	cmd.Flags().StringVarP(&flags.BuilderTomlPath, "builder-config", "b", "", "Path to builder TOML file (required)")
 	cmd.MarkFlagRequired("builder-config")
 	cmd.Flags().BoolVar(&flags.Publish, "publish", false, "Publish to registry")
+	cmd.Flags().BoolVar(&flags.Lint, "lint", false, "Fail when a buildpack has lint errors, see 'pack lint-buildpack'")
 	AddHelpFlag(cmd, "create-builder")
 	return cmd
 }
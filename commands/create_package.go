@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/BurntSushi/toml"
@@ -17,13 +18,16 @@ import (
 type CreatePackageFlags struct {
 	PackageTomlPath string
 	Publish         bool
+	Format          string
+	NoPull          bool
+	Lint            bool
 }
 
 func CreatePackage(logger logging.Logger, client PackClient) *cobra.Command {
 	var flags CreatePackageFlags
 	ctx := createCancellableContext()
 	cmd := &cobra.Command{
-		Use:   "create-package <image-name> --package-config <package-config-path>",
+		Use:   "create-package <image-name|file-path> --package-config <package-config-path>",
 		Args:  cobra.ExactArgs(1),
 		Short: "Create package",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
@@ -37,6 +41,9 @@ func CreatePackage(logger logging.Logger, client PackClient) *cobra.Command {
 				Name:    imageName,
 				Config:  config,
 				Publish: flags.Publish,
+				Format:  flags.Format,
+				NoPull:  flags.NoPull,
+				Lint:    flags.Lint,
 			}); err != nil {
 				return err
 			}
@@ -51,6 +58,9 @@ func CreatePackage(logger logging.Logger, client PackClient) *cobra.Command {
 	cmd.Flags().StringVarP(&flags.PackageTomlPath, "package-config", "p", "", "Path to package TOML config (required)")
 	cmd.MarkFlagRequired("package-config")
 	cmd.Flags().BoolVar(&flags.Publish, "publish", false, "Publish to registry")
+	cmd.Flags().BoolVar(&flags.NoPull, "no-pull", false, "Skip pulling buildpackage images declared as dependencies")
+	cmd.Flags().StringVar(&flags.Format, "format", pack.FormatImage, fmt.Sprintf("Format to write the package in, either '%s' or '%s' (%s file)", pack.FormatImage, pack.FormatFile, buildpackage.FileExtension))
+	cmd.Flags().BoolVar(&flags.Lint, "lint", false, "Fail when a buildpack has lint errors, see 'pack lint-buildpack'")
 	AddHelpFlag(cmd, "create-package")
 	return cmd
 }
@@ -78,5 +88,19 @@ func ReadPackageConfig(path string) (buildpackage.Config, error) {
 		config.Blobs[i].URI = absPath
 	}
 
+	for i := range config.Dependencies {
+		uri := config.Dependencies[i].URI
+		if uri == "" {
+			continue
+		}
+
+		absPath, err := paths.ToAbsolute(uri, configDir)
+		if err != nil {
+			return config, errors.Wrapf(err, "getting absolute path for %s", style.Symbol(uri))
+		}
+
+		config.Dependencies[i].URI = absPath
+	}
+
 	return config, nil
 }
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+func DiffBuilder(logger logging.Logger, client PackClient) *cobra.Command {
+	var (
+		outputFormat string
+		remote       bool
+	)
+	cmd := &cobra.Command{
+		Use:   "diff-builder <old-builder-image-name> <new-builder-image-name>",
+		Short: "Show the differences between two builders",
+		Args:  cobra.ExactArgs(2),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "" && outputFormat != "json" {
+				return fmt.Errorf("invalid output format %s, must be %s", style.Symbol(outputFormat), style.Symbol("json"))
+			}
+
+			diff, err := client.DiffBuilders(pack.DiffBuildersOptions{
+				OldBuilder: args[0],
+				NewBuilder: args[1],
+				Daemon:     !remote,
+			})
+			if err != nil {
+				return errors.Wrap(err, "comparing builders")
+			}
+
+			if outputFormat == "json" {
+				encode, err := encoderFor(outputFormat)
+				if err != nil {
+					return err
+				}
+				return encode(logger.Writer(), diff)
+			}
+
+			logger.Infof("Comparing builders: %s -> %s\n", style.Symbol(args[0]), style.Symbol(args[1]))
+			return writeBuilderDiff(logger.Writer(), *diff)
+		}),
+	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format: json (human readable text if omitted)")
+	cmd.Flags().BoolVar(&remote, "remote", false, "Look up the builders in the registry instead of the docker daemon")
+	AddHelpFlag(cmd, "diff-builder")
+	return cmd
+}
+
+func writeBuilderDiff(w io.Writer, diff pack.BuilderDiff) error {
+	if diff.IsEmpty() {
+		_, err := fmt.Fprintln(w, "\nNo differences")
+		return err
+	}
+
+	var sections []string
+
+	var bps []string
+	for _, bp := range diff.Buildpacks.Added {
+		bps = append(bps, "+ "+buildpackRef(bp))
+	}
+	for _, bp := range diff.Buildpacks.Removed {
+		bps = append(bps, "- "+buildpackRef(bp))
+	}
+	for _, change := range diff.Buildpacks.Upgraded {
+		bps = append(bps, fmt.Sprintf("~ %s: %s -> %s", change.ID, change.From, change.To))
+	}
+	for _, change := range diff.Buildpacks.Downgraded {
+		bps = append(bps, fmt.Sprintf("~ %s: %s -> %s (downgrade)", change.ID, change.From, change.To))
+	}
+	sections = appendSection(sections, "Buildpacks", bps)
+
+	var lifecycle []string
+	lifecycle = appendValueChange(lifecycle, "Version", diff.Lifecycle.Version)
+	lifecycle = appendValueChange(lifecycle, "Buildpack API", diff.Lifecycle.BuildpackAPI)
+	lifecycle = appendValueChange(lifecycle, "Platform API", diff.Lifecycle.PlatformAPI)
+	sections = appendSection(sections, "Lifecycle", lifecycle)
+
+	sections = appendSection(sections, "Stack", appendValueChange(nil, "ID", diff.Stack))
+
+	runImages := appendValueChange(nil, "Image", diff.RunImage)
+	for _, mirror := range diff.RunImageMirrors.Added {
+		runImages = append(runImages, "+ mirror "+mirror)
+	}
+	for _, mirror := range diff.RunImageMirrors.Removed {
+		runImages = append(runImages, "- mirror "+mirror)
+	}
+	sections = appendSection(sections, "Run Images", runImages)
+
+	var order []string
+	for _, change := range diff.Order {
+		order = append(order, fmt.Sprintf("Group #%d:", change.Index+1))
+		if change.From != nil {
+			order = append(order, "  - "+groupSummary(*change.From))
+		}
+		if change.To != nil {
+			order = append(order, "  + "+groupSummary(*change.To))
+		}
+	}
+	sections = appendSection(sections, "Detection Order", order)
+
+	var layers []string
+	for _, change := range diff.Layers {
+		layers = append(layers, fmt.Sprintf("%s: %s -> %s", buildpackRef(change.BuildpackInfo), change.From, change.To))
+	}
+	sections = appendSection(sections, "Buildpack Layers", layers)
+
+	_, err := fmt.Fprintf(w, "\n%s\n", strings.Join(sections, "\n\n"))
+	return err
+}
+
+func appendSection(sections []string, title string, lines []string) []string {
+	if len(lines) == 0 {
+		return sections
+	}
+	return append(sections, title+":\n  "+strings.Join(lines, "\n  "))
+}
+
+func appendValueChange(lines []string, name string, change *pack.ValueChange) []string {
+	if change == nil {
+		return lines
+	}
+	return append(lines, fmt.Sprintf("%s: %s -> %s", name, valueOrNone(change.From), valueOrNone(change.To)))
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func groupSummary(group dist.OrderEntry) string {
+	var refs []string
+	for _, bp := range group.Group {
+		ref := buildpackRef(bp.BuildpackInfo)
+		if bp.Optional {
+			ref += " (optional)"
+		}
+		refs = append(refs, ref)
+	}
+	return strings.Join(refs, ", ")
+}
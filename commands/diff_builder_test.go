@@ -0,0 +1,157 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/dist"
+	ilogging "github.com/buildpack/pack/internal/logging"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestDiffBuilderCommand(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Commands", testDiffBuilderCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testDiffBuilderCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *cmdmocks.MockPackClient
+		diff           *pack.BuilderDiff
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockClient = cmdmocks.NewMockPackClient(mockController)
+		logger = ilogging.NewLogWithWriters(&outBuf, &outBuf)
+
+		diff = &pack.BuilderDiff{
+			OldBuilder: "some/builder:old",
+			NewBuilder: "some/builder:new",
+			Buildpacks: pack.BuildpacksDiff{
+				Added:    []dist.BuildpackInfo{{ID: "bp.added", Version: "1.0.0"}},
+				Removed:  []dist.BuildpackInfo{{ID: "bp.removed", Version: "1.0.0"}},
+				Upgraded: []pack.VersionChange{{ID: "bp.upgraded", From: "1.0.0", To: "1.1.0"}},
+			},
+			Lifecycle: pack.LifecycleDiff{
+				Version: &pack.ValueChange{From: "0.4.0", To: "0.5.0"},
+			},
+			RunImage:        &pack.ValueChange{From: "some/run-image", To: "other/run-image"},
+			RunImageMirrors: pack.ListDiff{Added: []string{"new/mirror"}},
+			Order: []pack.OrderGroupChange{{
+				Index: 1,
+				From: &dist.OrderEntry{Group: []dist.BuildpackRef{
+					{BuildpackInfo: dist.BuildpackInfo{ID: "bp.removed", Version: "1.0.0"}},
+				}},
+				To: &dist.OrderEntry{Group: []dist.BuildpackRef{
+					{BuildpackInfo: dist.BuildpackInfo{ID: "bp.added", Version: "1.0.0"}, Optional: true},
+				}},
+			}},
+			Layers: []pack.BuildpackLayerChange{{
+				BuildpackInfo: dist.BuildpackInfo{ID: "bp.same", Version: "1.0.0"},
+				From:          "sha256:old",
+				To:            "sha256:new",
+			}},
+		}
+
+		command = commands.DiffBuilder(logger, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#DiffBuilder", func() {
+		it("displays the differences", func() {
+			mockClient.EXPECT().DiffBuilders(pack.DiffBuildersOptions{
+				OldBuilder: "some/builder:old",
+				NewBuilder: "some/builder:new",
+				Daemon:     true,
+			}).Return(diff, nil)
+
+			command.SetArgs([]string{"some/builder:old", "some/builder:new"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertEq(t, outBuf.String(), `Comparing builders: 'some/builder:old' -> 'some/builder:new'
+
+Buildpacks:
+  + bp.added@1.0.0
+  - bp.removed@1.0.0
+  ~ bp.upgraded: 1.0.0 -> 1.1.0
+
+Lifecycle:
+  Version: 0.4.0 -> 0.5.0
+
+Run Images:
+  Image: some/run-image -> other/run-image
+  + mirror new/mirror
+
+Detection Order:
+  Group #2:
+    - bp.removed@1.0.0
+    + bp.added@1.0.0 (optional)
+
+Buildpack Layers:
+  bp.same@1.0.0: sha256:old -> sha256:new
+`)
+		})
+
+		it("writes json when requested", func() {
+			mockClient.EXPECT().DiffBuilders(gomock.Any()).Return(&pack.BuilderDiff{
+				OldBuilder: "some/builder:old",
+				NewBuilder: "some/builder:new",
+				Stack:      &pack.ValueChange{From: "old.stack", To: "new.stack"},
+			}, nil)
+
+			command.SetArgs([]string{"some/builder:old", "some/builder:new", "--output", "json"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertEq(t, outBuf.String(), `{
+  "old_builder": "some/builder:old",
+  "new_builder": "some/builder:new",
+  "buildpacks": {},
+  "lifecycle": {},
+  "stack": {
+    "from": "old.stack",
+    "to": "new.stack"
+  },
+  "run_image_mirrors": {}
+}
+`)
+		})
+
+		it("looks up builders in the registry when --remote is provided", func() {
+			mockClient.EXPECT().DiffBuilders(pack.DiffBuildersOptions{
+				OldBuilder: "some/builder:old",
+				NewBuilder: "some/builder:new",
+				Daemon:     false,
+			}).Return(&pack.BuilderDiff{}, nil)
+
+			command.SetArgs([]string{"some/builder:old", "some/builder:new", "--remote"})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "No differences")
+		})
+
+		when("the output format is invalid", func() {
+			it("returns an error", func() {
+				command.SetArgs([]string{"some/builder:old", "some/builder:new", "--output", "xml"})
+				h.AssertError(t, command.Execute(), "invalid output format 'xml', must be 'json'")
+			})
+		})
+	})
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+)
+
+func DownloadCache(logger logging.Logger, cfg config.Config, cachePath string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download-cache",
+		Short: "Manage the cache of downloaded buildpacks and lifecycles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cache := blob.NewCache(cachePath)
+	cmd.AddCommand(downloadCacheList(logger, cache))
+	cmd.AddCommand(downloadCachePrune(logger, cfg, cache))
+	cmd.AddCommand(downloadCacheClear(logger, cache))
+	AddHelpFlag(cmd, "download-cache")
+	return cmd
+}
+
+func downloadCacheList(logger logging.Logger, cache *blob.Cache) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the downloads in the cache, most recently used first",
+		Args:  cobra.NoArgs,
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			entries, err := cache.List()
+			if err != nil {
+				return err
+			}
+
+			if len(entries) == 0 {
+				logger.Info("The download cache is empty")
+				return nil
+			}
+
+			output, err := downloadCacheOutput(entries)
+			if err != nil {
+				return err
+			}
+			logger.Info(output)
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "list")
+	return cmd
+}
+
+func downloadCachePrune(logger logging.Logger, cfg config.Config, cache *blob.Cache) *cobra.Command {
+	var (
+		maxSize   string
+		olderThan time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the least recently used downloads from the cache",
+		Long: "Remove the least recently used downloads from the cache.\n\n" +
+			"Without flags, the cache is pruned to the download-cache-max-size of the pack config. Downloads that " +
+			"running pack commands are using are kept. Downloads cached by older versions of pack are removed.",
+		Args: cobra.NoArgs,
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			opts := blob.PruneOptions{OlderThan: olderThan}
+			if maxSize != "" {
+				size, err := units.FromHumanSize(maxSize)
+				if err != nil {
+					return errors.Wrapf(err, "invalid max size %s", maxSize)
+				}
+				opts.MaxSize = size
+			} else if olderThan == 0 {
+				size, err := config.DownloadCacheMaxSize(cfg)
+				if err != nil {
+					return err
+				}
+				opts.MaxSize = size
+			}
+
+			if opts.MaxSize <= 0 && opts.OlderThan <= 0 {
+				return errors.New("--max-size or --older-than must be provided when download-cache-max-size is not configured")
+			}
+
+			result, err := cache.Prune(opts)
+			if err != nil {
+				return err
+			}
+			logger.Infof("Removed %d download(s), freeing %s", result.Removed, units.HumanSize(float64(result.Freed)))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&maxSize, "max-size", "", "Remove the least recently used downloads until the cache is no larger than this size (e.g. 2GB)")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Remove downloads that have not been used for longer than this duration (e.g. 720h)")
+	AddHelpFlag(cmd, "prune")
+	return cmd
+}
+
+func downloadCacheClear(logger logging.Logger, cache *blob.Cache) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all downloads from the cache",
+		Long:  "Remove all downloads from the cache, except for those that running pack commands are using.",
+		Args:  cobra.NoArgs,
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := cache.Clear(); err != nil {
+				return err
+			}
+			logger.Info("Download cache cleared")
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "clear")
+	return cmd
+}
+
+func downloadCacheOutput(entries []blob.CacheEntry) (string, error) {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 4, ' ', 0)
+	if _, err := fmt.Fprint(tabWriter, "URI\tDIGEST\tSIZE\tLAST USED\n"); err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(
+			tabWriter,
+			"%s\t%s\t%s\t%s\n",
+			entry.URI,
+			"sha256:"+entry.Digest[:12],
+			units.HumanSize(float64(entry.Size)),
+			units.HumanDuration(time.Since(entry.LastUsed))+" ago",
+		); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tabWriter.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
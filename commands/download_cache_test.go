@@ -0,0 +1,84 @@
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/commands"
+	"github.com/buildpack/pack/config"
+	ilogging "github.com/buildpack/pack/internal/logging"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestDownloadCacheCommand(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Commands", testDownloadCacheCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testDownloadCacheCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		logger   logging.Logger
+		outBuf   bytes.Buffer
+		cacheDir string
+	)
+
+	run := func(cfg config.Config, args ...string) error {
+		command := commands.DownloadCache(logger, cfg, cacheDir)
+		command.SetArgs(args)
+		return command.Execute()
+	}
+
+	it.Before(func() {
+		var err error
+		cacheDir, err = ioutil.TempDir("", "download-cache")
+		h.AssertNil(t, err)
+		logger = ilogging.NewLogWithWriters(&outBuf, &outBuf)
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(cacheDir))
+	})
+
+	when("list", func() {
+		it("reports an empty cache", func() {
+			h.AssertNil(t, run(config.Config{}, "list"))
+			h.AssertContains(t, outBuf.String(), "The download cache is empty")
+		})
+	})
+
+	when("prune", func() {
+		it("fails without a policy", func() {
+			h.AssertError(t, run(config.Config{}, "prune"), "--max-size or --older-than must be provided")
+		})
+
+		it("uses the configured max size", func() {
+			h.AssertNil(t, run(config.Config{DownloadCacheMaxSize: "1GB"}, "prune"))
+			h.AssertContains(t, outBuf.String(), "Removed 0 download(s), freeing 0B")
+		})
+
+		it("fails for an invalid max size", func() {
+			h.AssertError(t, run(config.Config{}, "prune", "--max-size", "lots"), "invalid max size lots")
+		})
+	})
+
+	when("clear", func() {
+		it("removes everything from the cache", func() {
+			h.AssertNil(t, os.MkdirAll(filepath.Join(cacheDir, "c2"), 0755))
+
+			h.AssertNil(t, run(config.Config{}, "clear"))
+			h.AssertContains(t, outBuf.String(), "Download cache cleared")
+
+			_, err := os.Stat(filepath.Join(cacheDir, "c2"))
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
+	})
+}
@@ -3,10 +3,11 @@ package commands
 import (
 	"bytes"
 	"fmt"
-	"html/template"
 	"io"
 	"strings"
 	"text/tabwriter"
+	"text/template"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -21,6 +22,10 @@ import (
 )
 
 func InspectBuilder(logger logging.Logger, cfg config.Config, client PackClient) *cobra.Command {
+	var (
+		outputFormat string
+		depth        int
+	)
 	cmd := &cobra.Command{
 		Use:   "inspect-builder <builder-image-name>",
 		Short: "Show information about a builder",
@@ -36,13 +41,17 @@ func InspectBuilder(logger logging.Logger, cfg config.Config, client PackClient)
 				imageName = args[0]
 			}
 
+			if outputFormat != "" {
+				return writeStructuredBuilderOutput(logger.Writer(), client, cfg, imageName, outputFormat, depth)
+			}
+
 			if imageName == cfg.DefaultBuilder {
 				logger.Infof("Inspecting default builder: %s\n", style.Symbol(imageName))
 			} else {
 				logger.Infof("Inspecting builder: %s\n", style.Symbol(imageName))
 			}
 
-			remoteOutput, warnings, err := inspectBuilderOutput(client, cfg, imageName, false)
+			remoteOutput, warnings, err := inspectBuilderOutput(client, cfg, imageName, false, depth)
 			if err != nil {
 				logger.Error(err.Error())
 			} else {
@@ -52,7 +61,7 @@ func InspectBuilder(logger logging.Logger, cfg config.Config, client PackClient)
 				}
 			}
 
-			localOutput, warnings, err := inspectBuilderOutput(client, cfg, imageName, true)
+			localOutput, warnings, err := inspectBuilderOutput(client, cfg, imageName, true, depth)
 			if err != nil {
 				logger.Error(err.Error())
 			} else {
@@ -65,11 +74,13 @@ func InspectBuilder(logger logging.Logger, cfg config.Config, client PackClient)
 			return nil
 		}),
 	}
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format: one of json, yaml or toml (human readable text if omitted)")
+	cmd.Flags().IntVarP(&depth, "depth", "d", -1, "Max depth of nested buildpacks to expand in the detection order (all levels if negative)")
 	AddHelpFlag(cmd, "inspect-builder")
 	return cmd
 }
 
-func inspectBuilderOutput(client PackClient, cfg config.Config, imageName string, local bool) (output string, warning []string, err error) {
+func inspectBuilderOutput(client PackClient, cfg config.Config, imageName string, local bool, depth int) (output string, warning []string, err error) {
 	source := "remote"
 	if local {
 		source = "local"
@@ -85,7 +96,7 @@ func inspectBuilderOutput(client PackClient, cfg config.Config, imageName string
 	}
 
 	var buf bytes.Buffer
-	warnings, err := generateOutput(&buf, imageName, cfg, *info)
+	warnings, err := generateOutput(&buf, imageName, cfg, *info, depth)
 	if err != nil {
 		return "", nil, errors.Wrapf(err, "writing output for %s image '%s'", source, imageName)
 	}
@@ -93,7 +104,7 @@ func inspectBuilderOutput(client PackClient, cfg config.Config, imageName string
 	return buf.String(), warnings, nil
 }
 
-func generateOutput(writer io.Writer, imageName string, cfg config.Config, info pack.BuilderInfo) (warnings []string, err error) {
+func generateOutput(writer io.Writer, imageName string, cfg config.Config, info pack.BuilderInfo, depth int) (warnings []string, err error) {
 	tpl := template.Must(template.New("").Parse(`
 {{ if ne .Info.Description "" -}}
 Description: {{ .Info.Description }}
@@ -114,6 +125,15 @@ Lifecycle:
   Buildpack API: {{ .Info.Lifecycle.API.BuildpackVersion }}
   Platform API: {{ .Info.Lifecycle.API.PlatformVersion }}
 
+Compat Paths:
+{{- if .Info.CompatPaths }}
+{{- range .Info.CompatPaths }}
+  {{ . }}
+{{- end }}
+{{- else }}
+  (none)
+{{- end }}
+
 Run Images:
 {{- if ne .RunImages "" }}
 {{ .RunImages }}
@@ -131,9 +151,19 @@ Buildpacks:
 Detection Order:
 {{- if ne .Order "" }}
 {{ .Order }}
+{{- if ne .FlattenedOrder "" }}
+
+Flattened Detection Order:
+{{ .FlattenedOrder }}
+{{- end }}
 {{- else }}
   (none)
-{{ end }}`,
+{{ end }}
+{{- if ne .Provenance "" }}
+
+Provenance:
+{{ .Provenance }}
+{{- end }}`,
 	))
 
 	bps, err := buildpacksOutput(info.Buildpacks)
@@ -141,32 +171,67 @@ Detection Order:
 		return nil, err
 	}
 
-	if len(info.Buildpacks) == 0 {
-		warnings = append(warnings, fmt.Sprintf("%s has no buildpacks", style.Symbol(imageName)))
-		warnings = append(warnings, "Users must supply buildpacks from the host machine")
+	order, err := detectionOrderOutput(info.Order, info.BuildpackLayers, depth)
+	if err != nil {
+		return nil, err
 	}
 
-	order, err := detectionOrderOutput(info.Order)
+	runImgs, err := runImagesOutput(info.RunImage, info.RunImageMirrors, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(info.Order) == 0 {
-		warnings = append(warnings, fmt.Sprintf("%s does not specify detection order", style.Symbol(imageName)))
-		warnings = append(warnings, "Users must build with explicitly specified buildpacks")
+	var flattened string
+	if flatOrder, ok := flattenedOrder(info); ok {
+		flattened = flattenedOrderOutput(flatOrder)
 	}
 
-	runImgs, err := runImagesOutput(info.RunImage, info.RunImageMirrors, cfg)
-	if err != nil {
-		return nil, err
+	warnings = builderWarnings(style.Symbol(imageName), info)
+	applyAssumedLifecycle(&info.Lifecycle)
+
+	return warnings, tpl.Execute(writer, &struct {
+		Info           pack.BuilderInfo
+		Buildpacks     string
+		RunImages      string
+		Order          string
+		FlattenedOrder string
+		Provenance     string
+	}{
+		info,
+		bps,
+		runImgs,
+		order,
+		flattened,
+		provenanceOutput(info.Provenance),
+	})
+}
+
+func builderWarnings(imageRef string, info pack.BuilderInfo) []string {
+	var warnings []string
+
+	if len(info.Buildpacks) == 0 {
+		warnings = append(warnings, fmt.Sprintf("%s has no buildpacks", imageRef))
+		warnings = append(warnings, "Users must supply buildpacks from the host machine")
+	}
+
+	if len(info.Order) == 0 {
+		warnings = append(warnings, fmt.Sprintf("%s does not specify detection order", imageRef))
+		warnings = append(warnings, "Users must build with explicitly specified buildpacks")
 	}
 
 	if info.RunImage == "" {
-		warnings = append(warnings, fmt.Sprintf("%s does not specify a run image", style.Symbol(imageName)))
+		warnings = append(warnings, fmt.Sprintf("%s does not specify a run image", imageRef))
 		warnings = append(warnings, "Users must build with an explicitly specified run image")
 	}
 
-	lcDescriptor := &info.Lifecycle
+	if err := builder.ValidateBuilderOrder(info.Order, info.Buildpacks, info.BuildpackLayers); err != nil {
+		warnings = append(warnings, fmt.Sprintf("%s has an invalid detection order: %s", imageRef, err))
+	}
+
+	return warnings
+}
+
+func applyAssumedLifecycle(lcDescriptor *builder.LifecycleDescriptor) {
 	if lcDescriptor.Info.Version == nil {
 		lcDescriptor.Info.Version = builder.VersionMustParse(builder.AssumedLifecycleVersion)
 	}
@@ -178,21 +243,8 @@ Detection Order:
 	if lcDescriptor.API.PlatformVersion == nil {
 		lcDescriptor.API.PlatformVersion = api.MustParse(builder.AssumedPlatformAPIVersion)
 	}
-
-	return warnings, tpl.Execute(writer, &struct {
-		Info       pack.BuilderInfo
-		Buildpacks string
-		RunImages  string
-		Order      string
-	}{
-		info,
-		bps,
-		runImgs,
-		order,
-	})
 }
 
-// TODO: present buildpack order (inc. nested) [https://github.com/buildpack/pack/issues/253].
 func buildpacksOutput(bps []builder.BuildpackMetadata) (string, error) {
 	buf := &bytes.Buffer{}
 	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
@@ -242,33 +294,169 @@ func runImagesOutput(runImage string, mirrors []string, cfg config.Config) (stri
 	return strings.TrimSuffix(buf.String(), "\n"), nil
 }
 
-func detectionOrderOutput(order dist.Order) (string, error) {
+type orderLine struct {
+	text     string
+	optional bool
+}
+
+func detectionOrderOutput(order dist.Order, layers builder.BuildpackLayers, maxDepth int) (string, error) {
+	lines := orderTreeLines(order, layers, "  ", 0, maxDepth, map[string]bool{})
+
+	width := 0
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line.text); line.optional && n > width {
+			width = n
+		}
+	}
+
 	buf := strings.Builder{}
+	for _, line := range lines {
+		buf.WriteString(line.text)
+		if line.optional {
+			buf.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(line.text)+4) + "(optional)")
+		}
+		buf.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// orderTreeLines renders each group of order, expanding order buildpacks (found in the buildpack layers label)
+// until maxDepth is reached. A negative maxDepth expands the whole tree.
+func orderTreeLines(order dist.Order, layers builder.BuildpackLayers, prefix string, depth, maxDepth int, visited map[string]bool) []orderLine {
+	var lines []orderLine
 	for i, group := range order {
-		buf.WriteString(fmt.Sprintf("  Group #%d:\n", i+1))
+		groupBranch, groupIndent := treeBranch(i == len(order)-1)
+		lines = append(lines, orderLine{text: fmt.Sprintf("%s%sGroup #%d:", prefix, groupBranch, i+1)})
 
-		tabWriter := new(tabwriter.Writer).Init(&buf, 0, 0, 4, ' ', 0)
-		for _, bp := range group.Group {
-			var optional string
-			if bp.Optional {
-				optional = "(optional)"
-			}
+		for j, bp := range group.Group {
+			branch, indent := treeBranch(j == len(group.Group)-1)
 
 			bpRef := bp.ID
 			if bp.Version != "" {
 				bpRef += "@" + bp.Version
 			}
+			lines = append(lines, orderLine{text: prefix + groupIndent + branch + bpRef, optional: bp.Optional})
 
-			if _, err := fmt.Fprintf(tabWriter, "    %s\t%s\n", bpRef, optional); err != nil {
-				return "", err
+			nestedOrder, key := nestedOrderFor(bp, layers)
+			if len(nestedOrder) == 0 || visited[key] || (maxDepth >= 0 && depth >= maxDepth) {
+				continue
 			}
+
+			visited[key] = true
+			lines = append(lines, orderTreeLines(nestedOrder, layers, prefix+groupIndent+indent, depth+1, maxDepth, visited)...)
+			delete(visited, key)
 		}
-		if err := tabWriter.Flush(); err != nil {
-			return "", err
+	}
+
+	return lines
+}
+
+func treeBranch(last bool) (branch, indent string) {
+	if last {
+		return "└ ", "   "
+	}
+	return "├ ", "│  "
+}
+
+// nestedOrderFor returns the order of an order buildpack along with its resolved `id@version` key. References are
+// resolved as in the builder order: without a version only if a single version exists on the builder, or to the
+// highest version matching a version range.
+func nestedOrderFor(bp dist.BuildpackRef, layers builder.BuildpackLayers) (dist.Order, string) {
+	var available []string
+	for v := range layers[bp.ID] {
+		available = append(available, v)
+	}
+
+	version, err := builder.ResolveVersion(bp.ID, bp.Version, available)
+	if err != nil {
+		return nil, ""
+	}
+	return layers[bp.ID][version].Order, bp.ID + "@" + version
+}
+
+// flattenedOrder returns the flattened order of a builder whose order contains order buildpacks. The order is nil
+// when it is too large to be flattened.
+func flattenedOrder(info pack.BuilderInfo) (dist.Order, bool) {
+	hasOrderBuildpacks := false
+	for _, group := range info.Order {
+		for _, bp := range group.Group {
+			if nestedOrder, _ := nestedOrderFor(bp, info.BuildpackLayers); len(nestedOrder) > 0 {
+				hasOrderBuildpacks = true
+			}
 		}
 	}
+	if !hasOrderBuildpacks {
+		return nil, false
+	}
 
-	return strings.TrimSuffix(buf.String(), "\n"), nil
+	flattened, err := builder.FlattenBuilderOrder(info.Order, info.Buildpacks, info.BuildpackLayers)
+	if err == builder.ErrTooManyGroups {
+		return nil, true
+	}
+	if err != nil {
+		return nil, false
+	}
+	return flattened, true
+}
+
+func flattenedOrderOutput(order dist.Order) string {
+	if order == nil {
+		return fmt.Sprintf("  (more than %d groups)", builder.MaxFlattenedGroups)
+	}
+
+	var lines []string
+	for i, group := range order {
+		lines = append(lines, fmt.Sprintf("  Group #%d: %s", i+1, groupSummary(group)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func provenanceOutput(provenance *builder.Provenance) string {
+	if provenance == nil {
+		return ""
+	}
+
+	lines := []string{
+		"  Build Image:",
+		"    Name: " + provenance.BuildImage.Name,
+	}
+	if provenance.BuildImage.Digest != "" {
+		lines = append(lines, "    Digest: "+provenance.BuildImage.Digest)
+	}
+
+	lines = append(lines,
+		"  Lifecycle:",
+		"    URI: "+provenance.Lifecycle.URI,
+		"    Digest: "+provenance.Lifecycle.Digest,
+	)
+	if provenance.Lifecycle.ETag != "" {
+		lines = append(lines, "    ETag: "+provenance.Lifecycle.ETag)
+	}
+
+	lines = append(lines, "  Buildpacks:")
+	if len(provenance.Buildpacks) == 0 {
+		lines = append(lines, "    (none)")
+	}
+	for _, bp := range provenance.Buildpacks {
+		lines = append(lines,
+			fmt.Sprintf("    %s@%s", bp.ID, bp.Version),
+			"      URI: "+bp.URI,
+			"      Digest: "+bp.Digest,
+		)
+		if bp.ETag != "" {
+			lines = append(lines, "      ETag: "+bp.ETag)
+		}
+		lines = append(lines, "      Layer Digest: "+bp.LayerDigest)
+	}
+
+	lines = append(lines,
+		"  Parameters:",
+		"    Builder Name: "+provenance.Parameters.BuilderName,
+		fmt.Sprintf("    Publish: %t", provenance.Parameters.Publish),
+		fmt.Sprintf("    No Pull: %t", provenance.Parameters.NoPull),
+	)
+	return strings.Join(lines, "\n")
 }
 
 func getLocalMirrors(runImage string, cfg config.Config) []string {
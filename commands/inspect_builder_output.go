@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/style"
+)
+
+// BuilderOutput is the document written by `inspect-builder --output json|yaml|toml`.
+//
+// Field names are part of pack's public interface: fields may be added, but existing ones are
+// neither renamed nor removed. A section is omitted (or null in JSON/YAML) when the builder
+// is not present in that location.
+type BuilderOutput struct {
+	BuilderName string             `json:"builder_name" yaml:"builder_name" toml:"builder_name"`
+	Default     bool               `json:"default" yaml:"default" toml:"default"`
+	RemoteInfo  *BuilderInfoOutput `json:"remote_info" yaml:"remote_info" toml:"remote_info,omitempty"`
+	LocalInfo   *BuilderInfoOutput `json:"local_info" yaml:"local_info" toml:"local_info,omitempty"`
+}
+
+type BuilderInfoOutput struct {
+	Description    string            `json:"description" yaml:"description" toml:"description"`
+	CreatedBy      CreatedByOutput   `json:"created_by" yaml:"created_by" toml:"created_by"`
+	Stack          StackOutput       `json:"stack" yaml:"stack" toml:"stack"`
+	Lifecycle      LifecycleOutput   `json:"lifecycle" yaml:"lifecycle" toml:"lifecycle"`
+	CompatPaths    []string          `json:"compat_paths" yaml:"compat_paths" toml:"compat_paths"`
+	RunImages      []RunImageOutput  `json:"run_images" yaml:"run_images" toml:"run_images"`
+	Buildpacks     []BuildpackOutput `json:"buildpacks" yaml:"buildpacks" toml:"buildpacks"`
+	DetectionOrder []GroupOutput     `json:"detection_order" yaml:"detection_order" toml:"detection_order"`
+	// FlattenedDetectionOrder is the detection order with all order buildpacks expanded. It is only present when
+	// the detection order contains order buildpacks.
+	FlattenedDetectionOrder []GroupOutput `json:"flattened_detection_order,omitempty" yaml:"flattened_detection_order,omitempty" toml:"flattened_detection_order,omitempty"`
+	// Provenance records where the contents of the builder came from. It is only present for builders that
+	// recorded it.
+	Provenance *ProvenanceOutput `json:"provenance,omitempty" yaml:"provenance,omitempty" toml:"provenance,omitempty"`
+	Warnings   []string          `json:"warnings" yaml:"warnings" toml:"warnings"`
+}
+
+type CreatedByOutput struct {
+	Name    string `json:"name" yaml:"name" toml:"name"`
+	Version string `json:"version" yaml:"version" toml:"version"`
+}
+
+type StackOutput struct {
+	ID string `json:"id" yaml:"id" toml:"id"`
+}
+
+type LifecycleOutput struct {
+	Version      string `json:"version" yaml:"version" toml:"version"`
+	BuildpackAPI string `json:"buildpack_api" yaml:"buildpack_api" toml:"buildpack_api"`
+	PlatformAPI  string `json:"platform_api" yaml:"platform_api" toml:"platform_api"`
+}
+
+type RunImageOutput struct {
+	Name           string `json:"name" yaml:"name" toml:"name"`
+	UserConfigured bool   `json:"user_configured" yaml:"user_configured" toml:"user_configured"`
+}
+
+type BuildpackOutput struct {
+	ID      string `json:"id" yaml:"id" toml:"id"`
+	Version string `json:"version" yaml:"version" toml:"version"`
+	Latest  bool   `json:"latest" yaml:"latest" toml:"latest"`
+}
+
+type GroupOutput struct {
+	Group []BuildpackRefOutput `json:"group" yaml:"group" toml:"group"`
+}
+
+type ProvenanceOutput struct {
+	BuildImage ProvenanceImageOutput       `json:"build_image" yaml:"build_image" toml:"build_image"`
+	Lifecycle  ProvenanceSourceOutput      `json:"lifecycle" yaml:"lifecycle" toml:"lifecycle"`
+	Buildpacks []ProvenanceBuildpackOutput `json:"buildpacks" yaml:"buildpacks" toml:"buildpacks"`
+	Parameters ProvenanceParametersOutput  `json:"parameters" yaml:"parameters" toml:"parameters"`
+}
+
+type ProvenanceImageOutput struct {
+	Name   string `json:"name" yaml:"name" toml:"name"`
+	Digest string `json:"digest" yaml:"digest" toml:"digest"`
+}
+
+type ProvenanceSourceOutput struct {
+	URI    string `json:"uri" yaml:"uri" toml:"uri"`
+	Digest string `json:"digest" yaml:"digest" toml:"digest"`
+	ETag   string `json:"etag,omitempty" yaml:"etag,omitempty" toml:"etag,omitempty"`
+}
+
+type ProvenanceBuildpackOutput struct {
+	ID          string `json:"id" yaml:"id" toml:"id"`
+	Version     string `json:"version" yaml:"version" toml:"version"`
+	URI         string `json:"uri" yaml:"uri" toml:"uri"`
+	Digest      string `json:"digest" yaml:"digest" toml:"digest"`
+	ETag        string `json:"etag,omitempty" yaml:"etag,omitempty" toml:"etag,omitempty"`
+	LayerDigest string `json:"layer_digest" yaml:"layer_digest" toml:"layer_digest"`
+}
+
+type ProvenanceParametersOutput struct {
+	BuilderName string `json:"builder_name" yaml:"builder_name" toml:"builder_name"`
+	Publish     bool   `json:"publish" yaml:"publish" toml:"publish"`
+	NoPull      bool   `json:"no_pull" yaml:"no_pull" toml:"no_pull"`
+}
+
+type BuildpackRefOutput struct {
+	ID       string `json:"id" yaml:"id" toml:"id"`
+	Version  string `json:"version" yaml:"version" toml:"version"`
+	Optional bool   `json:"optional" yaml:"optional" toml:"optional"`
+	// Order is the expanded order of an order buildpack, up to the requested depth.
+	Order []GroupOutput `json:"order,omitempty" yaml:"order,omitempty" toml:"order,omitempty"`
+}
+
+func writeStructuredBuilderOutput(writer io.Writer, client PackClient, cfg config.Config, imageName, format string, depth int) error {
+	encode, err := encoderFor(format)
+	if err != nil {
+		return err
+	}
+
+	output := BuilderOutput{
+		BuilderName: imageName,
+		Default:     imageName == cfg.DefaultBuilder,
+	}
+
+	if output.RemoteInfo, err = structuredBuilderInfo(client, cfg, imageName, false, depth); err != nil {
+		return err
+	}
+
+	if output.LocalInfo, err = structuredBuilderInfo(client, cfg, imageName, true, depth); err != nil {
+		return err
+	}
+
+	return encode(writer, output)
+}
+
+func encoderFor(format string) (func(io.Writer, interface{}) error, error) {
+	switch format {
+	case "json":
+		return func(w io.Writer, v interface{}) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(v)
+		}, nil
+	case "yaml":
+		return func(w io.Writer, v interface{}) error {
+			return yaml.NewEncoder(w).Encode(v)
+		}, nil
+	case "toml":
+		return func(w io.Writer, v interface{}) error {
+			return toml.NewEncoder(w).Encode(v)
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid output format %s, must be one of %s, %s or %s",
+			style.Symbol(format), style.Symbol("json"), style.Symbol("yaml"), style.Symbol("toml"))
+	}
+}
+
+func structuredBuilderInfo(client PackClient, cfg config.Config, imageName string, local bool, depth int) (*BuilderInfoOutput, error) {
+	source := "remote"
+	if local {
+		source = "local"
+	}
+
+	info, err := client.InspectBuilder(imageName, local)
+	if err != nil {
+		return nil, errors.Wrapf(err, "inspecting %s image '%s'", source, imageName)
+	}
+
+	if info == nil {
+		return nil, nil
+	}
+
+	return builderInfoOutput(imageName, cfg, *info, depth), nil
+}
+
+func builderInfoOutput(imageName string, cfg config.Config, info pack.BuilderInfo, depth int) *BuilderInfoOutput {
+	warnings := builderWarnings(imageName, info)
+	applyAssumedLifecycle(&info.Lifecycle)
+
+	output := &BuilderInfoOutput{
+		Description: info.Description,
+		CreatedBy: CreatedByOutput{
+			Name:    info.CreatedBy.Name,
+			Version: info.CreatedBy.Version,
+		},
+		Stack: StackOutput{ID: info.Stack},
+		Lifecycle: LifecycleOutput{
+			Version:      info.Lifecycle.Info.Version.String(),
+			BuildpackAPI: info.Lifecycle.API.BuildpackVersion.String(),
+			PlatformAPI:  info.Lifecycle.API.PlatformVersion.String(),
+		},
+		CompatPaths:    append([]string{}, info.CompatPaths...),
+		RunImages:      []RunImageOutput{},
+		Buildpacks:     []BuildpackOutput{},
+		DetectionOrder: orderOutput(info.Order, info.BuildpackLayers, 0, depth, map[string]bool{}),
+		Warnings:       []string{},
+	}
+
+	for _, r := range getLocalMirrors(info.RunImage, cfg) {
+		output.RunImages = append(output.RunImages, RunImageOutput{Name: r, UserConfigured: true})
+	}
+	if info.RunImage != "" {
+		output.RunImages = append(output.RunImages, RunImageOutput{Name: info.RunImage})
+	}
+	for _, r := range info.RunImageMirrors {
+		output.RunImages = append(output.RunImages, RunImageOutput{Name: r})
+	}
+
+	for _, bp := range info.Buildpacks {
+		output.Buildpacks = append(output.Buildpacks, BuildpackOutput{ID: bp.ID, Version: bp.Version, Latest: bp.Latest})
+	}
+
+	if flattened, ok := flattenedOrder(info); ok {
+		output.FlattenedDetectionOrder = orderOutput(flattened, nil, 0, 0, map[string]bool{})
+	}
+
+	if info.Provenance != nil {
+		output.Provenance = provenanceOutputFor(*info.Provenance)
+	}
+
+	output.Warnings = append(output.Warnings, warnings...)
+
+	return output
+}
+
+func provenanceOutputFor(provenance builder.Provenance) *ProvenanceOutput {
+	output := &ProvenanceOutput{
+		BuildImage: ProvenanceImageOutput{Name: provenance.BuildImage.Name, Digest: provenance.BuildImage.Digest},
+		Lifecycle: ProvenanceSourceOutput{
+			URI:    provenance.Lifecycle.URI,
+			Digest: provenance.Lifecycle.Digest,
+			ETag:   provenance.Lifecycle.ETag,
+		},
+		Buildpacks: []ProvenanceBuildpackOutput{},
+		Parameters: ProvenanceParametersOutput{
+			BuilderName: provenance.Parameters.BuilderName,
+			Publish:     provenance.Parameters.Publish,
+			NoPull:      provenance.Parameters.NoPull,
+		},
+	}
+
+	for _, bp := range provenance.Buildpacks {
+		output.Buildpacks = append(output.Buildpacks, ProvenanceBuildpackOutput{
+			ID:          bp.ID,
+			Version:     bp.Version,
+			URI:         bp.URI,
+			Digest:      bp.Digest,
+			ETag:        bp.ETag,
+			LayerDigest: bp.LayerDigest,
+		})
+	}
+	return output
+}
+
+func orderOutput(order dist.Order, layers builder.BuildpackLayers, depth, maxDepth int, visited map[string]bool) []GroupOutput {
+	groups := []GroupOutput{}
+	for _, entry := range order {
+		group := GroupOutput{Group: []BuildpackRefOutput{}}
+		for _, ref := range entry.Group {
+			refOutput := BuildpackRefOutput{ID: ref.ID, Version: ref.Version, Optional: ref.Optional}
+
+			nestedOrder, key := nestedOrderFor(ref, layers)
+			if len(nestedOrder) > 0 && !visited[key] && (maxDepth < 0 || depth < maxDepth) {
+				visited[key] = true
+				refOutput.Order = orderOutput(nestedOrder, layers, depth+1, maxDepth, visited)
+				delete(visited, key)
+			}
+
+			group.Group = append(group.Group, refOutput)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
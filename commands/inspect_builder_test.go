@@ -169,6 +169,7 @@ func testInspectBuilderCommand(t *testing.T, when spec.G, it spec.S) {
 						Name:    "Pack CLI",
 						Version: "1.2.3",
 					},
+					CompatPaths: []string{"/buildpacks", "/buildpacks/stack.toml", "/lifecycle"},
 				}
 				localInfo = &pack.BuilderInfo{
 					Description:     "Some local description",
@@ -225,6 +226,11 @@ Lifecycle:
   Buildpack API: 5.6
   Platform API: 7.8
 
+Compat Paths:
+  /buildpacks
+  /buildpacks/stack.toml
+  /lifecycle
+
 Run Images:
   first/local     (user-configured)
   second/local    (user-configured)
@@ -238,9 +244,9 @@ Buildpacks:
   test.bp.two        2.0.0
 
 Detection Order:
-  Group #1:
-    test.bp.one@1.0.0    (optional)
-    test.bp.two          
+  └ Group #1:
+     ├ test.bp.one@1.0.0    (optional)
+     └ test.bp.two
 `)
 
 					h.AssertContains(t, outBuf.String(), `
@@ -259,6 +265,9 @@ Lifecycle:
   Buildpack API: 1.2
   Platform API: 3.4
 
+Compat Paths:
+  (none)
+
 Run Images:
   first/local     (user-configured)
   second/local    (user-configured)
@@ -272,10 +281,10 @@ Buildpacks:
   test.bp.two        2.0.0
 
 Detection Order:
-  Group #1:
-    test.bp.one@1.0.0    
-  Group #2:
-    test.bp.two    (optional)
+  ├ Group #1:
+  │  └ test.bp.one@1.0.0
+  └ Group #2:
+     └ test.bp.two    (optional)
 `)
 				})
 			})
@@ -306,6 +315,11 @@ Lifecycle:
   Buildpack API: 5.6
   Platform API: 7.8
 
+Compat Paths:
+  /buildpacks
+  /buildpacks/stack.toml
+  /lifecycle
+
 Run Images:
   first/local     (user-configured)
   second/local    (user-configured)
@@ -319,9 +333,9 @@ Buildpacks:
   test.bp.two        2.0.0
 
 Detection Order:
-  Group #1:
-    test.bp.one@1.0.0    (optional)
-    test.bp.two          
+  └ Group #1:
+     ├ test.bp.one@1.0.0    (optional)
+     └ test.bp.two
 `)
 
 					h.AssertContains(t, outBuf.String(), `
@@ -340,6 +354,9 @@ Lifecycle:
   Buildpack API: 1.2
   Platform API: 3.4
 
+Compat Paths:
+  (none)
+
 Run Images:
   first/local     (user-configured)
   second/local    (user-configured)
@@ -353,11 +370,393 @@ Buildpacks:
   test.bp.two        2.0.0
 
 Detection Order:
-  Group #1:
-    test.bp.one@1.0.0    
-  Group #2:
-    test.bp.two    (optional)
+  ├ Group #1:
+  │  └ test.bp.one@1.0.0
+  └ Group #2:
+     └ test.bp.two    (optional)
+`)
+				})
+			})
+		})
+
+		when("the order contains order buildpacks", func() {
+			var info *pack.BuilderInfo
+
+			it.Before(func() {
+				info = &pack.BuilderInfo{
+					Stack: "test.stack.id",
+					Buildpacks: []builder.BuildpackMetadata{
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.meta", Version: "1.0.0"}, Latest: true},
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.nested", Version: "2.0.0"}, Latest: true},
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"}, Latest: true},
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.two", Version: "2.0.0"}, Latest: true},
+					},
+					Order: dist.Order{
+						{Group: []dist.BuildpackRef{
+							{BuildpackInfo: dist.BuildpackInfo{ID: "test.meta", Version: "1.0.0"}},
+							{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.two", Version: "2.0.0"}, Optional: true},
+						}},
+					},
+					BuildpackLayers: builder.BuildpackLayers{
+						"test.meta": {
+							"1.0.0": builder.BuildpackLayerInfo{
+								Order: dist.Order{
+									{Group: []dist.BuildpackRef{
+										{BuildpackInfo: dist.BuildpackInfo{ID: "test.nested"}},
+									}},
+									{Group: []dist.BuildpackRef{
+										{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.two", Version: "2.0.0"}},
+									}},
+								},
+							},
+						},
+						"test.nested": {
+							"2.0.0": builder.BuildpackLayerInfo{
+								Order: dist.Order{
+									{Group: []dist.BuildpackRef{
+										{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"}, Optional: true},
+										{BuildpackInfo: dist.BuildpackInfo{ID: "test.meta", Version: "1.0.0"}},
+									}},
+								},
+							},
+						},
+					},
+				}
+
+				mockClient.EXPECT().InspectBuilder("some/image", false).Return(info, nil)
+				mockClient.EXPECT().InspectBuilder("some/image", true).Return(nil, nil)
+			})
+
+			it("displays the nested detection order as a tree", func() {
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), `Detection Order:
+  └ Group #1:
+     ├ test.meta@1.0.0
+     │  ├ Group #1:
+     │  │  └ test.nested
+     │  │     └ Group #1:
+     │  │        ├ test.bp.one@1.0.0    (optional)
+     │  │        └ test.meta@1.0.0
+     │  └ Group #2:
+     │     └ test.bp.two@2.0.0
+     └ test.bp.two@2.0.0                (optional)
+`)
+			})
+
+			when("--depth is provided", func() {
+				it("limits the levels of nested buildpacks displayed", func() {
+					command.SetArgs([]string{"some/image", "--depth", "1"})
+					h.AssertNil(t, command.Execute())
+					h.AssertContains(t, outBuf.String(), `Detection Order:
+  └ Group #1:
+     ├ test.meta@1.0.0
+     │  ├ Group #1:
+     │  │  └ test.nested
+     │  └ Group #2:
+     │     └ test.bp.two@2.0.0
+     └ test.bp.two@2.0.0    (optional)
+`)
+				})
+
+				it("limits the nested order in structured output", func() {
+					command.SetArgs([]string{"some/image", "--depth", "0", "--output", "json"})
+					h.AssertNil(t, command.Execute())
+					h.AssertNotContains(t, outBuf.String(), `"order"`)
+				})
+			})
+
+			it("warns that the nested orders contain a cycle", func() {
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "Warning: 'some/image' has an invalid detection order: buildpack order contains a cycle: 'test.meta@1.0.0' -> 'test.nested@2.0.0' -> 'test.meta@1.0.0'")
+				h.AssertNotContains(t, outBuf.String(), "Flattened Detection Order:")
+			})
+		})
+
+		when("the builder has provenance", func() {
+			it.Before(func() {
+				info := &pack.BuilderInfo{
+					Stack: "test.stack.id",
+					Provenance: &builder.Provenance{
+						BuildImage: builder.ImageProvenance{Name: "some/build-image", Digest: "sha256:build-image"},
+						Lifecycle:  builder.SourceProvenance{URI: "https://example.com/lifecycle.tgz", Digest: "sha256:lifecycle"},
+						Buildpacks: []builder.BuildpackProvenance{{
+							BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"},
+							URI:           "https://example.com/bp-one.tgz",
+							Digest:        "sha256:bp-one",
+							ETag:          `"bp-one-etag"`,
+							LayerDigest:   "sha256:bp-one-layer",
+						}},
+						Parameters: builder.ProvenanceParameters{BuilderName: "some/image", Publish: true},
+					},
+				}
+
+				mockClient.EXPECT().InspectBuilder("some/image", false).Return(info, nil)
+				mockClient.EXPECT().InspectBuilder("some/image", true).Return(nil, nil)
+			})
+
+			it("displays the provenance", func() {
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), `
+Provenance:
+  Build Image:
+    Name: some/build-image
+    Digest: sha256:build-image
+  Lifecycle:
+    URI: https://example.com/lifecycle.tgz
+    Digest: sha256:lifecycle
+  Buildpacks:
+    test.bp.one@1.0.0
+      URI: https://example.com/bp-one.tgz
+      Digest: sha256:bp-one
+      ETag: "bp-one-etag"
+      Layer Digest: sha256:bp-one-layer
+  Parameters:
+    Builder Name: some/image
+    Publish: true
+    No Pull: false
 `)
+			})
+
+			it("includes the provenance in structured output", func() {
+				command.SetArgs([]string{"some/image", "--output", "json"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), `"provenance": {
+      "build_image": {
+        "name": "some/build-image",
+        "digest": "sha256:build-image"
+      },
+      "lifecycle": {
+        "uri": "https://example.com/lifecycle.tgz",
+        "digest": "sha256:lifecycle"
+      },
+      "buildpacks": [
+        {
+          "id": "test.bp.one",
+          "version": "1.0.0",
+          "uri": "https://example.com/bp-one.tgz",
+          "digest": "sha256:bp-one",
+          "etag": "\"bp-one-etag\"",
+          "layer_digest": "sha256:bp-one-layer"
+        }
+      ],
+      "parameters": {
+        "builder_name": "some/image",
+        "publish": true,
+        "no_pull": false
+      }
+    }`)
+			})
+		})
+
+		when("the order contains order buildpacks without cycles", func() {
+			it.Before(func() {
+				info := &pack.BuilderInfo{
+					Stack: "test.stack.id",
+					Buildpacks: []builder.BuildpackMetadata{
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.meta", Version: "1.0.0"}, Latest: true},
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"}, Latest: true},
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.two", Version: "2.0.0"}, Latest: true},
+					},
+					Order: dist.Order{
+						{Group: []dist.BuildpackRef{
+							{BuildpackInfo: dist.BuildpackInfo{ID: "test.meta", Version: "1.0.0"}, Optional: true},
+							{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.two"}},
+						}},
+					},
+					BuildpackLayers: builder.BuildpackLayers{
+						"test.meta": {"1.0.0": builder.BuildpackLayerInfo{
+							Order: dist.Order{
+								{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"}}}},
+								{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.two", Version: "2.0.0"}}}},
+							},
+						}},
+						"test.bp.one": {"1.0.0": builder.BuildpackLayerInfo{}},
+					},
+				}
+
+				mockClient.EXPECT().InspectBuilder("some/image", false).Return(info, nil)
+				mockClient.EXPECT().InspectBuilder("some/image", true).Return(nil, nil)
+			})
+
+			it("displays the flattened detection order", func() {
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), `
+Flattened Detection Order:
+  Group #1: test.bp.one@1.0.0 (optional), test.bp.two@2.0.0
+  Group #2: test.bp.two@2.0.0 (optional), test.bp.two@2.0.0
+`)
+				h.AssertNotContains(t, outBuf.String(), "invalid detection order")
+			})
+
+			it("includes the flattened detection order in structured output", func() {
+				command.SetArgs([]string{"some/image", "--output", "json"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), `"flattened_detection_order": [
+      {
+        "group": [
+          {
+            "id": "test.bp.one",
+            "version": "1.0.0",
+            "optional": true
+          },
+          {
+            "id": "test.bp.two",
+            "version": "2.0.0",
+            "optional": false
+          }
+        ]
+      },`)
+			})
+		})
+
+		when("--output is provided", func() {
+			var info = &pack.BuilderInfo{
+				Description:     "Some description",
+				Stack:           "test.stack.id",
+				RunImage:        "some/run-image",
+				RunImageMirrors: []string{"first/default"},
+				Buildpacks: []builder.BuildpackMetadata{
+					{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"}, Latest: true},
+				},
+				Order: dist.Order{
+					{Group: []dist.BuildpackRef{
+						{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"}, Optional: true},
+					}},
+				},
+				CreatedBy: builder.CreatorMetadata{Name: "Pack CLI", Version: "1.2.3"},
+			}
+
+			when("json", func() {
+				it("writes the builder information as json", func() {
+					mockClient.EXPECT().InspectBuilder("some/run-image-builder", false).Return(info, nil)
+					mockClient.EXPECT().InspectBuilder("some/run-image-builder", true).Return(nil, nil)
+
+					command.SetArgs([]string{"some/run-image-builder", "--output", "json"})
+					h.AssertNil(t, command.Execute())
+
+					h.AssertEq(t, outBuf.String(), `{
+  "builder_name": "some/run-image-builder",
+  "default": false,
+  "remote_info": {
+    "description": "Some description",
+    "created_by": {
+      "name": "Pack CLI",
+      "version": "1.2.3"
+    },
+    "stack": {
+      "id": "test.stack.id"
+    },
+    "lifecycle": {
+      "version": "0.3.0",
+      "buildpack_api": "0.1",
+      "platform_api": "0.1"
+    },
+    "compat_paths": [],
+    "run_images": [
+      {
+        "name": "first/local",
+        "user_configured": true
+      },
+      {
+        "name": "second/local",
+        "user_configured": true
+      },
+      {
+        "name": "some/run-image",
+        "user_configured": false
+      },
+      {
+        "name": "first/default",
+        "user_configured": false
+      }
+    ],
+    "buildpacks": [
+      {
+        "id": "test.bp.one",
+        "version": "1.0.0",
+        "latest": true
+      }
+    ],
+    "detection_order": [
+      {
+        "group": [
+          {
+            "id": "test.bp.one",
+            "version": "1.0.0",
+            "optional": true
+          }
+        ]
+      }
+    ],
+    "warnings": []
+  },
+  "local_info": null
+}
+`)
+				})
+			})
+
+			when("yaml", func() {
+				it("writes the builder information as yaml", func() {
+					mockClient.EXPECT().InspectBuilder("default/builder", false).Return(nil, nil)
+					mockClient.EXPECT().InspectBuilder("default/builder", true).Return(&pack.BuilderInfo{Stack: "test.stack.id"}, nil)
+
+					command.SetArgs([]string{"--output", "yaml"})
+					h.AssertNil(t, command.Execute())
+
+					h.AssertNotContains(t, outBuf.String(), "Inspecting default builder")
+					h.AssertContains(t, outBuf.String(), `builder_name: default/builder
+default: true
+remote_info: null
+local_info:
+  description: ""
+`)
+					h.AssertContains(t, outBuf.String(), `  stack:
+    id: test.stack.id
+`)
+					h.AssertContains(t, outBuf.String(), `  warnings:
+  - default/builder has no buildpacks
+`)
+				})
+			})
+
+			when("toml", func() {
+				it("writes the builder information as toml", func() {
+					mockClient.EXPECT().InspectBuilder("some/image", false).Return(info, nil)
+					mockClient.EXPECT().InspectBuilder("some/image", true).Return(nil, nil)
+
+					command.SetArgs([]string{"some/image", "-o", "toml"})
+					h.AssertNil(t, command.Execute())
+
+					h.AssertContains(t, outBuf.String(), `builder_name = "some/image"`)
+					h.AssertContains(t, outBuf.String(), `[remote_info.stack]
+    id = "test.stack.id"`)
+					h.AssertContains(t, outBuf.String(), `[[remote_info.run_images]]
+    name = "first/local"
+    user_configured = true`)
+					h.AssertNotContains(t, outBuf.String(), "local_info")
+				})
+			})
+
+			when("the format is unknown", func() {
+				it("returns an error", func() {
+					command.SetArgs([]string{"some/image", "--output", "xml"})
+					err := command.Execute()
+					h.AssertError(t, err, "invalid output format 'xml'")
+				})
+			})
+
+			when("inspecting fails", func() {
+				it("returns an error", func() {
+					mockClient.EXPECT().InspectBuilder("some/image", false).Return(nil, errors.New("some remote error"))
+
+					command.SetArgs([]string{"some/image", "--output", "json"})
+					err := command.Execute()
+					h.AssertError(t, err, "inspecting remote image 'some/image': some remote error")
 				})
 			})
 		})
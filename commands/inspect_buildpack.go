@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+func InspectBuildpack(logger logging.Logger, client PackClient) *cobra.Command {
+	var (
+		remote bool
+		depth  int
+	)
+	ctx := createCancellableContext()
+	cmd := &cobra.Command{
+		Use:   "inspect-buildpack <path|url|image>",
+		Short: "Show information about a buildpack or buildpackage",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			logger.Infof("Inspecting buildpack: %s\n", style.Symbol(name))
+
+			info, err := client.InspectBuildpack(ctx, pack.InspectBuildpackOptions{
+				BuildpackName: name,
+				Daemon:        !remote,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "inspecting buildpack %s", style.Symbol(name))
+			}
+
+			if info == nil {
+				logger.Info("(not present)")
+				return nil
+			}
+
+			var buf bytes.Buffer
+			if err := generateBuildpackOutput(&buf, *info, depth); err != nil {
+				return errors.Wrapf(err, "writing output for buildpack %s", style.Symbol(name))
+			}
+			logger.Info(buf.String())
+
+			for _, w := range buildpackWarnings(*info) {
+				logger.Warn(w)
+			}
+
+			return nil
+		}),
+	}
+	cmd.Flags().BoolVar(&remote, "remote", false, "Look up buildpackage images in the registry instead of the docker daemon")
+	cmd.Flags().IntVarP(&depth, "depth", "d", -1, "Max depth of nested buildpacks to expand in the detection order (all levels if negative)")
+	AddHelpFlag(cmd, "inspect-buildpack")
+	return cmd
+}
+
+func generateBuildpackOutput(writer io.Writer, info pack.InspectedBuildpack, depth int) error {
+	tpl := template.Must(template.New("").Parse(`
+Default Buildpack: {{ .Default }}
+
+Stacks:
+{{- if .Info.Stacks }}
+{{- range $index, $stack := .Info.Stacks }}
+  {{ $stack.ID }}
+{{- end }}
+{{- else }}
+  (none)
+{{- end }}
+
+Buildpacks:
+{{ .Buildpacks }}
+
+Detection Order:
+{{- if ne .Order "" }}
+{{ .Order }}
+{{- else }}
+  (none)
+{{- end }}`,
+	))
+
+	bps, err := inspectedBuildpacksOutput(info.Buildpacks)
+	if err != nil {
+		return err
+	}
+
+	order, err := detectionOrderOutput(info.Order(), info.BuildpackLayers(), depth)
+	if err != nil {
+		return err
+	}
+
+	return tpl.Execute(writer, &struct {
+		Info       pack.InspectedBuildpack
+		Default    string
+		Buildpacks string
+		Order      string
+	}{
+		info,
+		buildpackRef(info.Default),
+		bps,
+		order,
+	})
+}
+
+func inspectedBuildpacksOutput(bps []pack.BuildpackDetails) (string, error) {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 4, ' ', 0)
+	if _, err := fmt.Fprint(tabWriter, "  ID\tVERSION\tAPI\tLAYER DIGEST\tSIZE\n"); err != nil {
+		return "", err
+	}
+
+	for _, bp := range bps {
+		bpAPI := api.MustParse(dist.AssumedBuildpackAPIVersion)
+		if bp.Descriptor.API != nil {
+			bpAPI = bp.Descriptor.API
+		}
+
+		if _, err := fmt.Fprintf(
+			tabWriter,
+			"  %s\t%s\t%s\t%s\t%s\n",
+			bp.Descriptor.Info.ID,
+			bp.Descriptor.Info.Version,
+			bpAPI,
+			bp.LayerDigest,
+			units.HumanSize(float64(bp.LayerSize)),
+		); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tabWriter.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func buildpackWarnings(info pack.InspectedBuildpack) []string {
+	var warnings []string
+	if len(info.Buildpacks) == 0 {
+		warnings = append(warnings, fmt.Sprintf("no buildpack layers found for %s", style.Symbol(buildpackRef(info.Default))))
+	}
+
+	for _, bp := range info.Buildpacks {
+		for _, executable := range bp.MissingExecutables {
+			warnings = append(warnings, fmt.Sprintf(
+				"buildpack %s is missing executable %s",
+				style.Symbol(buildpackRef(bp.Descriptor.Info)),
+				style.Symbol(executable),
+			))
+		}
+	}
+	return warnings
+}
+
+func buildpackRef(info dist.BuildpackInfo) string {
+	if info.Version == "" {
+		return info.ID
+	}
+	return info.ID + "@" + info.Version
+}
@@ -0,0 +1,154 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/dist"
+	ilogging "github.com/buildpack/pack/internal/logging"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestInspectBuildpackCommand(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Commands", testInspectBuildpackCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testInspectBuildpackCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *cmdmocks.MockPackClient
+		info           *pack.InspectedBuildpack
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockClient = cmdmocks.NewMockPackClient(mockController)
+		logger = ilogging.NewLogWithWriters(&outBuf, &outBuf)
+
+		info = &pack.InspectedBuildpack{
+			Default: dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+			Stacks:  []dist.Stack{{ID: "some.stack.id"}, {ID: "other.stack.id"}},
+			Buildpacks: []pack.BuildpackDetails{
+				{
+					Descriptor: dist.BuildpackDescriptor{
+						API:  api.MustParse("0.2"),
+						Info: dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+						Order: dist.Order{{Group: []dist.BuildpackRef{
+							{BuildpackInfo: dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"}},
+							{BuildpackInfo: dist.BuildpackInfo{ID: "bp.two", Version: "2.0.0"}, Optional: true},
+						}}},
+					},
+					LayerDigest: "sha256:meta-digest",
+					LayerSize:   1024,
+				},
+				{
+					Descriptor: dist.BuildpackDescriptor{
+						API:    api.MustParse("0.2"),
+						Info:   dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+						Stacks: []dist.Stack{{ID: "some.stack.id"}},
+					},
+					LayerDigest:        "sha256:one-digest",
+					LayerSize:          2048,
+					MissingExecutables: []string{"bin/build"},
+				},
+			},
+		}
+
+		command = commands.InspectBuildpack(logger, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#InspectBuildpack", func() {
+		it("displays the buildpack information", func() {
+			mockClient.EXPECT().InspectBuildpack(gomock.Any(), pack.InspectBuildpackOptions{
+				BuildpackName: "some/package",
+				Daemon:        true,
+			}).Return(info, nil)
+
+			command.SetArgs([]string{"some/package"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertEq(t, outBuf.String(), `Inspecting buildpack: 'some/package'
+
+Default Buildpack: bp.meta@1.0.0
+
+Stacks:
+  some.stack.id
+  other.stack.id
+
+Buildpacks:
+  ID         VERSION    API    LAYER DIGEST          SIZE
+  bp.meta    1.0.0      0.2    sha256:meta-digest    1.024kB
+  bp.one     1.2.3      0.2    sha256:one-digest     2.048kB
+
+Detection Order:
+  └ Group #1:
+     ├ bp.one@1.2.3
+     └ bp.two@2.0.0    (optional)
+Warning: buildpack 'bp.one@1.2.3' is missing executable 'bin/build'
+`)
+		})
+
+		it("looks up images in the registry when --remote is provided", func() {
+			mockClient.EXPECT().InspectBuildpack(gomock.Any(), pack.InspectBuildpackOptions{
+				BuildpackName: "some/package",
+				Daemon:        false,
+			}).Return(info, nil)
+
+			command.SetArgs([]string{"some/package", "--remote"})
+			h.AssertNil(t, command.Execute())
+		})
+
+		when("the buildpack cannot be found", func() {
+			it("logs 'Not present'", func() {
+				mockClient.EXPECT().InspectBuildpack(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+				command.SetArgs([]string{"some/package"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "(not present)")
+			})
+		})
+
+		when("the client returns an error", func() {
+			it("returns the error", func() {
+				mockClient.EXPECT().InspectBuildpack(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+
+				command.SetArgs([]string{"some/package"})
+				h.AssertError(t, command.Execute(), "inspecting buildpack 'some/package': some error")
+			})
+		})
+
+		when("the buildpackage does not list its buildpacks", func() {
+			it("warns", func() {
+				mockClient.EXPECT().InspectBuildpack(gomock.Any(), gomock.Any()).Return(&pack.InspectedBuildpack{
+					Default: dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+				}, nil)
+
+				command.SetArgs([]string{"some/package"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "Detection Order:\n  (none)")
+				h.AssertContains(t, outBuf.String(), "Warning: no buildpack layers found for 'bp.meta@1.0.0'")
+			})
+		})
+	})
+}
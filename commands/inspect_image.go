@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/buildpack/lifecycle/metadata"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+func InspectImage(logger logging.Logger, cfg config.Config, client PackClient) *cobra.Command {
+	var showLayers bool
+	ctx := createCancellableContext()
+	cmd := &cobra.Command{
+		Use:   "inspect-image <image-name>",
+		Short: "Show information about a built image",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			imageName := args[0]
+			logger.Infof("Inspecting image: %s\n", style.Symbol(imageName))
+
+			remoteOutput, err := inspectImageOutput(ctx, client, cfg, imageName, false, showLayers)
+			if err != nil {
+				logger.Error(err.Error())
+			} else {
+				logger.Infof("REMOTE:\n%s\n", remoteOutput)
+			}
+
+			localOutput, err := inspectImageOutput(ctx, client, cfg, imageName, true, showLayers)
+			if err != nil {
+				logger.Error(err.Error())
+			} else {
+				logger.Infof("\nLOCAL:\n%s\n", localOutput)
+			}
+
+			return nil
+		}),
+	}
+	cmd.Flags().BoolVar(&showLayers, "layers", false, "Show the buildpack, app or run image layer behind each image layer, and its size")
+	AddHelpFlag(cmd, "inspect-image")
+	return cmd
+}
+
+func inspectImageOutput(ctx context.Context, client PackClient, cfg config.Config, imageName string, local, showLayers bool) (output string, err error) {
+	source := "remote"
+	if local {
+		source = "local"
+	}
+
+	info, err := client.InspectImage(ctx, imageName, local)
+	if err != nil {
+		return "", errors.Wrapf(err, "inspecting %s image '%s'", source, imageName)
+	}
+
+	if info == nil {
+		return "(not present)", nil
+	}
+
+	var buf bytes.Buffer
+	if err := generateImageOutput(&buf, cfg, *info); err != nil {
+		return "", errors.Wrapf(err, "writing output for %s image '%s'", source, imageName)
+	}
+
+	if showLayers {
+		layers, err := client.InspectImageLayers(ctx, imageName, local)
+		if err != nil {
+			return "", errors.Wrapf(err, "inspecting layers of %s image '%s'", source, imageName)
+		}
+
+		layersOutput, err := imageLayersOutput(layers)
+		if err != nil {
+			return "", errors.Wrapf(err, "writing layers for %s image '%s'", source, imageName)
+		}
+		buf.WriteString("\n\nLayers:\n" + layersOutput)
+	}
+
+	return buf.String(), nil
+}
+
+func generateImageOutput(writer io.Writer, cfg config.Config, info pack.ImageInfo) error {
+	tpl := template.Must(template.New("").Parse(`
+Stack: {{ .Info.StackID }}
+
+Base Image:
+{{- if ne .Info.Base.SHA "" }}
+  Digest: {{ .Info.Base.SHA }}
+{{- end }}
+  Top Layer: {{ .Info.Base.TopLayer }}
+
+Run Images:
+{{- if ne .RunImages "" }}
+{{ .RunImages }}
+{{- else }}
+  (none)
+{{- end }}
+
+Buildpacks:
+{{- if .Info.Buildpacks }}
+{{ .Buildpacks }}
+{{- else }}
+  (none)
+{{- end }}
+
+Buildpack Layers:
+{{- if ne .Layers "" }}
+{{ .Layers }}
+{{- else }}
+  (none)
+{{- end }}
+
+Processes:
+{{- if .Info.Processes }}
+{{ .Processes }}
+{{- else }}
+  (none)
+{{- end }}`,
+	))
+
+	bps, err := imageBuildpacksOutput(info.Buildpacks)
+	if err != nil {
+		return err
+	}
+
+	layers, err := buildpackLayersOutput(info.Buildpacks)
+	if err != nil {
+		return err
+	}
+
+	runImgs, err := runImagesOutput(info.Stack.RunImage.Image, info.Stack.RunImage.Mirrors, cfg)
+	if err != nil {
+		return err
+	}
+
+	processes, err := processesOutput(info.Processes)
+	if err != nil {
+		return err
+	}
+
+	return tpl.Execute(writer, &struct {
+		Info       pack.ImageInfo
+		Buildpacks string
+		Layers     string
+		RunImages  string
+		Processes  string
+	}{
+		info,
+		bps,
+		layers,
+		runImgs,
+		processes,
+	})
+}
+
+func imageBuildpacksOutput(bps []metadata.BuildpackMetadata) (string, error) {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 8, ' ', 0)
+	if _, err := fmt.Fprint(tabWriter, "  ID\tVERSION\n"); err != nil {
+		return "", err
+	}
+
+	for _, bp := range bps {
+		if _, err := fmt.Fprintf(tabWriter, "  %s\t%s\n", bp.ID, bp.Version); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tabWriter.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func buildpackLayersOutput(bps []metadata.BuildpackMetadata) (string, error) {
+	buf := strings.Builder{}
+	for _, bp := range bps {
+		if len(bp.Layers) == 0 {
+			continue
+		}
+
+		bpRef := bp.ID
+		if bp.Version != "" {
+			bpRef += "@" + bp.Version
+		}
+		buf.WriteString(fmt.Sprintf("  %s:\n", bpRef))
+
+		var names []string
+		for name := range bp.Layers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		tabWriter := new(tabwriter.Writer).Init(&buf, 0, 0, 4, ' ', 0)
+		for _, name := range names {
+			layer := bp.Layers[name]
+			if _, err := fmt.Fprintf(tabWriter, "    %s\t%s\t%s\n", name, layer.SHA, layerTypes(layer)); err != nil {
+				return "", err
+			}
+		}
+		if err := tabWriter.Flush(); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func layerTypes(layer metadata.LayerMetadata) string {
+	var types []string
+	if layer.Build {
+		types = append(types, "build")
+	}
+	if layer.Launch {
+		types = append(types, "launch")
+	}
+	if layer.Cache {
+		types = append(types, "cache")
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(types, ", ") + ")"
+}
+
+func processesOutput(processes []pack.ProcessInfo) (string, error) {
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 4, ' ', 0)
+	if _, err := fmt.Fprint(tabWriter, "  TYPE\tSHELL\tCOMMAND\tARGS\n"); err != nil {
+		return "", err
+	}
+
+	for _, p := range processes {
+		shell := "bash"
+		if p.Direct {
+			shell = ""
+		}
+		if _, err := fmt.Fprintf(tabWriter, "  %s\t%s\t%s\t%s\n", p.Type, shell, p.Command, strings.Join(p.Args, " ")); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tabWriter.Flush(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func imageLayersOutput(layers []pack.ImageLayer) (string, error) {
+	if len(layers) == 0 {
+		return "  (none)", nil
+	}
+
+	buf := &bytes.Buffer{}
+	tabWriter := new(tabwriter.Writer).Init(buf, 0, 0, 4, ' ', 0)
+	if _, err := fmt.Fprint(tabWriter, "  TYPE\tSIZE\tDIFF ID\tCONTRIBUTOR\n"); err != nil {
+		return "", err
+	}
+
+	var runImageSize, appSize int64
+	for _, layer := range layers {
+		if layer.Type == pack.RunImageLayer {
+			runImageSize += layer.Size
+		} else {
+			appSize += layer.Size
+		}
+
+		var contributor string
+		if layer.Type == pack.BuildpackLayer {
+			contributor = buildpackRef(layer.Buildpack) + ":" + layer.Name
+		}
+
+		if _, err := fmt.Fprintf(tabWriter, "  %s\t%s\t%s\t%s\n", layer.Type, units.HumanSize(float64(layer.Size)), layer.DiffID, contributor); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tabWriter.Flush(); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(buf, "\n  Run image layers: %s\n  Build layers: %s", units.HumanSize(float64(runImageSize)), units.HumanSize(float64(appSize)))
+	return buf.String(), nil
+}
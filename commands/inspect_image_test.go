@@ -0,0 +1,185 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/buildpack/lifecycle/metadata"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/config"
+	"github.com/buildpack/pack/dist"
+	ilogging "github.com/buildpack/pack/internal/logging"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestInspectImageCommand(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Commands", testInspectImageCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testInspectImageCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *cmdmocks.MockPackClient
+		cfg            config.Config
+	)
+
+	it.Before(func() {
+		cfg = config.Config{
+			RunImages: []config.RunImage{
+				{Image: "some/run-image", Mirrors: []string{"first/local"}},
+			},
+		}
+		mockController = gomock.NewController(t)
+		mockClient = cmdmocks.NewMockPackClient(mockController)
+		logger = ilogging.NewLogWithWriters(&outBuf, &outBuf)
+
+		command = commands.InspectImage(logger, cfg, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#InspectImage", func() {
+		when("image cannot be found", func() {
+			it("logs 'Not present'", func() {
+				mockClient.EXPECT().InspectImage(gomock.Any(), "some/image", false).Return(nil, nil)
+				mockClient.EXPECT().InspectImage(gomock.Any(), "some/image", true).Return(nil, nil)
+
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+
+				h.AssertContains(t, outBuf.String(), "REMOTE:\n(not present)\n\nLOCAL:\n(not present)\n")
+			})
+		})
+
+		when("inspector returns an error", func() {
+			it("logs the error message", func() {
+				mockClient.EXPECT().InspectImage(gomock.Any(), "some/image", false).Return(nil, errors.New("some remote error"))
+				mockClient.EXPECT().InspectImage(gomock.Any(), "some/image", true).Return(nil, errors.New("some local error"))
+
+				command.SetArgs([]string{"some/image"})
+				h.AssertNil(t, command.Execute())
+
+				h.AssertContains(t, outBuf.String(), `ERROR: inspecting remote image 'some/image': some remote error`)
+				h.AssertContains(t, outBuf.String(), `ERROR: inspecting local image 'some/image': some local error`)
+			})
+		})
+
+		when("is successful", func() {
+			it.Before(func() {
+				info := &pack.ImageInfo{
+					StackID: "test.stack.id",
+					Buildpacks: []metadata.BuildpackMetadata{
+						{
+							ID:      "test.bp.one",
+							Version: "1.0.0",
+							Layers: map[string]metadata.LayerMetadata{
+								"jdk":   {SHA: "sha256:jdk-sha", Launch: true, Cache: true},
+								"cache": {SHA: "sha256:cache-sha", Build: true},
+							},
+						},
+						{ID: "test.bp.two", Version: "2.0.0"},
+					},
+					Base: metadata.RunImageMetadata{
+						TopLayer: "sha256:top-layer",
+						SHA:      "sha256:base-digest",
+					},
+					Stack: metadata.StackMetadata{
+						RunImage: metadata.StackRunImageMetadata{
+							Image:   "some/run-image",
+							Mirrors: []string{"some/mirror"},
+						},
+					},
+					Processes: []pack.ProcessInfo{
+						{Type: "web", Command: "bundle exec rackup", Args: []string{"-p", "8080"}},
+						{Type: "worker", Command: "/bin/worker", Direct: true},
+					},
+				}
+				mockClient.EXPECT().InspectImage(gomock.Any(), "some/image", false).Return(info, nil)
+				mockClient.EXPECT().InspectImage(gomock.Any(), "some/image", true).Return(nil, nil)
+				command.SetArgs([]string{"some/image"})
+			})
+
+			it("displays the image information", func() {
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "Inspecting image: 'some/image'")
+				h.AssertContains(t, outBuf.String(), `
+REMOTE:
+
+Stack: test.stack.id
+
+Base Image:
+  Digest: sha256:base-digest
+  Top Layer: sha256:top-layer
+
+Run Images:
+  first/local    (user-configured)
+  some/run-image
+  some/mirror
+
+Buildpacks:
+  ID                 VERSION
+  test.bp.one        1.0.0
+  test.bp.two        2.0.0
+
+Buildpack Layers:
+  test.bp.one@1.0.0:
+    cache    sha256:cache-sha    (build)
+    jdk      sha256:jdk-sha      (launch, cache)
+
+Processes:
+  TYPE      SHELL    COMMAND               ARGS
+  web       bash     bundle exec rackup    -p 8080
+  worker             /bin/worker           
+`)
+				h.AssertContains(t, outBuf.String(), "LOCAL:\n(not present)\n")
+				h.AssertNotContains(t, outBuf.String(), "\nLayers:\n")
+			})
+
+			when("--layers is provided", func() {
+				it("displays the image layers", func() {
+					mockClient.EXPECT().InspectImageLayers(gomock.Any(), "some/image", false).Return([]pack.ImageLayer{
+						{DiffID: "sha256:base-layer", Size: 1000, Type: pack.RunImageLayer},
+						{
+							DiffID:    "sha256:jdk-sha",
+							Size:      2000,
+							Type:      pack.BuildpackLayer,
+							Buildpack: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"},
+							Name:      "jdk",
+						},
+						{DiffID: "sha256:app-sha", Size: 500, Type: pack.AppLayer},
+					}, nil)
+
+					command.SetArgs([]string{"some/image", "--layers"})
+					h.AssertNil(t, command.Execute())
+					h.AssertContains(t, outBuf.String(), `
+Layers:
+  TYPE         SIZE    DIFF ID              CONTRIBUTOR
+  run-image    1kB     sha256:base-layer    
+  buildpack    2kB     sha256:jdk-sha       test.bp.one@1.0.0:jdk
+  app          500B    sha256:app-sha       
+
+  Run image layers: 1kB
+  Build layers: 2.5kB
+`)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+func LintBuildpack(logger logging.Logger, client PackClient) *cobra.Command {
+	var buildpackAPI string
+	ctx := createCancellableContext()
+	cmd := &cobra.Command{
+		Use:   "lint-buildpack <path|url>",
+		Short: "Check a buildpack for common mistakes",
+		Args:  cobra.ExactArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			opts := pack.LintBuildpackOptions{BuildpackName: name}
+			if buildpackAPI != "" {
+				version, err := api.NewVersion(buildpackAPI)
+				if err != nil {
+					return errors.Wrapf(err, "invalid buildpack API %s", style.Symbol(buildpackAPI))
+				}
+				opts.BuildpackAPI = version
+			}
+
+			findings, err := client.LintBuildpack(ctx, opts)
+			if err != nil {
+				return errors.Wrapf(err, "linting buildpack %s", style.Symbol(name))
+			}
+
+			for _, f := range findings {
+				if f.Severity == dist.SeverityError {
+					logger.Errorf("[%s] %s", f.Rule, f.Message)
+				} else {
+					logger.Warnf("[%s] %s", f.Rule, f.Message)
+				}
+			}
+
+			errs := dist.LintErrors(findings)
+			if len(errs) > 0 {
+				logger.Infof("Buildpack %s has %d error(s) and %d warning(s)", style.Symbol(name), len(errs), len(findings)-len(errs))
+				return MakeSoftError()
+			}
+
+			if len(findings) > 0 {
+				logger.Infof("Buildpack %s has %d warning(s)", style.Symbol(name), len(findings))
+				return nil
+			}
+
+			logger.Infof("No problems found with buildpack %s", style.Symbol(name))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&buildpackAPI, "buildpack-api", "", "Buildpack API of the lifecycle the buildpack must be compatible with")
+	AddHelpFlag(cmd, "lint-buildpack")
+	return cmd
+}
@@ -0,0 +1,88 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/dist"
+	ilogging "github.com/buildpack/pack/internal/logging"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestLintBuildpackCommand(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Commands", testLintBuildpackCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testLintBuildpackCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *cmdmocks.MockPackClient
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockClient = cmdmocks.NewMockPackClient(mockController)
+		logger = ilogging.NewLogWithWriters(&outBuf, &outBuf)
+		command = commands.LintBuildpack(logger, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#LintBuildpack", func() {
+		it("reports that no problems were found", func() {
+			mockClient.EXPECT().LintBuildpack(gomock.Any(), pack.LintBuildpackOptions{
+				BuildpackName: "some/buildpack",
+			}).Return(nil, nil)
+
+			command.SetArgs([]string{"some/buildpack"})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "No problems found with buildpack 'some/buildpack'")
+		})
+
+		it("shows the findings and fails when there are errors", func() {
+			mockClient.EXPECT().LintBuildpack(gomock.Any(), pack.LintBuildpackOptions{
+				BuildpackName: "some/buildpack",
+				BuildpackAPI:  api.MustParse("0.2"),
+			}).Return([]dist.Finding{
+				{Severity: dist.SeverityWarning, Rule: dist.RuleAPI, Message: "some warning"},
+				{Severity: dist.SeverityError, Rule: dist.RuleExecutables, Message: "some error"},
+			}, nil)
+
+			command.SetArgs([]string{"some/buildpack", "--buildpack-api", "0.2"})
+			err := command.Execute()
+			h.AssertEq(t, commands.IsSoftError(err), true)
+
+			h.AssertContains(t, outBuf.String(), "Warning: [api] some warning")
+			h.AssertContains(t, outBuf.String(), "ERROR: [executables] some error")
+			h.AssertContains(t, outBuf.String(), "Buildpack 'some/buildpack' has 1 error(s) and 1 warning(s)")
+		})
+
+		it("does not fail when there are only warnings", func() {
+			mockClient.EXPECT().LintBuildpack(gomock.Any(), gomock.Any()).Return([]dist.Finding{
+				{Severity: dist.SeverityWarning, Rule: dist.RuleVersion, Message: "some warning"},
+			}, nil)
+
+			command.SetArgs([]string{"some/buildpack"})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Buildpack 'some/buildpack' has 1 warning(s)")
+		})
+	})
+}
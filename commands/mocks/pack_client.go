@@ -11,6 +11,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 
 	pack "github.com/buildpack/pack"
+	dist "github.com/buildpack/pack/dist"
 )
 
 // MockPackClient is a mock of PackClient interface
@@ -78,6 +79,21 @@ func (mr *MockPackClientMockRecorder) CreatePackage(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePackage", reflect.TypeOf((*MockPackClient)(nil).CreatePackage), arg0, arg1)
 }
 
+// DiffBuilders mocks base method
+func (m *MockPackClient) DiffBuilders(arg0 pack.DiffBuildersOptions) (*pack.BuilderDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffBuilders", arg0)
+	ret0, _ := ret[0].(*pack.BuilderDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffBuilders indicates an expected call of DiffBuilders
+func (mr *MockPackClientMockRecorder) DiffBuilders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffBuilders", reflect.TypeOf((*MockPackClient)(nil).DiffBuilders), arg0)
+}
+
 // InspectBuilder mocks base method
 func (m *MockPackClient) InspectBuilder(arg0 string, arg1 bool) (*pack.BuilderInfo, error) {
 	m.ctrl.T.Helper()
@@ -93,16 +109,105 @@ func (mr *MockPackClientMockRecorder) InspectBuilder(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectBuilder", reflect.TypeOf((*MockPackClient)(nil).InspectBuilder), arg0, arg1)
 }
 
-// Rebase mocks base method
-func (m *MockPackClient) Rebase(arg0 context.Context, arg1 pack.RebaseOptions) error {
+// InspectBuildpack mocks base method
+func (m *MockPackClient) InspectBuildpack(arg0 context.Context, arg1 pack.InspectBuildpackOptions) (*pack.InspectedBuildpack, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectBuildpack", arg0, arg1)
+	ret0, _ := ret[0].(*pack.InspectedBuildpack)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectBuildpack indicates an expected call of InspectBuildpack
+func (mr *MockPackClientMockRecorder) InspectBuildpack(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectBuildpack", reflect.TypeOf((*MockPackClient)(nil).InspectBuildpack), arg0, arg1)
+}
+
+// InspectImage mocks base method
+func (m *MockPackClient) InspectImage(arg0 context.Context, arg1 string, arg2 bool) (*pack.ImageInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectImage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*pack.ImageInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectImage indicates an expected call of InspectImage
+func (mr *MockPackClientMockRecorder) InspectImage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectImage", reflect.TypeOf((*MockPackClient)(nil).InspectImage), arg0, arg1, arg2)
+}
+
+// InspectImageLayers mocks base method
+func (m *MockPackClient) InspectImageLayers(arg0 context.Context, arg1 string, arg2 bool) ([]pack.ImageLayer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rebase", arg0, arg1)
+	ret := m.ctrl.Call(m, "InspectImageLayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]pack.ImageLayer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectImageLayers indicates an expected call of InspectImageLayers
+func (mr *MockPackClientMockRecorder) InspectImageLayers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectImageLayers", reflect.TypeOf((*MockPackClient)(nil).InspectImageLayers), arg0, arg1, arg2)
+}
+
+// LintBuildpack mocks base method
+func (m *MockPackClient) LintBuildpack(arg0 context.Context, arg1 pack.LintBuildpackOptions) ([]dist.Finding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LintBuildpack", arg0, arg1)
+	ret0, _ := ret[0].([]dist.Finding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LintBuildpack indicates an expected call of LintBuildpack
+func (mr *MockPackClientMockRecorder) LintBuildpack(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LintBuildpack", reflect.TypeOf((*MockPackClient)(nil).LintBuildpack), arg0, arg1)
+}
+
+// ModifyBuilder mocks base method
+func (m *MockPackClient) ModifyBuilder(arg0 context.Context, arg1 pack.ModifyBuilderOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyBuilder", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Rebase indicates an expected call of Rebase
-func (mr *MockPackClientMockRecorder) Rebase(arg0, arg1 interface{}) *gomock.Call {
+// ModifyBuilder indicates an expected call of ModifyBuilder
+func (mr *MockPackClientMockRecorder) ModifyBuilder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyBuilder", reflect.TypeOf((*MockPackClient)(nil).ModifyBuilder), arg0, arg1)
+}
+
+// RebaseMany mocks base method
+func (m *MockPackClient) RebaseMany(arg0 context.Context, arg1 pack.RebaseManyOptions) []pack.RebaseResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebaseMany", arg0, arg1)
+	ret0, _ := ret[0].([]pack.RebaseResult)
+	return ret0
+}
+
+// RebaseMany indicates an expected call of RebaseMany
+func (mr *MockPackClientMockRecorder) RebaseMany(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebaseMany", reflect.TypeOf((*MockPackClient)(nil).RebaseMany), arg0, arg1)
+}
+
+// RebaseWithReport mocks base method
+func (m *MockPackClient) RebaseWithReport(arg0 context.Context, arg1 pack.RebaseOptions) (*pack.RebaseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebaseWithReport", arg0, arg1)
+	ret0, _ := ret[0].(*pack.RebaseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RebaseWithReport indicates an expected call of RebaseWithReport
+func (mr *MockPackClientMockRecorder) RebaseWithReport(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebase", reflect.TypeOf((*MockPackClient)(nil).Rebase), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebaseWithReport", reflect.TypeOf((*MockPackClient)(nil).RebaseWithReport), arg0, arg1)
 }
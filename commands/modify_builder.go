@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+type ModifyBuilderFlags struct {
+	Buildpacks       []string
+	RemoveBuildpacks []string
+	OrderFile        string
+	Description      string
+	RunImageMirrors  []string
+	LifecycleURI     string
+	LifecycleVersion string
+	Tag              string
+	Publish          bool
+	NoPull           bool
+}
+
+func ModifyBuilder(logger logging.Logger, client PackClient) *cobra.Command {
+	var flags ModifyBuilderFlags
+	ctx := createCancellableContext()
+	cmd := &cobra.Command{
+		Use:   "modify-builder <builder-image-name>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Add or remove buildpacks, or change the order, lifecycle or run image mirrors of an existing builder",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			var removeBuildpacks []dist.BuildpackInfo
+			for _, ref := range flags.RemoveBuildpacks {
+				removeBuildpacks = append(removeBuildpacks, parseBuildpackInfo(ref))
+			}
+
+			var order dist.Order
+			if flags.OrderFile != "" {
+				var err error
+				if order, err = readOrderFile(flags.OrderFile); err != nil {
+					return err
+				}
+			}
+
+			var mirrors []string
+			if cmd.Flags().Changed("run-image-mirror") {
+				mirrors = append([]string{}, flags.RunImageMirrors...)
+			}
+
+			builderName := args[0]
+			if err := client.ModifyBuilder(ctx, pack.ModifyBuilderOptions{
+				BuilderName:      builderName,
+				TargetName:       flags.Tag,
+				AddBuildpacks:    flags.Buildpacks,
+				RemoveBuildpacks: removeBuildpacks,
+				Order:            order,
+				Description:      flags.Description,
+				RunImageMirrors:  mirrors,
+				Lifecycle: builder.LifecycleConfig{
+					URI:     flags.LifecycleURI,
+					Version: flags.LifecycleVersion,
+				},
+				Publish: flags.Publish,
+				NoPull:  flags.NoPull,
+			}); err != nil {
+				return err
+			}
+
+			modifiedName := builderName
+			if flags.Tag != "" {
+				modifiedName = flags.Tag
+			}
+			logger.Infof("Successfully modified builder %s", style.Symbol(modifiedName))
+			return nil
+		}),
+	}
+	cmd.Flags().StringSliceVarP(&flags.Buildpacks, "buildpack", "b", nil, "Path or URL of a buildpack, or name of a buildpackage image, to add"+multiValueHelp("buildpack"))
+	cmd.Flags().StringSliceVar(&flags.RemoveBuildpacks, "remove-buildpack", nil, "Buildpack to remove, as <id> or <id>@<version>"+multiValueHelp("buildpack"))
+	cmd.Flags().StringVar(&flags.OrderFile, "order-file", "", "Path to a TOML file with an [[order]] table that replaces the order of the builder")
+	cmd.Flags().StringVar(&flags.Description, "description", "", "New description of the builder")
+	cmd.Flags().StringSliceVar(&flags.RunImageMirrors, "run-image-mirror", nil, "Run image mirror that replaces the mirrors of the builder"+multiValueHelp("mirror"))
+	cmd.Flags().StringVar(&flags.LifecycleURI, "lifecycle-uri", "", "URI of the lifecycle to use in the builder")
+	cmd.Flags().StringVar(&flags.LifecycleVersion, "lifecycle-version", "", "Version of the lifecycle to use in the builder")
+	cmd.Flags().StringVarP(&flags.Tag, "tag", "t", "", "Name of the modified builder (the builder is modified in place if omitted)")
+	cmd.Flags().BoolVar(&flags.Publish, "publish", false, "Modify the builder in the registry")
+	cmd.Flags().BoolVar(&flags.NoPull, "no-pull", false, "Skip pulling the builder and buildpackage images before use")
+	AddHelpFlag(cmd, "modify-builder")
+	return cmd
+}
+
+func parseBuildpackInfo(ref string) dist.BuildpackInfo {
+	parts := strings.SplitN(ref, "@", 2)
+	if len(parts) == 2 {
+		return dist.BuildpackInfo{ID: parts[0], Version: parts[1]}
+	}
+	return dist.BuildpackInfo{ID: parts[0]}
+}
+
+func readOrderFile(path string) (dist.Order, error) {
+	var orderFile struct {
+		Order dist.Order `toml:"order"`
+	}
+	if _, err := toml.DecodeFile(path, &orderFile); err != nil {
+		return nil, errors.Wrapf(err, "reading order file %s", style.Symbol(path))
+	}
+	if len(orderFile.Order) == 0 {
+		return nil, fmt.Errorf("order file %s does not define an order", style.Symbol(path))
+	}
+	return orderFile.Order, nil
+}
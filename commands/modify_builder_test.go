@@ -0,0 +1,124 @@
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/dist"
+	ilogging "github.com/buildpack/pack/internal/logging"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestModifyBuilderCommand(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Commands", testModifyBuilderCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testModifyBuilderCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *cmdmocks.MockPackClient
+		tmpDir         string
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockClient = cmdmocks.NewMockPackClient(mockController)
+		logger = ilogging.NewLogWithWriters(&outBuf, &outBuf)
+		command = commands.ModifyBuilder(logger, mockClient)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "modify-builder-command-test")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	when("#ModifyBuilder", func() {
+		it("passes the changes to the client", func() {
+			orderFile := filepath.Join(tmpDir, "order.toml")
+			h.AssertNil(t, ioutil.WriteFile(orderFile, []byte(`
+[[order]]
+  [[order.group]]
+    id = "bp.new"
+    version = "2.0.0"
+  [[order.group]]
+    id = "bp.other"
+    optional = true
+`), 0644))
+
+			mockClient.EXPECT().ModifyBuilder(gomock.Any(), pack.ModifyBuilderOptions{
+				BuilderName:   "some/builder",
+				TargetName:    "some/builder:fixed",
+				AddBuildpacks: []string{"https://example.com/bp.tgz", "some/package"},
+				RemoveBuildpacks: []dist.BuildpackInfo{
+					{ID: "bp.old", Version: "1.0.0"},
+					{ID: "bp.unused"},
+				},
+				Order: dist.Order{{Group: []dist.BuildpackRef{
+					{BuildpackInfo: dist.BuildpackInfo{ID: "bp.new", Version: "2.0.0"}},
+					{BuildpackInfo: dist.BuildpackInfo{ID: "bp.other"}, Optional: true},
+				}}},
+				Description: "Fixed builder",
+				Lifecycle:   builder.LifecycleConfig{Version: "0.5.0"},
+				Publish:     true,
+			}).Return(nil)
+
+			command.SetArgs([]string{
+				"some/builder",
+				"--tag", "some/builder:fixed",
+				"--buildpack", "https://example.com/bp.tgz",
+				"-b", "some/package",
+				"--remove-buildpack", "bp.old@1.0.0,bp.unused",
+				"--order-file", orderFile,
+				"--description", "Fixed builder",
+				"--lifecycle-version", "0.5.0",
+				"--publish",
+			})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Successfully modified builder 'some/builder:fixed'")
+		})
+
+		it("replaces the run image mirrors only when provided", func() {
+			mockClient.EXPECT().ModifyBuilder(gomock.Any(), pack.ModifyBuilderOptions{
+				BuilderName:     "some/builder",
+				RunImageMirrors: []string{},
+			}).Return(nil)
+
+			command.SetArgs([]string{"some/builder", "--run-image-mirror", ""})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Successfully modified builder 'some/builder'")
+		})
+
+		when("the order file has no order", func() {
+			it("returns an error", func() {
+				orderFile := filepath.Join(tmpDir, "order.toml")
+				h.AssertNil(t, ioutil.WriteFile(orderFile, []byte(`description = "nothing"`), 0644))
+
+				command.SetArgs([]string{"some/builder", "--order-file", orderFile})
+				h.AssertError(t, command.Execute(), "does not define an order")
+			})
+		})
+	})
+}
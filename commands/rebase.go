@@ -1,6 +1,14 @@
 package commands
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/buildpack/pack/config"
@@ -11,19 +19,67 @@ import (
 )
 
 func Rebase(logger logging.Logger, cfg config.Config, client PackClient) *cobra.Command {
-	var opts pack.RebaseOptions
+	var (
+		opts         pack.RebaseOptions
+		fromFile     string
+		concurrency  int
+		outputFormat string
+	)
 	ctx := createCancellableContext()
 
 	cmd := &cobra.Command{
 		Use:   "rebase <image-name>",
-		Args:  cobra.ExactArgs(1),
 		Short: "Rebase app image with latest run image",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if fromFile != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
-			opts.RepoName = args[0]
 			opts.AdditionalMirrors = getMirrors(cfg)
-			if err := client.Rebase(ctx, opts); err != nil {
+
+			if fromFile != "" {
+				if outputFormat != "" && outputFormat != "json" {
+					return fmt.Errorf("invalid output format %s, must be %s", style.Symbol(outputFormat), style.Symbol("json"))
+				}
+
+				repoNames, err := readImageList(fromFile)
+				if err != nil {
+					return err
+				}
+
+				results := client.RebaseMany(ctx, pack.RebaseManyOptions{
+					RepoNames:     repoNames,
+					Concurrency:   concurrency,
+					RebaseOptions: opts,
+				})
+				return writeRebaseResults(logger.Writer(), results, outputFormat, opts.DryRun)
+			}
+
+			if outputFormat != "" {
+				return errors.New("--output can only be used with --from-file")
+			}
+
+			opts.RepoName = args[0]
+			report, err := client.RebaseWithReport(ctx, opts)
+			if err != nil {
+				if errors.Cause(err) == pack.ErrIncompatibleRunImage {
+					return fmt.Errorf("%s\nUse %s to rebase anyway", err, style.Symbol("--force"))
+				}
 				return err
 			}
+
+			if opts.DryRun {
+				logRebaseReport(logger, *report)
+				return nil
+			}
+
+			if !report.RebaseNeeded {
+				logger.Infof("Image %s is already up to date with run image %s", style.Symbol(opts.RepoName), style.Symbol(report.RunImage))
+				return nil
+			}
+
 			logger.Infof("Successfully rebased image %s", style.Symbol(opts.RepoName))
 			return nil
 		}),
@@ -31,6 +87,117 @@ func Rebase(logger logging.Logger, cfg config.Config, client PackClient) *cobra.
 	cmd.Flags().BoolVar(&opts.Publish, "publish", false, "Publish to registry")
 	cmd.Flags().BoolVar(&opts.SkipPull, "no-pull", false, "Skip pulling app and run images before use")
 	cmd.Flags().StringVar(&opts.RunImage, "run-image", "", "Run image to use for rebasing")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Rebase even if the run image does not match the app image's stack")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Report what would change without rebasing the image")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Rebase the images listed in a file, one per line")
+	cmd.Flags().IntVar(&concurrency, "concurrency", pack.DefaultRebaseConcurrency, "Number of images to rebase at the same time when using --from-file")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format for --from-file results: json (table if omitted)")
 	AddHelpFlag(cmd, "rebase")
 	return cmd
 }
+
+func logRebaseReport(logger logging.Logger, report pack.RebaseReport) {
+	if !report.RebaseNeeded {
+		logger.Infof("Image %s is up to date with run image %s", style.Symbol(report.Image), style.Symbol(report.RunImage))
+		return
+	}
+
+	logger.Infof("Image %s needs to be rebased on run image %s", style.Symbol(report.Image), style.Symbol(report.RunImage))
+	logger.Infof("  Old base digest: %s", valueOrNone(report.OldBaseDigest))
+	logger.Infof("  New base digest: %s", valueOrNone(report.NewBaseDigest))
+	logger.Infof("  Layers swapped:  %d removed, %d added", report.LayersRemoved, report.LayersAdded)
+}
+
+// readImageList reads image names from a file, one per line. Blank lines and lines starting with '#' are ignored.
+func readImageList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening image list")
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading image list")
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no images found in %s", style.Symbol(path))
+	}
+	return names, nil
+}
+
+type rebaseResultOutput struct {
+	Image         string `json:"image"`
+	Status        string `json:"status"`
+	OldBaseDigest string `json:"old_base_digest,omitempty"`
+	NewBaseDigest string `json:"new_base_digest,omitempty"`
+	Digest        string `json:"digest,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+func writeRebaseResults(w io.Writer, results []pack.RebaseResult, outputFormat string, dryRun bool) error {
+	var (
+		outputs []rebaseResultOutput
+		failed  int
+	)
+	for _, result := range results {
+		output := rebaseResultOutput{Image: result.RepoName}
+		switch {
+		case result.Err != nil:
+			failed++
+			output.Status = "failed"
+			output.Error = result.Err.Error()
+		case !result.Report.RebaseNeeded:
+			output.Status = "up to date"
+		case dryRun:
+			output.Status = "needs rebase"
+		default:
+			output.Status = "rebased"
+		}
+
+		if result.Report != nil {
+			output.OldBaseDigest = result.Report.OldBaseDigest
+			output.NewBaseDigest = result.Report.NewBaseDigest
+			output.Digest = result.Report.Digest
+		}
+		outputs = append(outputs, output)
+	}
+
+	if outputFormat == "json" {
+		encode, err := encoderFor(outputFormat)
+		if err != nil {
+			return err
+		}
+		if err := encode(w, outputs); err != nil {
+			return err
+		}
+	} else {
+		tabWriter := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		if _, err := fmt.Fprint(tabWriter, "IMAGE\tSTATUS\tOLD BASE DIGEST\tNEW BASE DIGEST\tERROR\n"); err != nil {
+			return err
+		}
+		for _, output := range outputs {
+			if _, err := fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n",
+				output.Image, output.Status, output.OldBaseDigest, output.NewBaseDigest, output.Error); err != nil {
+				return err
+			}
+		}
+		if err := tabWriter.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to rebase %d of %d images", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/commands"
+	cmdmocks "github.com/buildpack/pack/commands/mocks"
+	"github.com/buildpack/pack/config"
+	ilogging "github.com/buildpack/pack/internal/logging"
+	"github.com/buildpack/pack/logging"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRebaseCommand(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Commands", testRebaseCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testRebaseCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *cmdmocks.MockPackClient
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockClient = cmdmocks.NewMockPackClient(mockController)
+		logger = ilogging.NewLogWithWriters(&outBuf, &outBuf)
+		command = commands.Rebase(logger, config.Config{}, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#Rebase", func() {
+		it("rebases the image", func() {
+			mockClient.EXPECT().RebaseWithReport(gomock.Any(), pack.RebaseOptions{
+				RepoName:          "some/app",
+				AdditionalMirrors: map[string][]string{},
+			}).Return(&pack.RebaseReport{RebaseNeeded: true}, nil)
+
+			command.SetArgs([]string{"some/app"})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Successfully rebased image 'some/app'")
+		})
+
+		it("reports when the image is already up to date", func() {
+			mockClient.EXPECT().RebaseWithReport(gomock.Any(), gomock.Any()).
+				Return(&pack.RebaseReport{Image: "some/app", RunImage: "some/run"}, nil)
+
+			command.SetArgs([]string{"some/app"})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Image 'some/app' is already up to date with run image 'some/run'")
+			h.AssertNotContains(t, outBuf.String(), "Successfully rebased")
+		})
+
+		when("--dry-run", func() {
+			it("displays what would change", func() {
+				mockClient.EXPECT().RebaseWithReport(gomock.Any(), pack.RebaseOptions{
+					RepoName:          "some/app",
+					AdditionalMirrors: map[string][]string{},
+					DryRun:            true,
+				}).Return(&pack.RebaseReport{
+					Image:         "some/app",
+					RunImage:      "some/run",
+					RebaseNeeded:  true,
+					OldBaseDigest: "sha256:old",
+					NewBaseDigest: "sha256:new",
+					LayersRemoved: 1,
+					LayersAdded:   2,
+				}, nil)
+
+				command.SetArgs([]string{"some/app", "--dry-run"})
+				h.AssertNil(t, command.Execute())
+				h.AssertEq(t, outBuf.String(), `Image 'some/app' needs to be rebased on run image 'some/run'
+  Old base digest: sha256:old
+  New base digest: sha256:new
+  Layers swapped:  1 removed, 2 added
+`)
+			})
+		})
+
+		when("--from-file", func() {
+			var imageList string
+
+			it.Before(func() {
+				file, err := ioutil.TempFile("", "images")
+				h.AssertNil(t, err)
+				_, err = file.WriteString("some/app\n\n# a comment\nother/app\n")
+				h.AssertNil(t, err)
+				h.AssertNil(t, file.Close())
+				imageList = file.Name()
+			})
+
+			it.After(func() {
+				os.Remove(imageList)
+			})
+
+			it("rebases all listed images and displays a summary", func() {
+				mockClient.EXPECT().RebaseMany(gomock.Any(), pack.RebaseManyOptions{
+					RepoNames:   []string{"some/app", "other/app"},
+					Concurrency: 2,
+					RebaseOptions: pack.RebaseOptions{
+						AdditionalMirrors: map[string][]string{},
+						Publish:           true,
+					},
+				}).Return([]pack.RebaseResult{
+					{RepoName: "some/app", Report: &pack.RebaseReport{RebaseNeeded: true, OldBaseDigest: "sha256:old", NewBaseDigest: "sha256:new", Digest: "sha256:app"}},
+					{RepoName: "other/app", Err: errors.New("some error")},
+				})
+
+				command.SetArgs([]string{"--from-file", imageList, "--concurrency", "2", "--publish"})
+				h.AssertError(t, command.Execute(), "failed to rebase 1 of 2 images")
+				h.AssertContains(t, outBuf.String(), "IMAGE       STATUS    OLD BASE DIGEST   NEW BASE DIGEST   ERROR")
+				h.AssertContains(t, outBuf.String(), "some/app    rebased   sha256:old        sha256:new")
+				h.AssertContains(t, outBuf.String(), "other/app   failed                                        some error")
+			})
+
+			it("writes json when requested", func() {
+				mockClient.EXPECT().RebaseMany(gomock.Any(), gomock.Any()).Return([]pack.RebaseResult{
+					{RepoName: "some/app", Report: &pack.RebaseReport{OldBaseDigest: "sha256:same", NewBaseDigest: "sha256:same"}},
+				})
+
+				command.SetArgs([]string{"--from-file", imageList, "--output", "json"})
+				h.AssertNil(t, command.Execute())
+				h.AssertEq(t, outBuf.String(), `[
+  {
+    "image": "some/app",
+    "status": "up to date",
+    "old_base_digest": "sha256:same",
+    "new_base_digest": "sha256:same"
+  }
+]
+`)
+			})
+
+			it("does not accept an image name", func() {
+				command.SetArgs([]string{"some/app", "--from-file", imageList})
+				h.AssertError(t, command.Execute(), `unknown command "some/app" for "rebase"`)
+			})
+		})
+
+		when("the run image is incompatible", func() {
+			it("suggests --force", func() {
+				mockClient.EXPECT().RebaseWithReport(gomock.Any(), gomock.Any()).
+					Return(nil, errors.Wrap(pack.ErrIncompatibleRunImage, "run image 'some/run' has stack 'other.stack'"))
+
+				command.SetArgs([]string{"some/app"})
+				h.AssertError(t, command.Execute(), "Use '--force' to rebase anyway")
+			})
+		})
+	})
+}
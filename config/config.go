@@ -5,12 +5,19 @@ import (
 	"path/filepath"
 
 	"github.com/BurntSushi/toml"
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 )
 
 type Config struct {
 	RunImages      []RunImage `toml:"run-images"`
 	DefaultBuilder string     `toml:"default-builder-image,omitempty"`
+	// InsecureRegistries are reached without TLS verification by pack, but not by the lifecycle during a build.
+	InsecureRegistries []string `toml:"insecure-registries,omitempty"`
+	CACertsPath        string   `toml:"ca-certs,omitempty"`
+	// DownloadCacheMaxSize is the size, such as "2GB", above which the least recently used blobs are pruned from the
+	// download cache.
+	DownloadCacheMaxSize string `toml:"download-cache-max-size,omitempty"`
 }
 
 type RunImage struct {
@@ -38,6 +45,14 @@ func PackHome() (string, error) {
 	return packHome, nil
 }
 
+func DownloadCachePath() (string, error) {
+	home, err := PackHome()
+	if err != nil {
+		return "", errors.Wrap(err, "getting pack home")
+	}
+	return filepath.Join(home, "download-cache"), nil
+}
+
 func Read(path string) (Config, error) {
 	cfg := Config{}
 	_, err := toml.DecodeFile(path, &cfg)
@@ -45,6 +60,10 @@ func Read(path string) (Config, error) {
 		return Config{}, errors.Wrapf(err, "failed to read config file at path %s", path)
 	}
 
+	if cfg.CACertsPath != "" && !filepath.IsAbs(cfg.CACertsPath) {
+		cfg.CACertsPath = filepath.Join(filepath.Dir(path), cfg.CACertsPath)
+	}
+
 	return cfg, nil
 }
 
@@ -75,3 +94,15 @@ func SetRunImageMirrors(cfg Config, image string, mirrors []string) Config {
 	cfg.RunImages = append(cfg.RunImages, RunImage{Image: image, Mirrors: mirrors})
 	return cfg
 }
+
+// DownloadCacheMaxSize returns the download cache limit of cfg in bytes, or 0 when there is none.
+func DownloadCacheMaxSize(cfg Config) (int64, error) {
+	if cfg.DownloadCacheMaxSize == "" {
+		return 0, nil
+	}
+	size, err := units.FromHumanSize(cfg.DownloadCacheMaxSize)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid download-cache-max-size %s", cfg.DownloadCacheMaxSize)
+	}
+	return size, nil
+}
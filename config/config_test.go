@@ -48,6 +48,27 @@ func testConfig(t *testing.T, when spec.G, it spec.S) {
 				h.AssertEq(t, len(subject.RunImages), 0)
 			})
 		})
+
+		when("config has registry settings", func() {
+			it.Before(func() {
+				h.AssertNil(t, ioutil.WriteFile(configPath, []byte(`
+insecure-registries = ["registry.local:5000", "other.local"]
+ca-certs = "certs/ca.pem"
+`), 0777))
+			})
+
+			it("reads the insecure registries", func() {
+				subject, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, subject.InsecureRegistries, []string{"registry.local:5000", "other.local"})
+			})
+
+			it("resolves the ca-certs path relative to the config file", func() {
+				subject, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, subject.CACertsPath, filepath.Join(tmpDir, "certs", "ca.pem"))
+			})
+		})
 	})
 
 	when("#Write", func() {
@@ -170,4 +191,23 @@ func testConfig(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 	})
+
+	when("#DownloadCacheMaxSize", func() {
+		it("parses the configured size", func() {
+			size, err := config.DownloadCacheMaxSize(config.Config{DownloadCacheMaxSize: "2GB"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, size, int64(2000000000))
+		})
+
+		it("returns 0 when no size is configured", func() {
+			size, err := config.DownloadCacheMaxSize(config.Config{})
+			h.AssertNil(t, err)
+			h.AssertEq(t, size, int64(0))
+		})
+
+		it("fails for an invalid size", func() {
+			_, err := config.DownloadCacheMaxSize(config.Config{DownloadCacheMaxSize: "lots"})
+			h.AssertError(t, err, "invalid download-cache-max-size lots")
+		})
+	})
 }
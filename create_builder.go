@@ -8,7 +8,9 @@ import (
 	"github.com/buildpack/imgutil"
 	"github.com/pkg/errors"
 
+	"github.com/buildpack/pack/blob"
 	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/buildpackage"
 	"github.com/buildpack/pack/dist"
 	"github.com/buildpack/pack/image"
 	"github.com/buildpack/pack/style"
@@ -19,9 +21,33 @@ type CreateBuilderOptions struct {
 	BuilderConfig builder.Config
 	Publish       bool
 	NoPull        bool
+	// Lint fails the creation of the builder when buildpacks that are not part of a buildpackage have lint errors.
+	Lint bool
 }
 
 func (c *Client) CreateBuilder(ctx context.Context, opts CreateBuilderOptions) error {
+	var (
+		baseImage   imgutil.Image
+		baseBuilder *builder.Builder
+		err         error
+	)
+	if opts.BuilderConfig.Base.Builder != "" {
+		// the image of the base builder is used as the build image, so its layers are reused as they are
+		baseImage, err = c.imageFetcher.Fetch(ctx, opts.BuilderConfig.Base.Builder, !opts.Publish, !opts.NoPull)
+		if err != nil {
+			return errors.Wrapf(err, "fetching base builder %s", style.Symbol(opts.BuilderConfig.Base.Builder))
+		}
+
+		baseBuilder, err = builder.GetBuilder(baseImage)
+		if err != nil {
+			return errors.Wrapf(err, "invalid base builder %s", style.Symbol(opts.BuilderConfig.Base.Builder))
+		}
+
+		if opts.BuilderConfig, err = inheritBaseBuilder(opts.BuilderConfig, baseBuilder); err != nil {
+			return errors.Wrap(err, "invalid builder config")
+		}
+	}
+
 	if err := validateBuilderConfig(opts.BuilderConfig); err != nil {
 		return errors.Wrap(err, "invalid builder config")
 	}
@@ -30,9 +56,16 @@ func (c *Client) CreateBuilder(ctx context.Context, opts CreateBuilderOptions) e
 		return err
 	}
 
-	baseImage, err := c.imageFetcher.Fetch(ctx, opts.BuilderConfig.Stack.BuildImage, !opts.Publish, !opts.NoPull)
+	if baseImage == nil {
+		baseImage, err = c.imageFetcher.Fetch(ctx, opts.BuilderConfig.Stack.BuildImage, !opts.Publish, !opts.NoPull)
+		if err != nil {
+			return err
+		}
+	}
+
+	buildImageDigest, err := baseImage.Digest()
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "reading digest of build-image %s", style.Symbol(baseImage.Name()))
 	}
 
 	c.logger.Debugf("Creating builder %s from build-image %s", style.Symbol(opts.BuilderName), style.Symbol(baseImage.Name()))
@@ -51,13 +84,36 @@ func (c *Client) CreateBuilder(ctx context.Context, opts CreateBuilderOptions) e
 		)
 	}
 
-	lifecycle, err := c.fetchLifecycle(ctx, opts.BuilderConfig.Lifecycle)
-	if err != nil {
-		return errors.Wrap(err, "fetch lifecycle")
+	provenance := builder.Provenance{
+		BuildImage: builder.ImageProvenance{Name: opts.BuilderConfig.Stack.BuildImage, Digest: buildImageDigest},
+		Buildpacks: []builder.BuildpackProvenance{},
+		Parameters: builder.ProvenanceParameters{
+			BuilderName: opts.BuilderName,
+			Publish:     opts.Publish,
+			NoPull:      opts.NoPull,
+		},
+	}
+	if baseBuilder != nil && baseBuilder.GetProvenance() != nil {
+		provenance.Lifecycle = baseBuilder.GetProvenance().Lifecycle
+		provenance.Buildpacks = append(provenance.Buildpacks, baseBuilder.GetProvenance().Buildpacks...)
 	}
 
-	if err := builderImage.SetLifecycle(lifecycle); err != nil {
-		return errors.Wrap(err, "setting lifecycle")
+	lifecycleConfig := opts.BuilderConfig.Lifecycle
+	if baseBuilder == nil || lifecycleConfig.URI != "" || lifecycleConfig.Version != "" || len(lifecycleConfig.URIs) > 0 {
+		platform, err := c.platformFetcher.FetchPlatform(ctx, opts.BuilderConfig.Stack.BuildImage, !opts.Publish)
+		if err != nil {
+			return errors.Wrapf(err, "reading platform of build-image %s", style.Symbol(opts.BuilderConfig.Stack.BuildImage))
+		}
+
+		lifecycle, lifecycleProvenance, err := c.fetchLifecycle(ctx, lifecycleConfig, platform)
+		if err != nil {
+			return errors.Wrap(err, "fetch lifecycle")
+		}
+
+		if err := builderImage.SetLifecycle(lifecycle); err != nil {
+			return errors.Wrap(err, "setting lifecycle")
+		}
+		provenance.Lifecycle = lifecycleProvenance
 	}
 
 	for _, b := range opts.BuilderConfig.Buildpacks {
@@ -66,30 +122,141 @@ func (c *Client) CreateBuilder(ctx context.Context, opts CreateBuilderOptions) e
 			return err
 		}
 
-		blob, err := c.downloader.Download(ctx, b.URI)
+		blob, err := c.downloader.Download(ctx, blob.WithSHA256(b.URI, b.SHA256))
 		if err != nil {
 			return errors.Wrapf(err, "downloading buildpack from %s", style.Symbol(b.URI))
 		}
 
-		fetchedBp, err := dist.NewBuildpack(blob)
+		if opts.Lint && !buildpackage.IsFile(b.URI) {
+			lintOpts := dist.LintOptions{BuildpackAPI: builderImage.GetLifecycleDescriptor().API.BuildpackVersion}
+			if err := c.enforceLint(b.URI, blob, lintOpts); err != nil {
+				return err
+			}
+		}
+
+		fetchedBps, err := buildpacksFromBlob(b.URI, blob)
 		if err != nil {
-			return errors.Wrap(err, "creating buildpack")
+			return err
 		}
 
-		err = validateBuildpack(fetchedBp, b.URI, b.ID, b.Version)
+		// the buildpack of a buildpackage file that is checked against the config is its default buildpack
+		err = validateBuildpack(fetchedBps[0], b.URI, b.ID, b.Version)
 		if err != nil {
 			return errors.Wrap(err, "invalid buildpack")
 		}
 
-		builderImage.AddBuildpack(fetchedBp)
+		source, err := sourceProvenance(b.URI, blob)
+		if err != nil {
+			return errors.Wrapf(err, "computing digest of buildpack from %s", style.Symbol(b.URI))
+		}
+
+		for _, fetchedBp := range fetchedBps {
+			builderImage.AddBuildpack(fetchedBp)
+			provenance.Buildpacks = addBuildpackProvenance(provenance.Buildpacks, fetchedBp.Descriptor().Info, source)
+		}
+	}
+
+	order := opts.BuilderConfig.Order
+	if baseBuilder != nil {
+		if order, err = builder.MergeOrder(builderImage.GetOrder(), order, opts.BuilderConfig.Base.OrderMerge); err != nil {
+			return errors.Wrap(err, "merging order with base builder")
+		}
 	}
 
-	builderImage.SetOrder(opts.BuilderConfig.Order)
+	builderImage.SetOrder(order)
 	builderImage.SetStackInfo(opts.BuilderConfig.Stack)
+	if opts.BuilderConfig.Compat != nil {
+		builderImage.SetCompat(*opts.BuilderConfig.Compat)
+	}
+	builderImage.SetProvenance(provenance)
 
 	return builderImage.Save(c.logger)
 }
 
+// inheritBaseBuilder returns config with the stack and description of the base builder filled in where config
+// omits them. The base builder is used as the build image.
+func inheritBaseBuilder(config builder.Config, base *builder.Builder) (builder.Config, error) {
+	if config.Stack.BuildImage != "" {
+		return builder.Config{}, errors.Errorf(
+			"%s and %s cannot both be set",
+			style.Symbol("base.builder"), style.Symbol("stack.build-image"),
+		)
+	}
+	config.Stack.BuildImage = config.Base.Builder
+
+	if config.Stack.ID == "" {
+		config.Stack.ID = base.StackID
+	}
+
+	if config.Stack.RunImage == "" {
+		config.Stack.RunImage = base.GetStackInfo().RunImage.Image
+		config.Stack.RunImageMirrors = base.GetStackInfo().RunImage.Mirrors
+	}
+
+	if config.Description == "" {
+		config.Description = base.Description()
+	}
+
+	return config, nil
+}
+
+func withoutBuildpackProvenance(buildpacks []builder.BuildpackProvenance, info dist.BuildpackInfo) []builder.BuildpackProvenance {
+	var filtered []builder.BuildpackProvenance
+	for _, bp := range buildpacks {
+		if bp.BuildpackInfo != info {
+			filtered = append(filtered, bp)
+		}
+	}
+	return filtered
+}
+
+// addBuildpackProvenance records that the buildpack info comes from source, replacing what was recorded for it.
+func addBuildpackProvenance(buildpacks []builder.BuildpackProvenance, info dist.BuildpackInfo, source builder.SourceProvenance) []builder.BuildpackProvenance {
+	return append(withoutBuildpackProvenance(buildpacks, info), builder.BuildpackProvenance{
+		BuildpackInfo: info,
+		URI:           source.URI,
+		Digest:        source.Digest,
+		ETag:          source.ETag,
+	})
+}
+
+// sourceProvenance returns the provenance of a blob downloaded from uri.
+func sourceProvenance(uri string, b blob.Blob) (builder.SourceProvenance, error) {
+	digest, err := builder.BlobDigest(b)
+	if err != nil {
+		return builder.SourceProvenance{}, err
+	}
+	return builder.SourceProvenance{URI: uri, Digest: digest, ETag: blob.ETag(b)}, nil
+}
+
+// buildpacksFromBlob returns the buildpack in blob or, when uri refers to a buildpackage file, all of its buildpacks
+// starting with the default one.
+func buildpacksFromBlob(uri string, blob dist.Blob) ([]dist.Buildpack, error) {
+	if !buildpackage.IsFile(uri) {
+		bp, err := dist.NewBuildpack(blob)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating buildpack")
+		}
+		return []dist.Buildpack{bp}, nil
+	}
+
+	md, bps, err := readBuildpackageFile(uri, blob)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, bp := range bps {
+		if bp.Descriptor().Info == md.BuildpackInfo {
+			return append([]dist.Buildpack{bp}, append(bps[:i:i], bps[i+1:]...)...), nil
+		}
+	}
+	return nil, errors.Errorf(
+		"default buildpack %s is not contained in buildpackage file %s",
+		style.Symbol(md.ID+"@"+md.Version),
+		style.Symbol(uri),
+	)
+}
+
 func validateBuildpack(bp dist.Buildpack, source, expectedID, expectedBPVersion string) error {
 	if expectedID != "" && bp.Descriptor().Info.ID != expectedID {
 		return fmt.Errorf(
@@ -112,47 +279,93 @@ func validateBuildpack(bp dist.Buildpack, source, expectedID, expectedBPVersion
 	return nil
 }
 
-func (c *Client) fetchLifecycle(ctx context.Context, config builder.LifecycleConfig) (builder.Lifecycle, error) {
+// fetchLifecycle returns the lifecycle for platform along with the URI it was downloaded from.
+func (c *Client) fetchLifecycle(ctx context.Context, config builder.LifecycleConfig, platform image.Platform) (builder.Lifecycle, builder.SourceProvenance, error) {
 	if config.Version != "" && config.URI != "" {
-		return nil, errors.Errorf(
+		return nil, builder.SourceProvenance{}, errors.Errorf(
 			"%s can only declare %s or %s, not both",
 			style.Symbol("lifecycle"), style.Symbol("version"), style.Symbol("uri"),
 		)
 	}
 
-	var uri string
+	var uri, digest string
 	switch {
+	case config.URIs[platform.String()] != "":
+		uri = config.URIs[platform.String()]
 	case config.Version != "":
 		v, err := semver.NewVersion(config.Version)
 		if err != nil {
-			return nil, errors.Wrapf(err, "%s must be a valid semver", style.Symbol("lifecycle.version"))
+			return nil, builder.SourceProvenance{}, errors.Wrapf(err, "%s must be a valid semver", style.Symbol("lifecycle.version"))
 		}
 
-		uri = uriFromLifecycleVersion(*v)
+		uri, err = uriFromLifecycleVersion(*v, platform)
+		if err != nil {
+			return nil, builder.SourceProvenance{}, err
+		}
 	case config.URI != "":
-		uri = config.URI
+		uri, digest = config.URI, config.SHA256
+	case len(config.URIs) > 0:
+		return nil, builder.SourceProvenance{}, errors.Errorf("%s does not declare a URI for platform %s", style.Symbol("lifecycle.uris"), style.Symbol(platform.String()))
 	default:
-		uri = uriFromLifecycleVersion(*semver.MustParse(builder.DefaultLifecycleVersion))
+		var err error
+		uri, err = uriFromLifecycleVersion(*semver.MustParse(builder.DefaultLifecycleVersion), platform)
+		if err != nil {
+			return nil, builder.SourceProvenance{}, err
+		}
 	}
 
-	b, err := c.downloader.Download(ctx, uri)
+	c.logger.Debugf("Using lifecycle %s for platform %s", style.Symbol(uri), style.Symbol(platform.String()))
+	b, err := c.downloader.Download(ctx, blob.WithSHA256(uri, digest))
 	if err != nil {
-		return nil, errors.Wrap(err, "downloading lifecycle")
+		return nil, builder.SourceProvenance{}, errors.Wrap(err, "downloading lifecycle")
 	}
 
 	lifecycle, err := builder.NewLifecycle(b)
 	if err != nil {
-		return nil, errors.Wrap(err, "invalid lifecycle")
+		return nil, builder.SourceProvenance{}, errors.Wrap(err, "invalid lifecycle")
+	}
+
+	if err := builder.ValidateLifecyclePlatform(lifecycle, platform.OS, platform.Architecture); err != nil {
+		return nil, builder.SourceProvenance{}, errors.Wrapf(err, "invalid lifecycle from %s", style.Symbol(uri))
+	}
+
+	source, err := sourceProvenance(uri, b)
+	if err != nil {
+		return nil, builder.SourceProvenance{}, errors.Wrap(err, "computing lifecycle digest")
 	}
 
-	return lifecycle, nil
+	return lifecycle, source, nil
+}
+
+// lifecycleArchitectures maps image architectures to the names used in lifecycle release artifacts.
+var lifecycleArchitectures = map[string]string{
+	"amd64": "x86-64",
+	"arm64": "arm64",
 }
 
-func uriFromLifecycleVersion(version semver.Version) string {
-	return fmt.Sprintf("https://github.com/buildpack/lifecycle/releases/download/v%s/lifecycle-v%s+linux.x86-64.tgz", version.String(), version.String())
+func uriFromLifecycleVersion(version semver.Version, platform image.Platform) (string, error) {
+	arch, ok := lifecycleArchitectures[platform.Architecture]
+	if !ok || platform.OS != "linux" {
+		return "", errors.Errorf(
+			"no lifecycle release is available for platform %s, provide %s or %s",
+			style.Symbol(platform.String()),
+			style.Symbol("lifecycle.uri"),
+			style.Symbol("lifecycle.uris"),
+		)
+	}
+
+	return fmt.Sprintf("https://github.com/buildpack/lifecycle/releases/download/v%s/lifecycle-v%s+%s.%s.tgz", version.String(), version.String(), platform.OS, arch), nil
 }
 
 func validateBuilderConfig(conf builder.Config) error {
+	if conf.Base.OrderMerge != "" && conf.Base.Builder == "" {
+		return errors.Errorf("%s requires %s", style.Symbol("base.order-merge"), style.Symbol("base.builder"))
+	}
+
+	if _, err := builder.MergeOrder(nil, nil, conf.Base.OrderMerge); err != nil {
+		return err
+	}
+
 	if conf.Stack.ID == "" {
 		return errors.New("stack.id is required")
 	}
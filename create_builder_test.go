@@ -20,8 +20,10 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
+	"github.com/buildpack/pack/api"
 	"github.com/buildpack/pack/blob"
 	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/image"
 	ifakes "github.com/buildpack/pack/internal/fakes"
 	"github.com/buildpack/pack/logging"
 	h "github.com/buildpack/pack/testhelpers"
@@ -39,6 +41,8 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 		var (
 			mockController     *gomock.Controller
 			mockDownloader     *testmocks.MockDownloader
+			mockPlatform       *testmocks.MockPlatformFetcher
+			platform           image.Platform
 			imageFetcher       *ifakes.FakeImageFetcher
 			fakeBuildImage     *fakes.Image
 			fakeRunImage       *fakes.Image
@@ -54,6 +58,11 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			logger = ifakes.NewFakeLogger(&out)
 			mockController = gomock.NewController(t)
 			mockDownloader = testmocks.NewMockDownloader(mockController)
+			mockPlatform = testmocks.NewMockPlatformFetcher(mockController)
+			platform = image.Platform{OS: "linux", Architecture: "amd64"}
+			mockPlatform.EXPECT().FetchPlatform(gomock.Any(), "some/build-image", gomock.Any()).DoAndReturn(
+				func(context.Context, string, bool) (image.Platform, error) { return platform, nil },
+			).AnyTimes()
 
 			fakeBuildImage = fakes.NewImage("some/build-image", "", "")
 			h.AssertNil(t, fakeBuildImage.SetLabel("io.buildpacks.stack.id", "some.stack.id"))
@@ -71,14 +80,15 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			imageFetcher.LocalImages["some/run-image"] = fakeRunImage
 			imageFetcher.RemoteImages["localhost:5000/some-run-image"] = fakeRunImageMirror
 
-			mockDownloader.EXPECT().Download(gomock.Any(), "https://example.fake/bp-one.tgz").Return(blob.NewBlob(filepath.Join("testdata", "buildpack")), nil).AnyTimes()
+			mockDownloader.EXPECT().Download(gomock.Any(), "https://example.fake/bp-one.tgz").Return(&etagBlob{Blob: blob.NewBlob(filepath.Join("testdata", "buildpack")), etag: `"bp-one"`}, nil).AnyTimes()
 			mockDownloader.EXPECT().Download(gomock.Any(), "some/buildpack/dir").Return(blob.NewBlob(filepath.Join("testdata", "buildpack")), nil).AnyTimes()
 			mockDownloader.EXPECT().Download(gomock.Any(), "file:///some-lifecycle").Return(blob.NewBlob(filepath.Join("testdata", "lifecycle")), nil).AnyTimes()
 
 			subject = &Client{
-				logger:       logger,
-				imageFetcher: imageFetcher,
-				downloader:   mockDownloader,
+				logger:          logger,
+				imageFetcher:    imageFetcher,
+				platformFetcher: mockPlatform,
+				downloader:      mockDownloader,
 			}
 
 			opts = CreateBuilderOptions{
@@ -252,6 +262,85 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("the build image is not linux/amd64", func() {
+			it.Before(func() {
+				platform = image.Platform{OS: "linux", Architecture: "arm64"}
+				opts.BuilderConfig.Lifecycle.URI = ""
+			})
+
+			it("downloads the lifecycle release for the platform of the build image", func() {
+				opts.BuilderConfig.Lifecycle.Version = "3.4.5"
+				mockDownloader.EXPECT().Download(
+					gomock.Any(),
+					"https://github.com/buildpack/lifecycle/releases/download/v3.4.5/lifecycle-v3.4.5+linux.arm64.tgz",
+				).Return(
+					blob.NewBlob(filepath.Join("testdata", "lifecycle")), nil,
+				).MinTimes(1)
+
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+			})
+
+			it("prefers the lifecycle URI declared for the platform", func() {
+				opts.BuilderConfig.Lifecycle.Version = "3.4.5"
+				opts.BuilderConfig.Lifecycle.URIs = map[string]string{
+					"linux/amd64": "https://example.fake/lifecycle-amd64.tgz",
+					"linux/arm64": "file:///some-lifecycle",
+				}
+
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+			})
+
+			it("fails when no lifecycle URI is declared for the platform", func() {
+				opts.BuilderConfig.Lifecycle.URIs = map[string]string{"linux/amd64": "https://example.fake/lifecycle-amd64.tgz"}
+
+				h.AssertError(t, subject.CreateBuilder(context.TODO(), opts), "'lifecycle.uris' does not declare a URI for platform 'linux/arm64'")
+			})
+
+			it("fails when there is no lifecycle release for the platform", func() {
+				platform = image.Platform{OS: "linux", Architecture: "s390x"}
+
+				h.AssertError(t, subject.CreateBuilder(context.TODO(), opts), "no lifecycle release is available for platform 'linux/s390x'")
+			})
+
+			it("fails when the lifecycle binaries are built for another platform", func() {
+				lifecycleDir := filepath.Join(tmpDir, "lifecycle")
+				h.AssertNil(t, os.MkdirAll(filepath.Join(lifecycleDir, "lifecycle"), 0755))
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(lifecycleDir, "lifecycle.toml"), []byte(`
+[api]
+  platform = "0.2"
+  buildpack = "0.3"
+
+[lifecycle]
+  version = "3.4.5"
+`), 0644))
+				elfHeader := make([]byte, 64)
+				copy(elfHeader, "\x7fELF\x02\x01\x01")
+				elfHeader[18] = 0x3e // EM_X86_64
+				for _, binary := range []string{"detector", "restorer", "analyzer", "builder", "exporter", "cacher", "launcher"} {
+					h.AssertNil(t, ioutil.WriteFile(filepath.Join(lifecycleDir, "lifecycle", binary), elfHeader, 0755))
+				}
+
+				opts.BuilderConfig.Lifecycle.URI = "file:///amd64-lifecycle"
+				mockDownloader.EXPECT().Download(gomock.Any(), "file:///amd64-lifecycle").Return(blob.NewBlob(lifecycleDir), nil)
+
+				h.AssertError(t, subject.CreateBuilder(context.TODO(), opts), "is built for 'linux/amd64' but the build image is 'linux/arm64'")
+			})
+		})
+
+		it("passes the declared checksums to the downloader", func() {
+			opts.BuilderConfig.Buildpacks[0].URI = "https://example.fake/bp-checked.tgz"
+			opts.BuilderConfig.Buildpacks[0].SHA256 = "bp-digest"
+			opts.BuilderConfig.Lifecycle = builder.LifecycleConfig{URI: "file:///checked-lifecycle", SHA256: "lifecycle-digest"}
+			mockDownloader.EXPECT().Download(gomock.Any(), "https://example.fake/bp-checked.tgz#sha256=bp-digest").Return(blob.NewBlob(filepath.Join("testdata", "buildpack")), nil)
+			mockDownloader.EXPECT().Download(gomock.Any(), "file:///checked-lifecycle#sha256=lifecycle-digest").Return(blob.NewBlob(filepath.Join("testdata", "lifecycle")), nil)
+
+			h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+			builderImage, err := builder.GetBuilder(fakeBuildImage)
+			h.AssertNil(t, err)
+			h.AssertEq(t, builderImage.GetProvenance().Lifecycle.URI, "file:///checked-lifecycle")
+		})
+
 		it("should create a new builder image", func() {
 			err := subject.CreateBuilder(context.TODO(), opts)
 			h.AssertNil(t, err)
@@ -291,6 +380,155 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			assertTarHasFile(t, layerTar, "/cnb/lifecycle/launcher")
 		})
 
+		it("records the provenance of the builder", func() {
+			h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+			builderImage, err := builder.GetBuilder(fakeBuildImage)
+			h.AssertNil(t, err)
+
+			provenance := builderImage.GetProvenance()
+			h.AssertNotNil(t, provenance)
+			h.AssertEq(t, provenance.BuildImage.Name, "some/build-image")
+			h.AssertEq(t, provenance.Lifecycle.URI, "file:///some-lifecycle")
+			h.AssertContains(t, provenance.Lifecycle.Digest, "sha256:")
+			h.AssertEq(t, len(provenance.Buildpacks), 1)
+			h.AssertEq(t, provenance.Buildpacks[0].BuildpackInfo, dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"})
+			h.AssertEq(t, provenance.Buildpacks[0].URI, "https://example.fake/bp-one.tgz")
+			h.AssertContains(t, provenance.Buildpacks[0].Digest, "sha256:")
+			h.AssertEq(t, provenance.Buildpacks[0].ETag, `"bp-one"`)
+			h.AssertEq(t, provenance.Buildpacks[0].LayerDigest, builderImage.GetBuildpackLayers()["bp.one"]["1.2.3"].LayerDigest)
+			h.AssertEq(t, provenance.Parameters, builder.ProvenanceParameters{BuilderName: "some/builder"})
+		})
+
+		when("a base builder is declared", func() {
+			var baseBuilderImage *fakes.Image
+
+			vendorBp := dist.BuildpackInfo{ID: "bp.vendor", Version: "1.0.0"}
+			bpOne := dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"}
+
+			it.Before(func() {
+				baseBuilderImage = ifakes.NewFakeBuilderImage(t, "vendor/builder", "some.stack.id", "1234", "4321", builder.Metadata{
+					Description: "Vendor description",
+					Buildpacks:  []builder.BuildpackMetadata{{BuildpackInfo: vendorBp, Latest: true}},
+					Stack: builder.StackMetadata{RunImage: builder.RunImageMetadata{
+						Image:   "some/run-image",
+						Mirrors: []string{"localhost:5000/some-run-image"},
+					}},
+					Lifecycle: builder.LifecycleMetadata{
+						LifecycleInfo: builder.LifecycleInfo{Version: builder.VersionMustParse("3.4.5")},
+						API: builder.LifecycleAPI{
+							BuildpackVersion: api.MustParse("0.3"),
+							PlatformVersion:  api.MustParse("0.2"),
+						},
+					},
+				})
+				h.AssertNil(t, baseBuilderImage.SetLabel("io.buildpacks.buildpack.layers", `{"bp.vendor": {"1.0.0": {"layerDigest": "sha256:vendor"}}}`))
+				h.AssertNil(t, baseBuilderImage.SetLabel("io.buildpacks.buildpack.order", `[{"group": [{"id": "bp.vendor", "version": "1.0.0"}]}]`))
+				imageFetcher.LocalImages["vendor/builder"] = baseBuilderImage
+
+				opts.BuilderConfig = builder.Config{
+					Base: builder.BaseConfig{Builder: "vendor/builder"},
+					Buildpacks: []builder.BuildpackConfig{{
+						BuildpackInfo: bpOne,
+						URI:           "https://example.fake/bp-one.tgz",
+					}},
+					Order: dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: bpOne}}}},
+				}
+			})
+
+			it.After(func() {
+				baseBuilderImage.Cleanup()
+			})
+
+			vendorGroup := dist.OrderEntry{Group: []dist.BuildpackRef{{BuildpackInfo: vendorBp}}}
+			bpOneGroup := dist.OrderEntry{Group: []dist.BuildpackRef{{BuildpackInfo: bpOne}}}
+
+			it("inherits the buildpacks, stack and lifecycle of the base builder and appends the order", func() {
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				bldr, err := builder.GetBuilder(baseBuilderImage)
+				h.AssertNil(t, err)
+				h.AssertEq(t, bldr.Name(), "some/builder")
+				h.AssertEq(t, bldr.Description(), "Vendor description")
+				h.AssertEq(t, bldr.GetStackInfo().RunImage.Image, "some/run-image")
+				h.AssertEq(t, bldr.GetStackInfo().RunImage.Mirrors, []string{"localhost:5000/some-run-image"})
+				h.AssertEq(t, bldr.GetBuildpacks(), []builder.BuildpackMetadata{
+					{BuildpackInfo: vendorBp, Latest: true},
+					{BuildpackInfo: bpOne, Latest: true},
+				})
+				h.AssertEq(t, bldr.GetOrder(), dist.Order{vendorGroup, bpOneGroup})
+				h.AssertEq(t, bldr.GetBuildpackLayers()["bp.vendor"]["1.0.0"].LayerDigest, "sha256:vendor")
+				h.AssertEq(t, bldr.GetLifecycleDescriptor().Info.Version.String(), "3.4.5")
+
+				_, err = baseBuilderImage.FindLayerWithPath("/cnb/lifecycle")
+				h.AssertError(t, err, "Could not find")
+				h.AssertEq(t, fakeBuildImage.IsSaved(), false)
+			})
+
+			it("prepends the order", func() {
+				opts.BuilderConfig.Base.OrderMerge = "prepend"
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				bldr, err := builder.GetBuilder(baseBuilderImage)
+				h.AssertNil(t, err)
+				h.AssertEq(t, bldr.GetOrder(), dist.Order{bpOneGroup, vendorGroup})
+			})
+
+			it("replaces the order", func() {
+				opts.BuilderConfig.Base.OrderMerge = "replace"
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				bldr, err := builder.GetBuilder(baseBuilderImage)
+				h.AssertNil(t, err)
+				h.AssertEq(t, bldr.GetOrder(), dist.Order{bpOneGroup})
+				h.AssertEq(t, len(bldr.GetBuildpacks()), 2)
+			})
+
+			it("replaces the lifecycle when one is declared", func() {
+				opts.BuilderConfig.Lifecycle = builder.LifecycleConfig{URI: "file:///some-lifecycle"}
+				mockPlatform.EXPECT().FetchPlatform(gomock.Any(), "vendor/builder", true).Return(image.Platform{OS: "linux", Architecture: "amd64"}, nil)
+
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				layerTar, err := baseBuilderImage.FindLayerWithPath("/cnb/lifecycle")
+				h.AssertNil(t, err)
+				assertTarHasFile(t, layerTar, "/cnb/lifecycle/detector")
+			})
+
+			it("fails when the build image is also declared", func() {
+				opts.BuilderConfig.Stack.BuildImage = "some/build-image"
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "'base.builder' and 'stack.build-image' cannot both be set")
+			})
+
+			it("fails when the order merge strategy is unknown", func() {
+				opts.BuilderConfig.Base.OrderMerge = "shuffle"
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "unknown order merge strategy 'shuffle', must be one of 'append', 'prepend' or 'replace'")
+			})
+
+			it("fails when the base builder is not a builder", func() {
+				opts.BuilderConfig.Base.Builder = "some/build-image"
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "invalid base builder 'some/build-image'")
+			})
+		})
+
+		when("compat is enabled in the builder config", func() {
+			it("adds the compat layer even though the lifecycle does not require it", func() {
+				compat := true
+				opts.BuilderConfig.Compat = &compat
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				builderImage, err := builder.GetBuilder(fakeBuildImage)
+				h.AssertNil(t, err)
+				h.AssertEq(t, builderImage.CompatPaths(), []string{"/buildpacks", "/buildpacks/stack.toml", "/lifecycle"})
+
+				_, err = fakeBuildImage.FindLayerWithPath("/lifecycle")
+				h.AssertNil(t, err)
+			})
+		})
+
 		when("windows", func() {
 			it.Before(func() {
 				h.SkipIf(t, runtime.GOOS != "windows", "Skipped on non-windows")
@@ -321,6 +559,16 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 	})
 }
 
+// etagBlob is a blob downloaded over HTTP with an ETag.
+type etagBlob struct {
+	blob.Blob
+	etag string
+}
+
+func (b *etagBlob) ETag() string {
+	return b.etag
+}
+
 func assertTarHasFile(t *testing.T, tarFile, path string) {
 	t.Helper()
 
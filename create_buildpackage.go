@@ -2,26 +2,123 @@ package pack
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 
 	"github.com/pkg/errors"
 
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/builder"
 	"github.com/buildpack/pack/buildpackage"
 	"github.com/buildpack/pack/dist"
 	"github.com/buildpack/pack/style"
 )
 
+const (
+	// FormatImage writes the buildpackage to the docker daemon, or to a registry when publishing.
+	FormatImage = "image"
+	// FormatFile writes the buildpackage to a file with the buildpackage.FileExtension.
+	FormatFile = "file"
+)
+
 type CreatePackageOptions struct {
+	// Name is the name of the buildpackage image or, for FormatFile, the path of the buildpackage file.
 	Name    string
 	Config  buildpackage.Config
 	Publish bool
+	// Format is either FormatImage, the default, or FormatFile.
+	Format string
+	// NoPull selects whether buildpackage images declared as dependencies are only looked up in the docker daemon
+	// rather than pulled.
+	NoPull bool
+	// Lint fails the creation of the package when buildpacks provided by blobs have lint errors.
+	Lint bool
+}
+
+// dependencyLayer is the layer of a buildpackage dependency that a buildpack is copied from.
+type dependencyLayer struct {
+	image  buildpackageImage
+	diffID string
+}
+
+// packageImage is the image or file a buildpackage is written to.
+type packageImage interface {
+	SetLabel(string, string) error
+	AddLayer(path string) error
+	Save() (string, error)
 }
 
 func (c *Client) CreatePackage(ctx context.Context, opts CreatePackageOptions) error {
-	image, err := c.imageFactory.NewImage(opts.Name, !opts.Publish)
+	if err := validatePackageFormat(opts); err != nil {
+		return err
+	}
+
+	var bps []dist.Buildpack
+	for _, bc := range opts.Config.Blobs {
+		blob, err := c.downloader.Download(ctx, blob.WithSHA256(bc.URI, bc.SHA256))
+		if err != nil {
+			return errors.Wrapf(err, "downloading buildpack from %s", style.Symbol(bc.URI))
+		}
+
+		if buildpackage.IsFile(bc.URI) {
+			_, packageBPs, err := readBuildpackageFile(bc.URI, blob)
+			if err != nil {
+				return err
+			}
+			bps = append(bps, packageBPs...)
+			continue
+		}
+
+		if opts.Lint {
+			if err := c.enforceLint(bc.URI, blob, dist.LintOptions{}); err != nil {
+				return err
+			}
+		}
+
+		bp, err := dist.NewBuildpack(blob)
+		if err != nil {
+			return errors.Wrapf(err, "creating buildpack from %s", style.Symbol(bc.URI))
+		}
+		bps = append(bps, bp)
+	}
+
+	dependencyLayers := map[dist.BuildpackInfo]dependencyLayer{}
+	for _, dep := range opts.Config.Dependencies {
+		name, img, err := c.fetchPackageDependency(ctx, dep, !opts.Publish, !opts.NoPull)
+		if err != nil {
+			return err
+		}
+
+		_, depBPs, err := readBuildpackage(name, img)
+		if err != nil {
+			return err
+		}
+
+		var depLayers builder.BuildpackLayers
+		if _, err := dist.GetLabel(img, builder.BuildpackLayersLabel, &depLayers); err != nil {
+			return err
+		}
+
+		for _, bp := range depBPs {
+			bpInfo := bp.Descriptor().Info
+			if _, ok := dependencyLayers[bpInfo]; ok || containsBuildpack(bps, bpInfo) {
+				continue
+			}
+
+			dependencyLayers[bpInfo] = dependencyLayer{image: img, diffID: depLayers[bpInfo.ID][bpInfo.Version].LayerDigest}
+			bps = append(bps, bp)
+		}
+	}
+
+	if err := validatePackage(opts.Config, bps); err != nil {
+		return errors.Wrap(err, "invalid package")
+	}
+
+	image, err := c.packageImage(opts)
 	if err != nil {
-		return errors.Wrapf(err, "creating image")
+		return err
 	}
 
 	err = dist.SetLabel(image, buildpackage.MetadataLabel, &buildpackage.Metadata{
@@ -38,27 +135,196 @@ func (c *Client) CreatePackage(ctx context.Context, opts CreatePackageOptions) e
 	}
 	defer os.RemoveAll(tmpDir)
 
-	for _, bc := range opts.Config.Blobs {
-		blob, err := c.downloader.Download(ctx, bc.URI)
+	bpLayers := builder.BuildpackLayers{}
+	for _, bp := range bps {
+		bpInfo := bp.Descriptor().Info
+
+		var bpLayerTar string
+		if layer, ok := dependencyLayers[bpInfo]; ok {
+			bpLayerTar, err = copyLayer(tmpDir, layer)
+		} else {
+			bpLayerTar, err = dist.BuildpackLayer(tmpDir, 0, 0, bp)
+		}
 		if err != nil {
-			return errors.Wrapf(err, "downloading buildpack from %s", style.Symbol(bc.URI))
+			return err
 		}
 
-		bp, err := dist.NewBuildpack(blob)
-		if err != nil {
-			return errors.Wrapf(err, "creating buildpack from %s", style.Symbol(bc.URI))
+		if err := image.AddLayer(bpLayerTar); err != nil {
+			return errors.Wrapf(err, "adding layer tar for buildpack %s:%s", style.Symbol(bpInfo.ID), style.Symbol(bpInfo.Version))
 		}
 
-		bpLayerTar, err := dist.BuildpackLayer(tmpDir, 0, 0, bp)
+		digest, err := fileDigest(bpLayerTar)
 		if err != nil {
-			return err
+			return errors.Wrapf(err, "computing layer digest for buildpack %s", style.Symbol(bpInfo.ID+"@"+bpInfo.Version))
 		}
 
-		if err := image.AddLayer(bpLayerTar); err != nil {
-			return errors.Wrapf(err, "adding layer tar for buildpack %s:%s", style.Symbol(bp.Descriptor().Info.ID), style.Symbol(bp.Descriptor().Info.Version))
+		if _, ok := bpLayers[bpInfo.ID]; !ok {
+			bpLayers[bpInfo.ID] = map[string]builder.BuildpackLayerInfo{}
+		}
+		bpLayers[bpInfo.ID][bpInfo.Version] = builder.BuildpackLayerInfo{
+			LayerDigest: digest,
+			Order:       bp.Descriptor().Order,
+			Stacks:      bp.Descriptor().Stacks,
 		}
 	}
 
+	if err := dist.SetLabel(image, builder.BuildpackLayersLabel, bpLayers); err != nil {
+		return err
+	}
+
 	_, err = image.Save()
 	return err
 }
+
+// fetchPackageDependency returns the buildpackage file or image declared by dep, along with its name.
+func (c *Client) fetchPackageDependency(ctx context.Context, dep buildpackage.Dependency, daemon, pull bool) (string, buildpackageImage, error) {
+	switch {
+	case dep.URI != "" && dep.Image != "":
+		return "", nil, errors.Errorf("dependency %s must declare either 'uri' or 'image', not both", style.Symbol(dep.URI))
+	case dep.URI != "":
+		if !buildpackage.IsFile(dep.URI) {
+			return "", nil, errors.Errorf("dependency %s is not a buildpackage file", style.Symbol(dep.URI))
+		}
+
+		blob, err := c.downloader.Download(ctx, blob.WithSHA256(dep.URI, dep.SHA256))
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "downloading buildpackage from %s", style.Symbol(dep.URI))
+		}
+
+		file, err := buildpackage.OpenFile(blob)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "reading buildpackage file %s", style.Symbol(dep.URI))
+		}
+		return dep.URI, file, nil
+	case dep.Image != "":
+		img, err := c.imageFetcher.Fetch(ctx, dep.Image, daemon, pull)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "fetching buildpackage %s", style.Symbol(dep.Image))
+		}
+
+		if ok, err := dist.GetLabel(img, buildpackage.MetadataLabel, &buildpackage.Metadata{}); err != nil {
+			return "", nil, err
+		} else if !ok {
+			return "", nil, errors.Errorf("image %s is not a buildpackage: missing label %s", style.Symbol(dep.Image), style.Symbol(buildpackage.MetadataLabel))
+		}
+		return dep.Image, img, nil
+	default:
+		return "", nil, errors.New("dependency must declare either 'uri' or 'image'")
+	}
+}
+
+// copyLayer writes the dependency layer to a file in dir so that it is added to the package unchanged.
+func copyLayer(dir string, layer dependencyLayer) (string, error) {
+	rc, err := layer.image.GetLayer(layer.diffID)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading layer %s", style.Symbol(layer.diffID))
+	}
+	defer rc.Close()
+
+	fh, err := ioutil.TempFile(dir, "dependency-layer")
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	if _, err := io.Copy(fh, rc); err != nil {
+		return "", errors.Wrapf(err, "copying layer %s", style.Symbol(layer.diffID))
+	}
+	return fh.Name(), fh.Close()
+}
+
+func containsBuildpack(bps []dist.Buildpack, info dist.BuildpackInfo) bool {
+	for _, bp := range bps {
+		if bp.Descriptor().Info == info {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePackageFormat(opts CreatePackageOptions) error {
+	switch opts.Format {
+	case "", FormatImage:
+		return nil
+	case FormatFile:
+		if opts.Publish {
+			return errors.Errorf("a buildpackage with format %s cannot be published", style.Symbol(FormatFile))
+		}
+		if !buildpackage.IsFile(opts.Name) {
+			return errors.Errorf("buildpackage file %s must have the extension %s", style.Symbol(opts.Name), style.Symbol(buildpackage.FileExtension))
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown format %s, must be one of '%s' or '%s'", style.Symbol(opts.Format), FormatImage, FormatFile)
+	}
+}
+
+func (c *Client) packageImage(opts CreatePackageOptions) (packageImage, error) {
+	if opts.Format == FormatFile {
+		return buildpackage.NewFileImage(opts.Name), nil
+	}
+
+	image, err := c.imageFactory.NewImage(opts.Name, !opts.Publish)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating image")
+	}
+	return image, nil
+}
+
+// validatePackage checks that the default buildpack is one of bps, the buildpacks of the blobs and dependencies of the
+// package, that the combined orders of the package resolve within it and that its buildpacks support the stacks of
+// the package.
+func validatePackage(config buildpackage.Config, bps []dist.Buildpack) error {
+	layers := builder.BuildpackLayers{}
+	for _, bp := range bps {
+		bpd := bp.Descriptor()
+		if _, ok := layers[bpd.Info.ID][bpd.Info.Version]; ok {
+			return fmt.Errorf("buildpack %s is provided by more than one blob", style.Symbol(bpd.Info.ID+"@"+bpd.Info.Version))
+		}
+
+		if _, ok := layers[bpd.Info.ID]; !ok {
+			layers[bpd.Info.ID] = map[string]builder.BuildpackLayerInfo{}
+		}
+		layers[bpd.Info.ID][bpd.Info.Version] = builder.BuildpackLayerInfo{Order: bpd.Order, Stacks: bpd.Stacks}
+
+		for _, stack := range config.Stacks {
+			if len(bpd.Stacks) >= 1 && !bpd.SupportsStack(stack.ID) {
+				return fmt.Errorf(
+					"buildpack %s does not support stack %s",
+					style.Symbol(bpd.Info.ID+"@"+bpd.Info.Version),
+					style.Symbol(stack.ID),
+				)
+			}
+		}
+	}
+
+	if _, ok := layers[config.Default.ID][config.Default.Version]; !ok {
+		return fmt.Errorf(
+			"default buildpack %s is not provided by any blob or dependency",
+			style.Symbol(config.Default.ID+"@"+config.Default.Version),
+		)
+	}
+
+	for _, bp := range bps {
+		if len(bp.Descriptor().Order) == 0 {
+			continue
+		}
+
+		ref := dist.BuildpackRef{BuildpackInfo: bp.Descriptor().Info}
+		if err := builder.ValidateOrder(dist.Order{{Group: []dist.BuildpackRef{ref}}}, layers, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileDigest(path string) (string, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+
+	digest, _, err := digestAndSize(fh)
+	return digest, err
+}
@@ -5,6 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/buildpack/imgutil/fakes"
@@ -15,8 +18,11 @@ import (
 
 	"github.com/buildpack/pack"
 	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/builder"
 	"github.com/buildpack/pack/buildpackage"
 	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/internal/archive"
 	ifakes "github.com/buildpack/pack/internal/fakes"
 	"github.com/buildpack/pack/internal/logging"
 	h "github.com/buildpack/pack/testhelpers"
@@ -35,6 +41,7 @@ func testCreatePackage(t *testing.T, when spec.G, it spec.S) {
 		mockController   *gomock.Controller
 		mockDownloader   *testmocks.MockDownloader
 		mockImageFactory *testmocks.MockImageFactory
+		mockImageFetcher *testmocks.MockImageFetcher
 		fakePackageImage *fakes.Image
 		out              bytes.Buffer
 	)
@@ -43,6 +50,7 @@ func testCreatePackage(t *testing.T, when spec.G, it spec.S) {
 		mockController = gomock.NewController(t)
 		mockDownloader = testmocks.NewMockDownloader(mockController)
 		mockImageFactory = testmocks.NewMockImageFactory(mockController)
+		mockImageFetcher = testmocks.NewMockImageFetcher(mockController)
 
 		fakePackageImage = fakes.NewImage("some/package", "", "")
 		mockImageFactory.EXPECT().NewImage("some/package", true).Return(fakePackageImage, nil).AnyTimes()
@@ -52,6 +60,7 @@ func testCreatePackage(t *testing.T, when spec.G, it spec.S) {
 			pack.WithLogger(logging.NewLogWithWriters(&out, &out)),
 			pack.WithDownloader(mockDownloader),
 			pack.WithImageFactory(mockImageFactory),
+			pack.WithFetcher(mockImageFetcher),
 		)
 		h.AssertNil(t, err)
 	})
@@ -139,6 +148,30 @@ func testCreatePackage(t *testing.T, when spec.G, it spec.S) {
 				)
 			})
 
+			it("writes the buildpack layers label", func() {
+				h.AssertNil(t, client.CreatePackage(context.TODO(), opts))
+
+				var bpLayers builder.BuildpackLayers
+				ok, err := dist.GetLabel(fakePackageImage, "io.buildpacks.buildpack.layers", &bpLayers)
+				h.AssertNil(t, err)
+				h.AssertEq(t, ok, true)
+
+				layerInfo := bpLayers["bp.one"]["1.2.3"]
+				h.AssertEq(t, layerInfo.Stacks, []dist.Stack{{ID: "some.stack.id"}})
+
+				layer, err := fakePackageImage.GetLayer(layerInfo.LayerDigest)
+				h.AssertNil(t, err)
+				h.AssertNil(t, layer.Close())
+			})
+
+			it("fails when lint is enforced and the buildpack has lint errors", func() {
+				opts.Lint = true
+
+				err := client.CreatePackage(context.TODO(), opts)
+				h.AssertError(t, err, "buildpack from 'https://example.com/bp.one.tgz' failed linting: 'bin/detect' is not executable; 'bin/build' is not executable")
+				h.AssertEq(t, fakePackageImage.IsSaved(), false)
+			})
+
 			when("when publish is true", func() {
 				var fakeRemotePackageImage *fakes.Image
 
@@ -158,6 +191,263 @@ func testCreatePackage(t *testing.T, when spec.G, it spec.S) {
 					h.AssertEq(t, fakeRemotePackageImage.IsSaved(), true)
 				})
 			})
+			when("format is file", func() {
+				var (
+					tmpDir      string
+					packagePath string
+				)
+
+				it.Before(func() {
+					var err error
+					tmpDir, err = ioutil.TempDir("", "create-package-test")
+					h.AssertNil(t, err)
+
+					packagePath = filepath.Join(tmpDir, "package.cnb")
+					opts.Name = packagePath
+					opts.Format = pack.FormatFile
+				})
+
+				it.After(func() {
+					h.AssertNil(t, os.RemoveAll(tmpDir))
+				})
+
+				it("writes a buildpackage file with the labels and layers of the package", func() {
+					h.AssertNil(t, client.CreatePackage(context.TODO(), opts))
+
+					file, err := buildpackage.OpenFile(blob.NewBlob(packagePath))
+					h.AssertNil(t, err)
+
+					var md buildpackage.Metadata
+					ok, err := dist.GetLabel(file, "io.buildpacks.buildpackage.metadata", &md)
+					h.AssertNil(t, err)
+					h.AssertEq(t, ok, true)
+					h.AssertEq(t, md.BuildpackInfo, dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"})
+
+					var bpLayers builder.BuildpackLayers
+					_, err = dist.GetLabel(file, "io.buildpacks.buildpack.layers", &bpLayers)
+					h.AssertNil(t, err)
+
+					layer, err := file.GetLayer(bpLayers["bp.one"]["1.2.3"].LayerDigest)
+					h.AssertNil(t, err)
+					defer layer.Close()
+
+					_, contents, err := archive.ReadTarEntry(layer, "/cnb/buildpacks/bp.one/1.2.3/bin/build")
+					h.AssertNil(t, err)
+					h.AssertEq(t, string(contents), "build-contents")
+				})
+
+				it("can be used as a blob of another package", func() {
+					h.AssertNil(t, client.CreatePackage(context.TODO(), opts))
+
+					mockDownloader.EXPECT().Download(gomock.Any(), packagePath).Return(blob.NewBlob(packagePath), nil)
+
+					h.AssertNil(t, client.CreatePackage(context.TODO(), pack.CreatePackageOptions{
+						Name: fakePackageImage.Name(),
+						Config: buildpackage.Config{
+							Default: dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+							Blobs:   []dist.BlobConfig{{URI: packagePath}},
+						},
+					}))
+
+					_, err := fakePackageImage.FindLayerWithPath("/cnb/buildpacks/bp.one/1.2.3/bin/build")
+					h.AssertNil(t, err)
+				})
+
+				it("fails when publish is true", func() {
+					opts.Publish = true
+					h.AssertError(t, client.CreatePackage(context.TODO(), opts), "a buildpackage with format 'file' cannot be published")
+				})
+
+				it("fails when the file does not have the buildpackage file extension", func() {
+					opts.Name = filepath.Join(tmpDir, "package.tar")
+					h.AssertError(t, client.CreatePackage(context.TODO(), opts), "buildpackage file '"+opts.Name+"' must have the extension '.cnb'")
+				})
+			})
+		})
+
+		when("package declares dependencies", func() {
+			var (
+				tmpDir          string
+				dependencyImage *fakes.Image
+			)
+
+			addBuildpack := func(uri string, descriptor dist.BuildpackDescriptor) {
+				descriptor.API = api.MustParse("0.2")
+				buildpack, err := ifakes.NewBuildpackFromDescriptor(descriptor, 0644)
+				h.AssertNil(t, err)
+				mockDownloader.EXPECT().Download(gomock.Any(), uri).Return(buildpack, nil).AnyTimes()
+			}
+
+			layerDigest := func(img dist.Labeled, id, version string) string {
+				var bpLayers builder.BuildpackLayers
+				_, err := dist.GetLabel(img, "io.buildpacks.buildpack.layers", &bpLayers)
+				h.AssertNil(t, err)
+				return bpLayers[id][version].LayerDigest
+			}
+
+			metaConfig := func(dep buildpackage.Dependency) buildpackage.Config {
+				return buildpackage.Config{
+					Default:      dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+					Blobs:        []dist.BlobConfig{{URI: "https://example.com/bp.meta.tgz"}},
+					Dependencies: []buildpackage.Dependency{dep},
+				}
+			}
+
+			it.Before(func() {
+				var err error
+				tmpDir, err = ioutil.TempDir("", "create-package-test")
+				h.AssertNil(t, err)
+
+				addBuildpack("https://example.com/bp.one.tgz", dist.BuildpackDescriptor{
+					Info:   dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+					Stacks: []dist.Stack{{ID: "some.stack.id"}},
+				})
+				addBuildpack("https://example.com/bp.meta.tgz", dist.BuildpackDescriptor{
+					Info: dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+					Order: dist.Order{{Group: []dist.BuildpackRef{
+						{BuildpackInfo: dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"}},
+					}}},
+				})
+
+				dependencyImage = fakes.NewImage("some/dependency", "", "")
+				mockImageFactory.EXPECT().NewImage("some/dependency", true).Return(dependencyImage, nil).AnyTimes()
+			})
+
+			it.After(func() {
+				dependencyImage.Cleanup()
+				h.AssertNil(t, os.RemoveAll(tmpDir))
+			})
+
+			createDependency := func(name, format string) {
+				h.AssertNil(t, client.CreatePackage(context.TODO(), pack.CreatePackageOptions{
+					Name:   name,
+					Format: format,
+					Config: buildpackage.Config{
+						Default: dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+						Blobs:   []dist.BlobConfig{{URI: "https://example.com/bp.one.tgz"}},
+					},
+				}))
+			}
+
+			it("copies the layers of a buildpackage file by digest", func() {
+				dependencyPath := filepath.Join(tmpDir, "dependency.cnb")
+				createDependency(dependencyPath, pack.FormatFile)
+				mockDownloader.EXPECT().Download(gomock.Any(), dependencyPath).Return(blob.NewBlob(dependencyPath), nil)
+
+				h.AssertNil(t, client.CreatePackage(context.TODO(), pack.CreatePackageOptions{
+					Name:   fakePackageImage.Name(),
+					Config: metaConfig(buildpackage.Dependency{URI: dependencyPath}),
+				}))
+
+				file, err := buildpackage.OpenFile(blob.NewBlob(dependencyPath))
+				h.AssertNil(t, err)
+
+				digest := layerDigest(fakePackageImage, "bp.one", "1.2.3")
+				h.AssertEq(t, digest, layerDigest(file, "bp.one", "1.2.3"))
+
+				layer, err := fakePackageImage.GetLayer(digest)
+				h.AssertNil(t, err)
+				h.AssertNil(t, layer.Close())
+			})
+
+			it("copies the layers of a buildpackage image by digest", func() {
+				createDependency("some/dependency", pack.FormatImage)
+				mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/dependency", true, true).Return(dependencyImage, nil)
+
+				h.AssertNil(t, client.CreatePackage(context.TODO(), pack.CreatePackageOptions{
+					Name:   fakePackageImage.Name(),
+					Config: metaConfig(buildpackage.Dependency{Image: "some/dependency"}),
+				}))
+
+				h.AssertEq(t, layerDigest(fakePackageImage, "bp.one", "1.2.3"), layerDigest(dependencyImage, "bp.one", "1.2.3"))
+			})
+
+			it("fails when the combined order references a buildpack missing from the package", func() {
+				addBuildpack("https://example.com/bp.other.tgz", dist.BuildpackDescriptor{
+					Info:   dist.BuildpackInfo{ID: "bp.other", Version: "2.0.0"},
+					Stacks: []dist.Stack{{ID: "some.stack.id"}},
+				})
+				h.AssertNil(t, client.CreatePackage(context.TODO(), pack.CreatePackageOptions{
+					Name: "some/dependency",
+					Config: buildpackage.Config{
+						Default: dist.BuildpackInfo{ID: "bp.other", Version: "2.0.0"},
+						Blobs:   []dist.BlobConfig{{URI: "https://example.com/bp.other.tgz"}},
+					},
+				}))
+				mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/dependency", true, true).Return(dependencyImage, nil)
+
+				err := client.CreatePackage(context.TODO(), pack.CreatePackageOptions{
+					Name:   fakePackageImage.Name(),
+					Config: metaConfig(buildpackage.Dependency{Image: "some/dependency"}),
+				})
+				h.AssertError(t, err, "buildpack 'bp.one@1.2.3' not found on the builder (required by 'bp.meta@1.0.0')")
+				h.AssertEq(t, fakePackageImage.IsSaved(), false)
+			})
+
+			it("fails when a dependency is not a buildpackage file", func() {
+				err := client.CreatePackage(context.TODO(), pack.CreatePackageOptions{
+					Name:   fakePackageImage.Name(),
+					Config: metaConfig(buildpackage.Dependency{URI: "https://example.com/bp.one.tgz"}),
+				})
+				h.AssertError(t, err, "dependency 'https://example.com/bp.one.tgz' is not a buildpackage file")
+			})
+		})
+
+		when("package config is invalid", func() {
+			addBuildpack := func(uri string, descriptor dist.BuildpackDescriptor) {
+				descriptor.API = api.MustParse("0.2")
+				buildpack, err := ifakes.NewBuildpackFromDescriptor(descriptor, 0644)
+				h.AssertNil(t, err)
+				mockDownloader.EXPECT().Download(gomock.Any(), uri).Return(buildpack, nil).AnyTimes()
+			}
+
+			ref := func(id, version string) dist.BuildpackRef {
+				return dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: id, Version: version}}
+			}
+
+			it.Before(func() {
+				addBuildpack("https://example.com/bp.one.tgz", dist.BuildpackDescriptor{
+					Info:   dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+					Stacks: []dist.Stack{{ID: "some.stack.id"}},
+				})
+				addBuildpack("https://example.com/bp.meta.tgz", dist.BuildpackDescriptor{
+					Info:  dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+					Order: dist.Order{{Group: []dist.BuildpackRef{ref("bp.one", "1.2.3"), ref("bp.two", "")}}},
+				})
+			})
+
+			createPackage := func(config buildpackage.Config) error {
+				return client.CreatePackage(context.TODO(), pack.CreatePackageOptions{Name: fakePackageImage.Name(), Config: config})
+			}
+
+			it("fails when the default buildpack is not among the blobs", func() {
+				err := createPackage(buildpackage.Config{
+					Default: dist.BuildpackInfo{ID: "bp.other", Version: "1.2.3"},
+					Blobs:   []dist.BlobConfig{{URI: "https://example.com/bp.one.tgz"}},
+				})
+				h.AssertError(t, err, "default buildpack 'bp.other@1.2.3' is not provided by any blob")
+				h.AssertEq(t, fakePackageImage.IsSaved(), false)
+			})
+
+			it("fails when an order references a buildpack missing from the package", func() {
+				err := createPackage(buildpackage.Config{
+					Default: dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+					Blobs: []dist.BlobConfig{
+						{URI: "https://example.com/bp.one.tgz"},
+						{URI: "https://example.com/bp.meta.tgz"},
+					},
+				})
+				h.AssertError(t, err, "no versions of buildpack 'bp.two' were found on the builder (required by 'bp.meta@1.0.0')")
+			})
+
+			it("fails when a buildpack does not support a stack of the package", func() {
+				err := createPackage(buildpackage.Config{
+					Default: dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+					Blobs:   []dist.BlobConfig{{URI: "https://example.com/bp.one.tgz"}},
+					Stacks:  []dist.Stack{{ID: "other.stack.id"}},
+				})
+				h.AssertError(t, err, "buildpack 'bp.one@1.2.3' does not support stack 'other.stack.id'")
+			})
 		})
 	})
 }
@@ -0,0 +1,322 @@
+package pack
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/style"
+)
+
+type DiffBuildersOptions struct {
+	OldBuilder string
+	NewBuilder string
+	// Daemon selects whether the builders are looked up in the docker daemon or in a registry.
+	Daemon bool
+}
+
+// BuilderDiff describes the changes between two builders. Sections without changes are left empty.
+type BuilderDiff struct {
+	OldBuilder      string                 `json:"old_builder"`
+	NewBuilder      string                 `json:"new_builder"`
+	Buildpacks      BuildpacksDiff         `json:"buildpacks"`
+	Lifecycle       LifecycleDiff          `json:"lifecycle"`
+	Stack           *ValueChange           `json:"stack,omitempty"`
+	RunImage        *ValueChange           `json:"run_image,omitempty"`
+	RunImageMirrors ListDiff               `json:"run_image_mirrors"`
+	Order           []OrderGroupChange     `json:"order,omitempty"`
+	Layers          []BuildpackLayerChange `json:"layers,omitempty"`
+}
+
+type BuildpacksDiff struct {
+	Added      []dist.BuildpackInfo `json:"added,omitempty"`
+	Removed    []dist.BuildpackInfo `json:"removed,omitempty"`
+	Upgraded   []VersionChange      `json:"upgraded,omitempty"`
+	Downgraded []VersionChange      `json:"downgraded,omitempty"`
+}
+
+type VersionChange struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type LifecycleDiff struct {
+	Version      *ValueChange `json:"version,omitempty"`
+	BuildpackAPI *ValueChange `json:"buildpack_api,omitempty"`
+	PlatformAPI  *ValueChange `json:"platform_api,omitempty"`
+}
+
+type ValueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type ListDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// OrderGroupChange describes a detection order group that differs between the builders. Index is zero-based;
+// From is nil for groups only present in the new builder and To is nil for groups only present in the old one.
+type OrderGroupChange struct {
+	Index int              `json:"index"`
+	From  *dist.OrderEntry `json:"from"`
+	To    *dist.OrderEntry `json:"to"`
+}
+
+type BuildpackLayerChange struct {
+	dist.BuildpackInfo
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IsEmpty returns true when the builders have no differences.
+func (d *BuilderDiff) IsEmpty() bool {
+	bps := d.Buildpacks
+	lc := d.Lifecycle
+	return len(bps.Added) == 0 && len(bps.Removed) == 0 && len(bps.Upgraded) == 0 && len(bps.Downgraded) == 0 &&
+		lc.Version == nil && lc.BuildpackAPI == nil && lc.PlatformAPI == nil &&
+		d.Stack == nil && d.RunImage == nil &&
+		len(d.RunImageMirrors.Added) == 0 && len(d.RunImageMirrors.Removed) == 0 &&
+		len(d.Order) == 0 && len(d.Layers) == 0
+}
+
+func (c *Client) DiffBuilders(opts DiffBuildersOptions) (*BuilderDiff, error) {
+	oldInfo, err := c.inspectBuilderForDiff(opts.OldBuilder, opts.Daemon)
+	if err != nil {
+		return nil, err
+	}
+
+	newInfo, err := c.inspectBuilderForDiff(opts.NewBuilder, opts.Daemon)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &BuilderDiff{
+		OldBuilder:      opts.OldBuilder,
+		NewBuilder:      opts.NewBuilder,
+		Buildpacks:      diffBuildpacks(oldInfo, newInfo),
+		Lifecycle:       diffLifecycle(oldInfo, newInfo),
+		Stack:           diffValue(oldInfo.Stack, newInfo.Stack),
+		RunImage:        diffValue(oldInfo.RunImage, newInfo.RunImage),
+		RunImageMirrors: diffList(oldInfo.RunImageMirrors, newInfo.RunImageMirrors),
+		Order:           diffOrder(oldInfo.Order, newInfo.Order),
+		Layers:          diffLayers(oldInfo, newInfo),
+	}
+
+	return diff, nil
+}
+
+func (c *Client) inspectBuilderForDiff(name string, daemon bool) (*BuilderInfo, error) {
+	info, err := c.InspectBuilder(name, daemon)
+	if err != nil {
+		return nil, errors.Wrapf(err, "inspecting builder %s", style.Symbol(name))
+	}
+
+	if info == nil {
+		return nil, fmt.Errorf("builder %s not found", style.Symbol(name))
+	}
+
+	return info, nil
+}
+
+func diffBuildpacks(oldInfo, newInfo *BuilderInfo) BuildpacksDiff {
+	oldVersions := buildpackVersions(oldInfo)
+	newVersions := buildpackVersions(newInfo)
+
+	diff := BuildpacksDiff{}
+	for _, id := range union(mapKeys(oldVersions), mapKeys(newVersions)) {
+		removed := subtract(oldVersions[id], newVersions[id])
+		added := subtract(newVersions[id], oldVersions[id])
+
+		if len(removed) == 1 && len(added) == 1 {
+			change := VersionChange{ID: id, From: removed[0], To: added[0]}
+			if isDowngrade(change) {
+				diff.Downgraded = append(diff.Downgraded, change)
+			} else {
+				diff.Upgraded = append(diff.Upgraded, change)
+			}
+			continue
+		}
+
+		for _, v := range removed {
+			diff.Removed = append(diff.Removed, dist.BuildpackInfo{ID: id, Version: v})
+		}
+		for _, v := range added {
+			diff.Added = append(diff.Added, dist.BuildpackInfo{ID: id, Version: v})
+		}
+	}
+
+	return diff
+}
+
+func mapKeys(m map[string][]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func buildpackVersions(info *BuilderInfo) map[string][]string {
+	versions := map[string][]string{}
+	for _, bp := range info.Buildpacks {
+		versions[bp.ID] = append(versions[bp.ID], bp.Version)
+	}
+	return versions
+}
+
+func isDowngrade(change VersionChange) bool {
+	from, err := semver.NewVersion(change.From)
+	if err != nil {
+		return false
+	}
+
+	to, err := semver.NewVersion(change.To)
+	if err != nil {
+		return false
+	}
+
+	return to.LessThan(from)
+}
+
+func diffLifecycle(oldInfo, newInfo *BuilderInfo) LifecycleDiff {
+	oldLifecycle, newLifecycle := oldInfo.Lifecycle, newInfo.Lifecycle
+	return LifecycleDiff{
+		Version:      diffValue(lifecycleVersionString(oldLifecycle.Info.Version), lifecycleVersionString(newLifecycle.Info.Version)),
+		BuildpackAPI: diffValue(apiVersionString(oldLifecycle.API.BuildpackVersion), apiVersionString(newLifecycle.API.BuildpackVersion)),
+		PlatformAPI:  diffValue(apiVersionString(oldLifecycle.API.PlatformVersion), apiVersionString(newLifecycle.API.PlatformVersion)),
+	}
+}
+
+func lifecycleVersionString(v *builder.Version) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func apiVersionString(v *api.Version) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func diffValue(from, to string) *ValueChange {
+	if from == to {
+		return nil
+	}
+	return &ValueChange{From: from, To: to}
+}
+
+func diffList(from, to []string) ListDiff {
+	return ListDiff{
+		Added:   subtract(to, from),
+		Removed: subtract(from, to),
+	}
+}
+
+func diffOrder(from, to dist.Order) []OrderGroupChange {
+	var changes []OrderGroupChange
+	for i := 0; i < len(from) || i < len(to); i++ {
+		var fromGroup, toGroup *dist.OrderEntry
+		if i < len(from) {
+			fromGroup = &from[i]
+		}
+		if i < len(to) {
+			toGroup = &to[i]
+		}
+
+		if fromGroup != nil && toGroup != nil && groupsEqual(*fromGroup, *toGroup) {
+			continue
+		}
+
+		changes = append(changes, OrderGroupChange{Index: i, From: fromGroup, To: toGroup})
+	}
+	return changes
+}
+
+func groupsEqual(a, b dist.OrderEntry) bool {
+	if len(a.Group) != len(b.Group) {
+		return false
+	}
+	for i := range a.Group {
+		if a.Group[i] != b.Group[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func diffLayers(oldInfo, newInfo *BuilderInfo) []BuildpackLayerChange {
+	var changes []BuildpackLayerChange
+	var ids []string
+	for id := range oldInfo.BuildpackLayers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var versions []string
+		for version := range oldInfo.BuildpackLayers[id] {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+
+		for _, version := range versions {
+			newLayer, ok := newInfo.BuildpackLayers[id][version]
+			if !ok {
+				continue
+			}
+
+			oldLayer := oldInfo.BuildpackLayers[id][version]
+			if oldLayer.LayerDigest != newLayer.LayerDigest {
+				changes = append(changes, BuildpackLayerChange{
+					BuildpackInfo: dist.BuildpackInfo{ID: id, Version: version},
+					From:          oldLayer.LayerDigest,
+					To:            newLayer.LayerDigest,
+				})
+			}
+		}
+	}
+	return changes
+}
+
+// union returns the values of a and b, sorted and deduplicated.
+func union(a, b []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, v := range append(append([]string{}, a...), b...) {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// subtract returns the values of a not present in b, preserving their order.
+func subtract(a, b []string) []string {
+	var result []string
+	for _, v := range a {
+		found := false
+		for _, w := range b {
+			if v == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, v)
+		}
+	}
+	return result
+}
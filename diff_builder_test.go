@@ -0,0 +1,162 @@
+package pack
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/buildpack/imgutil/fakes"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/dist"
+	ifakes "github.com/buildpack/pack/internal/fakes"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestDiffBuilders(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "DiffBuilders", testDiffBuilders, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testDiffBuilders(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject          *Client
+		fakeImageFetcher *ifakes.FakeImageFetcher
+		oldBuilder       *fakes.Image
+		newBuilder       *fakes.Image
+		out              bytes.Buffer
+	)
+
+	newBuilderImage := func(name, stackID, metadata, order, layers string) *fakes.Image {
+		img := fakes.NewImage(name, "", "")
+		h.AssertNil(t, img.SetLabel("io.buildpacks.stack.id", stackID))
+		h.AssertNil(t, img.SetLabel("io.buildpacks.builder.metadata", metadata))
+		h.AssertNil(t, img.SetLabel("io.buildpacks.buildpack.order", order))
+		h.AssertNil(t, img.SetLabel("io.buildpacks.buildpack.layers", layers))
+		h.AssertNil(t, img.SetEnv("CNB_USER_ID", "1234"))
+		h.AssertNil(t, img.SetEnv("CNB_GROUP_ID", "4321"))
+		return img
+	}
+
+	it.Before(func() {
+		fakeImageFetcher = ifakes.NewFakeImageFetcher()
+
+		subject = &Client{
+			logger:       ifakes.NewFakeLogger(&out),
+			imageFetcher: fakeImageFetcher,
+		}
+
+		oldBuilder = newBuilderImage("some/builder:old", "test.stack.id", `{
+  "stack": {"runImage": {"image": "some/run-image", "mirrors": ["some/mirror", "old/mirror"]}},
+  "buildpacks": [
+    {"id": "bp.same", "version": "1.0.0"},
+    {"id": "bp.upgraded", "version": "1.0.0"},
+    {"id": "bp.downgraded", "version": "2.0.0"},
+    {"id": "bp.removed", "version": "1.0.0"}
+  ],
+  "lifecycle": {"version": "0.4.0", "api": {"buildpack": "0.2", "platform": "0.1"}}
+}`,
+			`[{"group": [{"id": "bp.same", "version": "1.0.0"}]}, {"group": [{"id": "bp.removed", "version": "1.0.0"}]}]`,
+			`{"bp.same": {"1.0.0": {"layerDigest": "sha256:same-old"}}, "bp.removed": {"1.0.0": {"layerDigest": "sha256:removed"}}}`,
+		)
+
+		newBuilder = newBuilderImage("some/builder:new", "test.stack.id", `{
+  "stack": {"runImage": {"image": "other/run-image", "mirrors": ["some/mirror", "new/mirror"]}},
+  "buildpacks": [
+    {"id": "bp.same", "version": "1.0.0"},
+    {"id": "bp.upgraded", "version": "1.1.0"},
+    {"id": "bp.downgraded", "version": "1.9.0"},
+    {"id": "bp.added", "version": "1.0.0"}
+  ],
+  "lifecycle": {"version": "0.5.0", "api": {"buildpack": "0.2", "platform": "0.2"}}
+}`,
+			`[{"group": [{"id": "bp.same", "version": "1.0.0"}]}, {"group": [{"id": "bp.added", "version": "1.0.0"}]}]`,
+			`{"bp.same": {"1.0.0": {"layerDigest": "sha256:same-new"}}, "bp.added": {"1.0.0": {"layerDigest": "sha256:added"}}}`,
+		)
+
+		fakeImageFetcher.LocalImages["some/builder:old"] = oldBuilder
+		fakeImageFetcher.LocalImages["some/builder:new"] = newBuilder
+	})
+
+	it.After(func() {
+		oldBuilder.Cleanup()
+		newBuilder.Cleanup()
+	})
+
+	when("#DiffBuilders", func() {
+		var diff *BuilderDiff
+
+		it.Before(func() {
+			var err error
+			diff, err = subject.DiffBuilders(DiffBuildersOptions{
+				OldBuilder: "some/builder:old",
+				NewBuilder: "some/builder:new",
+				Daemon:     true,
+			})
+			h.AssertNil(t, err)
+		})
+
+		it("reports buildpack changes", func() {
+			h.AssertEq(t, diff.Buildpacks.Added, []dist.BuildpackInfo{{ID: "bp.added", Version: "1.0.0"}})
+			h.AssertEq(t, diff.Buildpacks.Removed, []dist.BuildpackInfo{{ID: "bp.removed", Version: "1.0.0"}})
+			h.AssertEq(t, diff.Buildpacks.Upgraded, []VersionChange{{ID: "bp.upgraded", From: "1.0.0", To: "1.1.0"}})
+			h.AssertEq(t, diff.Buildpacks.Downgraded, []VersionChange{{ID: "bp.downgraded", From: "2.0.0", To: "1.9.0"}})
+		})
+
+		it("reports lifecycle changes", func() {
+			h.AssertEq(t, diff.Lifecycle.Version, &ValueChange{From: "0.4.0", To: "0.5.0"})
+			h.AssertNil(t, diff.Lifecycle.BuildpackAPI)
+			h.AssertEq(t, diff.Lifecycle.PlatformAPI, &ValueChange{From: "0.1", To: "0.2"})
+		})
+
+		it("reports stack and run image changes", func() {
+			h.AssertNil(t, diff.Stack)
+			h.AssertEq(t, diff.RunImage, &ValueChange{From: "some/run-image", To: "other/run-image"})
+			h.AssertEq(t, diff.RunImageMirrors, ListDiff{Added: []string{"new/mirror"}, Removed: []string{"old/mirror"}})
+		})
+
+		it("reports detection order changes", func() {
+			h.AssertEq(t, len(diff.Order), 1)
+			h.AssertEq(t, diff.Order[0].Index, 1)
+			h.AssertEq(t, diff.Order[0].From.Group[0].ID, "bp.removed")
+			h.AssertEq(t, diff.Order[0].To.Group[0].ID, "bp.added")
+		})
+
+		it("reports changed buildpack layers", func() {
+			h.AssertEq(t, diff.Layers, []BuildpackLayerChange{{
+				BuildpackInfo: dist.BuildpackInfo{ID: "bp.same", Version: "1.0.0"},
+				From:          "sha256:same-old",
+				To:            "sha256:same-new",
+			}})
+		})
+
+		it("is not empty", func() {
+			h.AssertEq(t, diff.IsEmpty(), false)
+		})
+	})
+
+	when("the builders are the same", func() {
+		it("returns an empty diff", func() {
+			diff, err := subject.DiffBuilders(DiffBuildersOptions{
+				OldBuilder: "some/builder:old",
+				NewBuilder: "some/builder:old",
+				Daemon:     true,
+			})
+			h.AssertNil(t, err)
+			h.AssertEq(t, diff.IsEmpty(), true)
+		})
+	})
+
+	when("a builder does not exist", func() {
+		it("returns an error", func() {
+			_, err := subject.DiffBuilders(DiffBuildersOptions{
+				OldBuilder: "some/builder:old",
+				NewBuilder: "missing/builder",
+				Daemon:     true,
+			})
+			h.AssertError(t, err, "builder 'missing/builder' not found")
+		})
+	})
+}
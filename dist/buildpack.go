@@ -101,3 +101,33 @@ func (b *BuildpackDescriptor) SupportsStack(stackID string) bool {
 	}
 	return false
 }
+
+// RequiredExecutables are the executables a buildpack without an order must provide.
+var RequiredExecutables = []string{"bin/detect", "bin/build"}
+
+// MissingExecutables returns the required executables absent from the buildpack or present without execute
+// permissions. Buildpacks with an order have no required executables.
+func MissingExecutables(bp Buildpack) ([]string, error) {
+	if len(bp.Descriptor().Order) > 0 {
+		return nil, nil
+	}
+
+	rc, err := bp.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "open buildpack")
+	}
+	defer rc.Close()
+
+	headers, _, err := buildpackEntries(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, executable := range RequiredExecutables {
+		if header, ok := headers[executable]; !ok || !isExecutable(header) {
+			missing = append(missing, executable)
+		}
+	}
+	return missing, nil
+}
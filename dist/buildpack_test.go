@@ -117,4 +117,95 @@ version = "1.2.3"
 			})
 		})
 	})
+
+	when("#MissingExecutables", func() {
+		it.Before(func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "buildpack.toml"), []byte(`
+[buildpack]
+id = "bp.one"
+version = "1.2.3"
+
+[[stacks]]
+id = "some.stack.id"
+`), os.ModePerm))
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpBpDir, "bin"), os.ModePerm))
+		})
+
+		it("returns nothing when the executables are present", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "bin", "detect"), []byte("detect"), 0755))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "bin", "build"), []byte("build"), 0755))
+
+			bp, err := dist.NewBuildpack(blob.NewBlob(tmpBpDir))
+			h.AssertNil(t, err)
+
+			missing, err := dist.MissingExecutables(bp)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(missing), 0)
+		})
+
+		it("returns absent or non-executable files", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "bin", "build"), []byte("build"), 0644))
+
+			bp, err := dist.NewBuildpack(blob.NewBlob(tmpBpDir))
+			h.AssertNil(t, err)
+
+			missing, err := dist.MissingExecutables(bp)
+			h.AssertNil(t, err)
+			h.AssertEq(t, missing, []string{"bin/detect", "bin/build"})
+		})
+
+		it("does not require executables for order buildpacks", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "buildpack.toml"), []byte(`
+[buildpack]
+id = "bp.one"
+version = "1.2.3"
+
+[[order]]
+[[order.group]]
+  id = "bp.nested"
+  version = "bp.nested.version"
+`), os.ModePerm))
+
+			bp, err := dist.NewBuildpack(blob.NewBlob(tmpBpDir))
+			h.AssertNil(t, err)
+
+			missing, err := dist.MissingExecutables(bp)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(missing), 0)
+		})
+	})
+
+	when("#BuildpackFromLayer", func() {
+		it("reads the buildpack from its layer", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "buildpack.toml"), []byte(`
+[buildpack]
+id = "some/bp"
+version = "1.2.3"
+
+[[stacks]]
+id = "some.stack.id"
+`), os.ModePerm))
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpBpDir, "bin"), os.ModePerm))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "bin", "detect"), []byte("detect"), 0755))
+
+			bp, err := dist.NewBuildpack(blob.NewBlob(tmpBpDir))
+			h.AssertNil(t, err)
+
+			layerDir, err := ioutil.TempDir("", "buildpack-layer")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(layerDir)
+
+			layerTar, err := dist.BuildpackLayer(layerDir, 0, 0, bp)
+			h.AssertNil(t, err)
+
+			layerBp, err := dist.BuildpackFromLayer(blob.NewBlob(layerTar), bp.Descriptor().Info)
+			h.AssertNil(t, err)
+			h.AssertEq(t, layerBp.Descriptor().Info, bp.Descriptor().Info)
+			h.AssertEq(t, layerBp.Descriptor().Stacks[0].ID, "some.stack.id")
+
+			missing, err := dist.MissingExecutables(layerBp)
+			h.AssertNil(t, err)
+			h.AssertEq(t, missing, []string{"bin/build"})
+		})
+	})
 }
@@ -2,6 +2,8 @@ package dist
 
 type BlobConfig struct {
 	URI string `toml:"uri"`
+	// SHA256 is the expected digest of the contents at URI. It is verified when it is set.
+	SHA256 string `toml:"sha256,omitempty"`
 }
 
 type Order []OrderEntry
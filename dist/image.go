@@ -3,13 +3,22 @@ package dist
 import (
 	"encoding/json"
 
-	"github.com/buildpack/imgutil"
 	"github.com/pkg/errors"
 
 	"github.com/buildpack/pack/style"
 )
 
-func SetLabel(image imgutil.Image, label string, data interface{}) error {
+// Labelable is an image whose labels can be set, such as an imgutil.Image.
+type Labelable interface {
+	SetLabel(string, string) error
+}
+
+// Labeled is an image whose labels can be read, such as an imgutil.Image.
+type Labeled interface {
+	Label(string) (string, error)
+}
+
+func SetLabel(image Labelable, label string, data interface{}) error {
 	dataBytes, err := json.Marshal(data)
 	if err != nil {
 		return errors.Wrapf(err, "marshalling data to JSON for label %s", style.Symbol(label))
@@ -20,7 +29,7 @@ func SetLabel(image imgutil.Image, label string, data interface{}) error {
 	return nil
 }
 
-func GetLabel(image imgutil.Image, label string, obj interface{}) (ok bool, err error) {
+func GetLabel(image Labeled, label string, obj interface{}) (ok bool, err error) {
 	labelData, err := image.Label(label)
 	if err != nil {
 		return false, errors.Wrapf(err, "retrieving label %s", style.Symbol(label))
@@ -4,10 +4,10 @@ import (
 	"archive/tar"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -63,17 +63,66 @@ func BuildpackLayer(dest string, uid, gid int, bp Buildpack) (string, error) {
 }
 
 func embedBuildpackTar(tw *tar.Writer, uid, gid int, bp Buildpack, baseTarDir string) error {
-	var (
-		err error
-	)
-
 	rc, err := bp.Open()
 	if err != nil {
 		return errors.Wrap(err, "read buildpack blob")
 	}
 	defer rc.Close()
 
-	tr := tar.NewReader(rc)
+	return archive.ReadSortedTarEntries(rc, func(header *tar.Header, contents io.Reader) error {
+		header.Name = path.Clean(header.Name)
+		if header.Name == "." || header.Name == "/" {
+			return nil
+		}
+
+		header.Name = path.Clean(path.Join(baseTarDir, header.Name))
+		archive.NormalizeHeader(header)
+		header.Uid = uid
+		header.Gid = gid
+		if err := tw.WriteHeader(header); err != nil {
+			return errors.Wrapf(err, "failed to write header for '%s'", header.Name)
+		}
+
+		if _, err := io.Copy(tw, contents); err != nil {
+			return errors.Wrapf(err, "failed to write contents to '%s'", header.Name)
+		}
+		return nil
+	})
+}
+
+// BuildpackFromLayer reads the buildpack identified by info from a layer created by BuildpackLayer.
+func BuildpackFromLayer(layer Blob, info BuildpackInfo) (Buildpack, error) {
+	bpd := BuildpackDescriptor{Info: info}
+	return NewBuildpack(&layerBuildpackBlob{
+		layer:   layer,
+		baseDir: path.Join(BuildpacksDir, bpd.EscapedID(), info.Version),
+	})
+}
+
+// layerBuildpackBlob presents the contents of a buildpack directory within a layer as a buildpack tar.
+type layerBuildpackBlob struct {
+	layer   Blob
+	baseDir string
+}
+
+func (b *layerBuildpackBlob) Open() (io.ReadCloser, error) {
+	rc, err := b.layer.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "open layer")
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer rc.Close()
+		pw.CloseWithError(b.copyBuildpackEntries(tar.NewReader(rc), pw))
+	}()
+
+	return pr, nil
+}
+
+func (b *layerBuildpackBlob) copyBuildpackEntries(tr *tar.Reader, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	prefix := b.baseDir + "/"
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -83,29 +132,20 @@ func embedBuildpackTar(tw *tar.Writer, uid, gid int, bp Buildpack, baseTarDir st
 			return errors.Wrap(err, "failed to get next tar entry")
 		}
 
-		header.Name = path.Clean(header.Name)
-		if header.Name == "." || header.Name == "/" {
+		name := path.Clean("/" + header.Name)
+		if !strings.HasPrefix(name, prefix) {
 			continue
 		}
 
-		header.Name = path.Clean(path.Join(baseTarDir, header.Name))
-		header.Uid = uid
-		header.Gid = gid
-		err = tw.WriteHeader(header)
-		if err != nil {
+		header.Name = strings.TrimPrefix(name, prefix)
+		if err := tw.WriteHeader(header); err != nil {
 			return errors.Wrapf(err, "failed to write header for '%s'", header.Name)
 		}
 
-		buf, err := ioutil.ReadAll(tr)
-		if err != nil {
-			return errors.Wrapf(err, "failed to read contents of '%s'", header.Name)
-		}
-
-		_, err = tw.Write(buf)
-		if err != nil {
+		if _, err := io.Copy(tw, tr); err != nil {
 			return errors.Wrapf(err, "failed to write contents to '%s'", header.Name)
 		}
 	}
 
-	return nil
+	return tw.Close()
 }
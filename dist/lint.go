@@ -0,0 +1,200 @@
+package dist
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/style"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rules reported by Lint.
+const (
+	RuleDescriptor  = "descriptor"
+	RuleAPI         = "api"
+	RuleID          = "id"
+	RuleVersion     = "version"
+	RuleStacksOrder = "stacks-order"
+	RuleExecutables = "executables"
+)
+
+// reservedBuildpackIDs cannot be used as buildpack IDs.
+var reservedBuildpackIDs = []string{"app", "config"}
+
+var buildpackIDRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9./-]*$`)
+
+// Finding is a problem with a buildpack reported by Lint.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s [%s] %s", f.Severity, f.Rule, f.Message)
+}
+
+type LintOptions struct {
+	// BuildpackAPI is the buildpack API of the lifecycle the buildpack is used with. The API of the buildpack is not
+	// checked against it when it is nil.
+	BuildpackAPI *api.Version
+}
+
+// Lint inspects the buildpack in blob and returns the problems found with it. An error is only returned when blob
+// cannot be read.
+func Lint(blob Blob, opts LintOptions) ([]Finding, error) {
+	rc, err := blob.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "open buildpack")
+	}
+	defer rc.Close()
+
+	headers, descriptorBytes, err := buildpackEntries(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	report := func(severity Severity, rule, format string, args ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if descriptorBytes == nil {
+		report(SeverityError, RuleDescriptor, "buildpack.toml is missing")
+		return findings, nil
+	}
+
+	var bpd BuildpackDescriptor
+	if _, err := toml.Decode(string(descriptorBytes), &bpd); err != nil {
+		report(SeverityError, RuleDescriptor, "buildpack.toml cannot be decoded: %s", err)
+		return findings, nil
+	}
+
+	switch {
+	case bpd.API == nil:
+		report(SeverityWarning, RuleAPI, "api is not declared, Buildpack API %s is assumed", AssumedBuildpackAPIVersion)
+	case opts.BuildpackAPI != nil && !opts.BuildpackAPI.SupportsVersion(bpd.API):
+		report(SeverityError, RuleAPI, "Buildpack API %s is not supported by the lifecycle, which supports Buildpack API %s", style.Symbol(bpd.API.String()), style.Symbol(opts.BuildpackAPI.String()))
+	}
+
+	switch {
+	case bpd.Info.ID == "":
+		report(SeverityError, RuleID, "buildpack ID is empty")
+	case !buildpackIDRegexp.MatchString(bpd.Info.ID):
+		report(SeverityError, RuleID, "buildpack ID %s may only contain letters, numbers and the characters '.', '/' and '-'", style.Symbol(bpd.Info.ID))
+	case isReservedBuildpackID(bpd.Info.ID):
+		report(SeverityError, RuleID, "buildpack ID %s is reserved", style.Symbol(bpd.Info.ID))
+	}
+
+	if bpd.Info.Version == "" {
+		report(SeverityError, RuleVersion, "buildpack version is empty")
+	} else if _, err := semver.NewVersion(bpd.Info.Version); err != nil {
+		report(SeverityWarning, RuleVersion, "version %s is not a semantic version, version ranges will not resolve to it", style.Symbol(bpd.Info.Version))
+	}
+
+	if err := validateDescriptor(bpd); err != nil {
+		report(SeverityError, RuleStacksOrder, err.Error())
+	}
+
+	for _, stack := range bpd.Stacks {
+		if stack.ID == "" {
+			report(SeverityError, RuleStacksOrder, "stack ID is empty")
+		}
+	}
+
+	for i, entry := range bpd.Order {
+		for _, ref := range entry.Group {
+			if ref.ID == "" {
+				report(SeverityError, RuleStacksOrder, "group %d of the order contains a buildpack without an ID", i+1)
+			}
+		}
+	}
+
+	if len(bpd.Order) == 0 {
+		for _, executable := range RequiredExecutables {
+			header, ok := headers[executable]
+			if !ok {
+				report(SeverityError, RuleExecutables, "%s is missing", style.Symbol(executable))
+			} else if !isExecutable(header) {
+				report(SeverityError, RuleExecutables, "%s is not executable", style.Symbol(executable))
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// LintErrors returns the findings with SeverityError.
+func LintErrors(findings []Finding) []Finding {
+	var errs []Finding
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			errs = append(errs, f)
+		}
+	}
+	return errs
+}
+
+func isReservedBuildpackID(id string) bool {
+	for _, reserved := range reservedBuildpackIDs {
+		if id == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+func isExecutable(header *tar.Header) bool {
+	switch header.Typeflag {
+	case tar.TypeSymlink:
+		return true
+	case tar.TypeReg, tar.TypeRegA:
+		return header.Mode&0111 != 0
+	}
+	return false
+}
+
+// buildpackEntries reads the tar stream of a buildpack and returns its headers by path, along with the contents of
+// buildpack.toml or nil when it is absent.
+func buildpackEntries(r io.Reader) (map[string]*tar.Header, []byte, error) {
+	headers := map[string]*tar.Header{}
+	var descriptor []byte
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to get next tar entry")
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		headers[name] = header
+
+		if name == "buildpack.toml" {
+			if descriptor, err = ioutil.ReadAll(tr); err != nil {
+				return nil, nil, errors.Wrap(err, "reading buildpack.toml")
+			}
+		}
+	}
+
+	return headers, descriptor, nil
+}
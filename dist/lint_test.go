@@ -0,0 +1,150 @@
+package dist_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/dist"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestLint(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "lint", testLint, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testLint(t *testing.T, when spec.G, it spec.S) {
+	var tmpBpDir string
+
+	writeDescriptor := func(contents string) {
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "buildpack.toml"), []byte(contents), os.ModePerm))
+	}
+
+	writeExecutables := func(mode os.FileMode) {
+		h.AssertNil(t, os.MkdirAll(filepath.Join(tmpBpDir, "bin"), os.ModePerm))
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "bin", "detect"), []byte("detect"), mode))
+		h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "bin", "build"), []byte("build"), mode))
+	}
+
+	lint := func(opts dist.LintOptions) []dist.Finding {
+		findings, err := dist.Lint(blob.NewBlob(tmpBpDir), opts)
+		h.AssertNil(t, err)
+		return findings
+	}
+
+	it.Before(func() {
+		var err error
+		tmpBpDir, err = ioutil.TempDir("", "")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpBpDir))
+	})
+
+	when("#Lint", func() {
+		it("finds nothing wrong with a valid buildpack", func() {
+			writeDescriptor(`
+api = "0.2"
+
+[buildpack]
+id = "bp.one"
+version = "1.2.3"
+
+[[stacks]]
+id = "some.stack.id"
+`)
+			writeExecutables(0755)
+
+			h.AssertEq(t, len(lint(dist.LintOptions{BuildpackAPI: api.MustParse("0.2")})), 0)
+		})
+
+		it("reports a missing buildpack.toml", func() {
+			h.AssertEq(t, lint(dist.LintOptions{}), []dist.Finding{
+				{Severity: dist.SeverityError, Rule: dist.RuleDescriptor, Message: "buildpack.toml is missing"},
+			})
+		})
+
+		it("reports problems with the descriptor and the executables", func() {
+			writeDescriptor(`
+[buildpack]
+id = "bp one"
+version = ""
+`)
+			h.AssertNil(t, os.MkdirAll(filepath.Join(tmpBpDir, "bin"), os.ModePerm))
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpBpDir, "bin", "build"), []byte("build"), 0644))
+
+			h.AssertEq(t, lint(dist.LintOptions{}), []dist.Finding{
+				{Severity: dist.SeverityWarning, Rule: dist.RuleAPI, Message: "api is not declared, Buildpack API 0.1 is assumed"},
+				{Severity: dist.SeverityError, Rule: dist.RuleID, Message: "buildpack ID 'bp one' may only contain letters, numbers and the characters '.', '/' and '-'"},
+				{Severity: dist.SeverityError, Rule: dist.RuleVersion, Message: "buildpack version is empty"},
+				{Severity: dist.SeverityError, Rule: dist.RuleStacksOrder, Message: "buildpack 'bp one@' must have either stacks or an order defined"},
+				{Severity: dist.SeverityError, Rule: dist.RuleExecutables, Message: "'bin/detect' is missing"},
+				{Severity: dist.SeverityError, Rule: dist.RuleExecutables, Message: "'bin/build' is not executable"},
+			})
+		})
+
+		it("reports a buildpack API that the lifecycle does not support", func() {
+			writeDescriptor(`
+api = "0.3"
+
+[buildpack]
+id = "bp.one"
+version = "1.2.3"
+
+[[stacks]]
+id = "some.stack.id"
+`)
+			writeExecutables(0755)
+
+			h.AssertEq(t, lint(dist.LintOptions{BuildpackAPI: api.MustParse("0.2")}), []dist.Finding{
+				{Severity: dist.SeverityError, Rule: dist.RuleAPI, Message: "Buildpack API '0.3' is not supported by the lifecycle, which supports Buildpack API '0.2'"},
+			})
+		})
+
+		it("warns about versions that are not semantic versions", func() {
+			writeDescriptor(`
+api = "0.2"
+
+[buildpack]
+id = "bp.one"
+version = "latest"
+
+[[order]]
+[[order.group]]
+  id = "bp.nested"
+`)
+
+			h.AssertEq(t, lint(dist.LintOptions{}), []dist.Finding{
+				{Severity: dist.SeverityWarning, Rule: dist.RuleVersion, Message: "version 'latest' is not a semantic version, version ranges will not resolve to it"},
+			})
+		})
+
+		it("reports reserved IDs", func() {
+			writeDescriptor(`
+api = "0.2"
+
+[buildpack]
+id = "app"
+version = "1.2.3"
+
+[[stacks]]
+id = "some.stack.id"
+`)
+			writeExecutables(0755)
+
+			h.AssertEq(t, lint(dist.LintOptions{}), []dist.Finding{
+				{Severity: dist.SeverityError, Rule: dist.RuleID, Message: "buildpack ID 'app' is reserved"},
+			})
+		})
+	})
+}
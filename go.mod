@@ -9,6 +9,7 @@ require (
 	github.com/dgodd/dockerdial v1.0.1
 	github.com/docker/docker v0.7.3-0.20190307005417-54dddadc7d5d
 	github.com/docker/go-connections v0.4.0
+	github.com/docker/go-units v0.4.0
 	github.com/golang/mock v1.3.1
 	github.com/google/go-cmp v0.3.0
 	github.com/google/go-containerregistry v0.0.0-20190503220729-1c6c7f61e8a5
@@ -20,6 +21,7 @@ require (
 	github.com/sclevine/spec v1.2.0
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/pflag v1.0.3 // indirect
+	gopkg.in/yaml.v2 v2.2.1
 )
 
 go 1.13
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
 	"github.com/buildpack/imgutil"
@@ -13,6 +14,7 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/pkg/errors"
 
 	"github.com/buildpack/pack/logging"
@@ -20,15 +22,30 @@ import (
 )
 
 type Fetcher struct {
-	docker *client.Client
-	logger logging.Logger
+	docker    *client.Client
+	logger    logging.Logger
+	transport http.RoundTripper
 }
 
-func NewFetcher(logger logging.Logger, docker *client.Client) *Fetcher {
-	return &Fetcher{
-		logger: logger,
-		docker: docker,
+type FetcherOption func(f *Fetcher)
+
+// WithTransport sets the transport used to talk to registries, http.DefaultTransport by default.
+func WithTransport(rt http.RoundTripper) FetcherOption {
+	return func(f *Fetcher) {
+		f.transport = rt
+	}
+}
+
+func NewFetcher(logger logging.Logger, docker *client.Client, opts ...FetcherOption) *Fetcher {
+	f := &Fetcher{
+		logger:    logger,
+		docker:    docker,
+		transport: http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(f)
 	}
+	return f
 }
 
 var ErrNotFound = errors.New("not found")
@@ -38,9 +55,20 @@ func (f *Fetcher) Fetch(ctx context.Context, name string, daemon, pull bool) (im
 		return f.fetchDaemonImage(name)
 	}
 
-	image, err = imgutil.NewRemoteImage(name, authn.DefaultKeychain)
+	image, err = NewRemoteImage(name, authn.DefaultKeychain, f.transport)
 	if err != nil {
-		return nil, err
+		if !daemon || errorCode(err) != transport.UnauthorizedErrorCode {
+			return nil, err
+		}
+
+		// Images that only exist on the daemon, such as locally built builders, are often refused by registries
+		// rather than not found.
+		f.logger.Debugf("Not pulling image %s: %s", style.Symbol(name), err)
+		daemonImage, daemonErr := f.fetchDaemonImage(name)
+		if errors.Cause(daemonErr) == ErrNotFound {
+			return nil, err
+		}
+		return daemonImage, daemonErr
 	}
 
 	remoteFound := image.Found()
@@ -63,7 +91,7 @@ func (f *Fetcher) Fetch(ctx context.Context, name string, daemon, pull bool) (im
 }
 
 func (f *Fetcher) fetchDaemonImage(name string) (imgutil.Image, error) {
-	image, err := imgutil.NewLocalImage(name, f.docker)
+	image, err := NewLocalImage(name, f.docker, f.logger)
 	if err != nil {
 		return nil, err
 	}
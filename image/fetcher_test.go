@@ -15,6 +15,7 @@ import (
 	"github.com/sclevine/spec/report"
 
 	"github.com/buildpack/pack/image"
+	"github.com/buildpack/pack/internal/archive"
 	"github.com/buildpack/pack/internal/fakes"
 	h "github.com/buildpack/pack/testhelpers"
 )
@@ -186,4 +187,116 @@ func testFetcher(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 	})
+
+	when("#FetchLayers", func() {
+		var dockerFile string
+
+		it.Before(func() {
+			dockerFile = "FROM scratch\nCOPY Dockerfile /Dockerfile\nLABEL repo_name=" + repoName
+		})
+
+		when("daemon is false", func() {
+			it.Before(func() {
+				h.CreateImageOnRemote(t, docker, registryConfig, repo, dockerFile)
+			})
+
+			it("returns the layers of the remote image", func() {
+				layers, err := fetcher.FetchLayers(context.TODO(), repoName, false)
+				h.AssertNil(t, err)
+				h.AssertEq(t, len(layers), 1)
+				h.AssertContains(t, layers[0].DiffID, "sha256:")
+				h.AssertEq(t, layers[0].Size > 0, true)
+			})
+
+			it("allows fetched images to read their layers", func() {
+				layers, err := fetcher.FetchLayers(context.TODO(), repoName, false)
+				h.AssertNil(t, err)
+
+				img, err := fetcher.Fetch(context.TODO(), repoName, false, false)
+				h.AssertNil(t, err)
+
+				rc, err := img.GetLayer(layers[0].DiffID)
+				h.AssertNil(t, err)
+				defer rc.Close()
+
+				_, contents, err := archive.ReadTarEntry(rc, "Dockerfile")
+				h.AssertNil(t, err)
+				h.AssertEq(t, string(contents), dockerFile)
+			})
+		})
+
+		when("daemon is true", func() {
+			it.Before(func() {
+				h.CreateImageOnLocal(t, docker, repoName, dockerFile)
+			})
+
+			it.After(func() {
+				h.DockerRmi(docker, repoName)
+			})
+
+			it("returns the layers of the local image", func() {
+				layers, err := fetcher.FetchLayers(context.TODO(), repoName, true)
+				h.AssertNil(t, err)
+				h.AssertEq(t, len(layers), 1)
+				h.AssertContains(t, layers[0].DiffID, "sha256:")
+				h.AssertEq(t, layers[0].Size > 0, true)
+			})
+
+			when("there is no local image", func() {
+				it("returns an error", func() {
+					_, err := fetcher.FetchLayers(context.TODO(), "missing/"+repo, true)
+					h.AssertError(t, err, "does not exist on the daemon")
+				})
+			})
+		})
+	})
+
+	when("#FetchDiffIDs", func() {
+		var dockerFile string
+
+		it.Before(func() {
+			dockerFile = "FROM scratch\nCOPY Dockerfile /Dockerfile\nLABEL repo_name=" + repoName
+		})
+
+		when("daemon is false", func() {
+			it.Before(func() {
+				h.CreateImageOnRemote(t, docker, registryConfig, repo, dockerFile)
+			})
+
+			it("returns the diff IDs of the layers of the remote image", func() {
+				diffIDs, err := fetcher.FetchDiffIDs(context.TODO(), repoName, false)
+				h.AssertNil(t, err)
+
+				layers, err := fetcher.FetchLayers(context.TODO(), repoName, false)
+				h.AssertNil(t, err)
+				h.AssertEq(t, diffIDs, []string{layers[0].DiffID})
+			})
+		})
+
+		when("daemon is true", func() {
+			it.Before(func() {
+				h.CreateImageOnLocal(t, docker, repoName, dockerFile)
+			})
+
+			it.After(func() {
+				h.DockerRmi(docker, repoName)
+			})
+
+			it("returns the diff IDs of the layers of the local image", func() {
+				diffIDs, err := fetcher.FetchDiffIDs(context.TODO(), repoName, true)
+				h.AssertNil(t, err)
+
+				layers, err := fetcher.FetchLayers(context.TODO(), repoName, true)
+				h.AssertNil(t, err)
+				h.AssertEq(t, diffIDs, []string{layers[0].DiffID})
+			})
+
+			when("there is no local image", func() {
+				it("returns an error", func() {
+					_, err := fetcher.FetchDiffIDs(context.TODO(), "missing/"+repo, true)
+					h.AssertError(t, err, "does not exist on the daemon")
+				})
+			})
+		})
+	})
 }
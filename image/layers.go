@@ -0,0 +1,137 @@
+package image
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// Layer describes a layer of an image.
+type Layer struct {
+	DiffID string
+	// Size is the uncompressed size of the layer for daemon images and its compressed size for registry images.
+	Size int64
+}
+
+// FetchLayers returns the layers of an image, starting from its base.
+func (f *Fetcher) FetchLayers(ctx context.Context, name string, daemon bool) ([]Layer, error) {
+	if daemon {
+		return f.fetchDaemonLayers(ctx, name)
+	}
+
+	img, err := f.fetchRegistryImage(name)
+	if err != nil {
+		return nil, err
+	}
+
+	registryLayers, err := img.Layers()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading layers of image %s", style.Symbol(name))
+	}
+
+	var layers []Layer
+	for _, layer := range registryLayers {
+		diffID, err := layer.DiffID()
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := layer.Size()
+		if err != nil {
+			return nil, err
+		}
+
+		layers = append(layers, Layer{DiffID: diffID.String(), Size: size})
+	}
+	return layers, nil
+}
+
+// FetchDiffIDs returns the diff IDs of the layers of an image, starting from its base. Unlike FetchLayers, it only
+// reads the config of the image.
+func (f *Fetcher) FetchDiffIDs(ctx context.Context, name string, daemon bool) ([]string, error) {
+	if daemon {
+		inspect, err := f.inspectDaemonImage(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return inspect.RootFS.Layers, nil
+	}
+
+	img, err := f.fetchRegistryImage(name)
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading config of image %s", style.Symbol(name))
+	}
+
+	var diffIDs []string
+	for _, diffID := range configFile.RootFS.DiffIDs {
+		diffIDs = append(diffIDs, diffID.String())
+	}
+	return diffIDs, nil
+}
+
+func (f *Fetcher) fetchDaemonLayers(ctx context.Context, name string) ([]Layer, error) {
+	inspect, err := f.inspectDaemonImage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := f.docker.ImageHistory(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading history of image %s", style.Symbol(name))
+	}
+
+	// The history lists the size of the layer added by each entry, newest first, and a size of zero for entries that
+	// add no layer. Layers without files are also sized zero, so entries of size zero are only counted as layers once
+	// the remaining entries are needed for the remaining layers.
+	var layers []Layer
+	for i := len(history) - 1; i >= 0 && len(layers) < len(inspect.RootFS.Layers); i-- {
+		remainingLayers := len(inspect.RootFS.Layers) - len(layers)
+		if history[i].Size == 0 && i+1 > remainingLayers {
+			continue
+		}
+		layers = append(layers, Layer{DiffID: inspect.RootFS.Layers[len(layers)], Size: history[i].Size})
+	}
+
+	if len(layers) != len(inspect.RootFS.Layers) {
+		return nil, fmt.Errorf("history of image %s does not account for its %d layers", style.Symbol(name), len(inspect.RootFS.Layers))
+	}
+	return layers, nil
+}
+
+func (f *Fetcher) inspectDaemonImage(ctx context.Context, name string) (types.ImageInspect, error) {
+	inspect, _, err := f.docker.ImageInspectWithRaw(ctx, name)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return types.ImageInspect{}, errors.Wrapf(ErrNotFound, "image %s does not exist on the daemon", style.Symbol(name))
+		}
+		return types.ImageInspect{}, err
+	}
+	return inspect, nil
+}
+
+func (f *Fetcher) fetchRegistryImage(imageName string) (v1.Image, error) {
+	ref, err := name.ParseReference(imageName, name.WeakValidation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing image name %s", style.Symbol(imageName))
+	}
+
+	img, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithTransport(f.transport))
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching image %s from registry", style.Symbol(imageName))
+	}
+	return img, nil
+}
@@ -0,0 +1,256 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/buildpack/imgutil"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/internal/archive"
+	"github.com/buildpack/pack/logging"
+	"github.com/buildpack/pack/style"
+)
+
+// localImage is an image on the daemon. Unlike imgutil's local images, which always record the time of the save as
+// their creation time, its creation time can be fixed so that saving the same image twice gives the same image ID.
+type localImage struct {
+	imgutil.Image
+	docker *client.Client
+	logger logging.Logger
+	// baseConfig is the config of the image as found on the daemon, whose architecture and history imgutil does not
+	// keep when saving. It is nil when the image did not exist.
+	baseConfig *v1.ConfigFile
+	createdAt  *time.Time
+}
+
+// NewLocalImage returns the image repoName from the daemon, which is not found when it does not exist yet.
+func NewLocalImage(repoName string, docker *client.Client, logger logging.Logger) (imgutil.Image, error) {
+	image, err := imgutil.NewLocalImage(repoName, docker)
+	if err != nil {
+		return nil, err
+	}
+
+	local := &localImage{Image: image, docker: docker, logger: logger}
+	if image.Found() {
+		ctx := context.Background()
+
+		_, local.baseConfig, err = inspectConfig(ctx, docker, repoName)
+		if err != nil {
+			return nil, err
+		}
+
+		local.baseConfig.History, err = daemonHistory(ctx, docker, repoName, len(local.baseConfig.RootFS.DiffIDs))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return local, nil
+}
+
+// SetCreatedAt sets the creation time of the image and of every entry of its history when it is saved, instead of
+// the time of the save.
+func (l *localImage) SetCreatedAt(t time.Time) error {
+	l.createdAt = &t
+	return nil
+}
+
+func (l *localImage) Save() (string, error) {
+	imageID, err := l.Image.Save()
+	if err != nil || l.createdAt == nil {
+		return imageID, err
+	}
+
+	return l.setCreatedAt(imageID)
+}
+
+// setCreatedAt loads a copy of the saved image imageID whose config records the fixed creation time, and returns
+// the ID of the copy. The config of the copy is the config of the base image with the runtime config and layers of
+// the saved image, so that its architecture and history are kept.
+func (l *localImage) setCreatedAt(imageID string) (string, error) {
+	ctx := context.Background()
+
+	inspect, saved, err := inspectConfig(ctx, l.docker, imageID)
+	if err != nil {
+		return "", errors.Wrapf(err, "inspecting image %s", style.Symbol(l.Name()))
+	}
+
+	configFile := *saved
+	if l.baseConfig != nil {
+		configFile = *l.baseConfig
+		configFile.Config = saved.Config
+		configFile.RootFS = saved.RootFS
+	}
+	configFile.History = layerHistory(configFile, l.baseConfig)
+
+	configFile.Created = v1.Time{Time: l.createdAt.UTC()}
+	for i := range configFile.History {
+		configFile.History[i].Created = configFile.Created
+	}
+
+	config, err := json.Marshal(configFile)
+	if err != nil {
+		return "", err
+	}
+
+	newImageID := fmt.Sprintf("%x", sha256.Sum256(config))
+	if "sha256:"+newImageID == inspect.ID {
+		return newImageID, nil
+	}
+
+	tag, err := name.NewTag(l.Name(), name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+
+	// Like imgutil, the tar leaves out the layers, which are already on the daemon, and lists them as empty paths.
+	// The daemon only reads a layer from the tar when it has no layer with the same chain ID, which it does not
+	// document, so loading the config alone depends on it.
+	manifest, err := json.Marshal([]map[string]interface{}{
+		{
+			"Config":   newImageID + ".json",
+			"RepoTags": []string{tag.String()},
+			"Layers":   make([]string, len(inspect.RootFS.Layers)),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	if err := archive.AddFileToTar(tw, newImageID+".json", string(config)); err != nil {
+		return "", err
+	}
+	if err := archive.AddFileToTar(tw, "manifest.json", string(manifest)); err != nil {
+		return "", err
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+
+	res, err := l.docker.ImageLoad(ctx, buf, true)
+	if err != nil {
+		return "", errors.Wrapf(err, "loading image %s", style.Symbol(l.Name()))
+	}
+	defer res.Body.Close()
+	if _, err := ioutil.ReadAll(res.Body); err != nil {
+		return "", err
+	}
+
+	if _, _, err := l.docker.ImageInspectWithRaw(ctx, newImageID); err != nil {
+		return "", errors.Wrapf(err, "saving image %s", style.Symbol(l.Name()))
+	}
+
+	// The image saved with the time of the save is left untagged, remove it if nothing else uses it.
+	if _, err := l.docker.ImageRemove(ctx, inspect.ID, types.ImageRemoveOptions{}); err != nil {
+		l.logger.Debugf("Not removing image %s: %s", style.Symbol(inspect.ID), err)
+	}
+
+	return newImageID, nil
+}
+
+// inspectConfig returns the inspect of the image ref on the daemon and its config without history. The daemon does
+// not return image configs, so the config is read from the fields of the inspect.
+func inspectConfig(ctx context.Context, docker *client.Client, ref string) (types.ImageInspect, *v1.ConfigFile, error) {
+	inspect, raw, err := docker.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	var configs struct {
+		Config          v1.Config
+		ContainerConfig v1.Config
+	}
+	if err := json.Unmarshal(raw, &configs); err != nil {
+		return types.ImageInspect{}, nil, errors.Wrapf(err, "reading config of image %s", style.Symbol(ref))
+	}
+
+	configFile := &v1.ConfigFile{
+		Architecture:    inspect.Architecture,
+		Author:          inspect.Author,
+		Container:       inspect.Container,
+		DockerVersion:   inspect.DockerVersion,
+		OS:              inspect.Os,
+		OSVersion:       inspect.OsVersion,
+		RootFS:          v1.RootFS{Type: inspect.RootFS.Type},
+		Config:          configs.Config,
+		ContainerConfig: configs.ContainerConfig,
+	}
+	for _, layer := range inspect.RootFS.Layers {
+		diffID, err := v1.NewHash(layer)
+		if err != nil {
+			return types.ImageInspect{}, nil, errors.Wrapf(err, "reading layers of image %s", style.Symbol(ref))
+		}
+		configFile.RootFS.DiffIDs = append(configFile.RootFS.DiffIDs, diffID)
+	}
+	return inspect, configFile, nil
+}
+
+// daemonHistory returns the history of the image ref on the daemon, oldest entry first. The daemon only reports the
+// size of the layer each entry added, so entries without one are taken to be empty layers. The history is nil when
+// that does not account for all layers of the image.
+func daemonHistory(ctx context.Context, docker *client.Client, ref string, layers int) ([]v1.History, error) {
+	items, err := docker.ImageHistory(ctx, ref)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading history of image %s", style.Symbol(ref))
+	}
+
+	var history []v1.History
+	for i := len(items) - 1; i >= 0; i-- {
+		history = append(history, v1.History{
+			Created:    v1.Time{Time: time.Unix(items[i].Created, 0).UTC()},
+			CreatedBy:  items[i].CreatedBy,
+			Comment:    items[i].Comment,
+			EmptyLayer: items[i].Size == 0,
+		})
+		if !history[len(history)-1].EmptyLayer {
+			layers--
+		}
+	}
+
+	if layers != 0 {
+		return nil, nil
+	}
+	return history, nil
+}
+
+// layerHistory returns the history of the base image followed by an entry for each layer added to it. Images whose
+// layers do not start with the layers of the base, such as rebased images, get an entry for each layer instead.
+func layerHistory(configFile v1.ConfigFile, base *v1.ConfigFile) []v1.History {
+	diffIDs := configFile.RootFS.DiffIDs
+
+	var history []v1.History
+	if base != nil && base.History != nil && hasLayers(diffIDs, base.RootFS.DiffIDs) {
+		history = append(history, base.History...)
+		diffIDs = diffIDs[len(base.RootFS.DiffIDs):]
+	}
+
+	for range diffIDs {
+		history = append(history, v1.History{})
+	}
+	return history
+}
+
+// hasLayers returns whether diffIDs starts with the layers baseDiffIDs.
+func hasLayers(diffIDs, baseDiffIDs []v1.Hash) bool {
+	if len(diffIDs) < len(baseDiffIDs) {
+		return false
+	}
+	for i, diffID := range baseDiffIDs {
+		if diffIDs[i] != diffID {
+			return false
+		}
+	}
+	return true
+}
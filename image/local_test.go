@@ -0,0 +1,107 @@
+package image_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildpack/imgutil"
+	"github.com/docker/docker/client"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/image"
+	"github.com/buildpack/pack/internal/archive"
+	"github.com/buildpack/pack/internal/fakes"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestLocalImage(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+
+	h.RequireDocker(t)
+
+	var err error
+	docker, err = client.NewClientWithOpts(client.FromEnv, client.WithVersion("1.38"))
+	h.AssertNil(t, err)
+	spec.Run(t, "LocalImage", testLocalImage, spec.Report(report.Terminal{}))
+}
+
+func testLocalImage(t *testing.T, when spec.G, it spec.S) {
+	var (
+		repoName  string
+		layerPath string
+		tmpDir    string
+	)
+
+	it.Before(func() {
+		repoName = "some-org/" + h.RandString(10)
+		h.CreateImageOnLocal(t, docker, repoName, "FROM scratch\nCOPY Dockerfile /\nLABEL some-label=some-value\n")
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "local-image-test")
+		h.AssertNil(t, err)
+
+		layerPath = filepath.Join(tmpDir, "layer.tar")
+		h.AssertNil(t, archive.CreateSingleFileTar(layerPath, "/some-file", "some-contents"))
+	})
+
+	it.After(func() {
+		h.DockerRmi(docker, repoName, repoName+"-first", repoName+"-second")
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	when("#SetCreatedAt", func() {
+		var save = func(target string) string {
+			t.Helper()
+
+			img, err := image.NewLocalImage(repoName, docker, fakes.NewFakeLogger(ioutil.Discard))
+			h.AssertNil(t, err)
+
+			img.Rename(target)
+			h.AssertNil(t, img.SetLabel("other-label", "other-value"))
+			h.AssertNil(t, img.AddLayer(layerPath))
+			h.AssertNil(t, img.(createdAtSetter).SetCreatedAt(archive.NormalizedDateTime))
+
+			imageID, err := img.Save()
+			h.AssertNil(t, err)
+			return imageID
+		}
+
+		it("saves the same image ID each time", func() {
+			h.AssertEq(t, save(repoName+"-first"), save(repoName+"-second"))
+		})
+
+		it("keeps the architecture and history of the image", func() {
+			save(repoName + "-first")
+
+			base, _, err := docker.ImageInspectWithRaw(context.TODO(), repoName)
+			h.AssertNil(t, err)
+
+			saved, _, err := docker.ImageInspectWithRaw(context.TODO(), repoName+"-first")
+			h.AssertNil(t, err)
+			h.AssertEq(t, saved.Architecture, base.Architecture)
+			h.AssertEq(t, saved.Config.Labels["other-label"], "other-value")
+
+			history, err := docker.ImageHistory(context.TODO(), repoName+"-first")
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(history), 3)
+			h.AssertContains(t, history[2].CreatedBy, "COPY")
+			h.AssertContains(t, history[1].CreatedBy, "LABEL")
+			h.AssertEq(t, history[1].Size, int64(0))
+			for _, item := range history {
+				h.AssertEq(t, time.Unix(item.Created, 0).UTC(), archive.NormalizedDateTime)
+			}
+		})
+	})
+}
+
+type createdAtSetter interface {
+	imgutil.Image
+	SetCreatedAt(time.Time) error
+}
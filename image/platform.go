@@ -0,0 +1,65 @@
+package image
+
+import (
+	"context"
+
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+const (
+	DefaultOS           = "linux"
+	DefaultArchitecture = "amd64"
+)
+
+// Platform describes the operating system and CPU architecture an image was built for.
+type Platform struct {
+	OS           string
+	Architecture string
+}
+
+// String returns the platform as <os>/<architecture>.
+func (p Platform) String() string {
+	return p.OS + "/" + p.Architecture
+}
+
+// FetchPlatform returns the platform of an image from the daemon if daemon is true or from a registry otherwise.
+// Platforms missing from the image config are assumed to be linux/amd64.
+func (f *Fetcher) FetchPlatform(ctx context.Context, name string, daemon bool) (Platform, error) {
+	var platform Platform
+	if daemon {
+		inspect, _, err := f.docker.ImageInspectWithRaw(ctx, name)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				return Platform{}, errors.Wrapf(ErrNotFound, "image %s does not exist on the daemon", style.Symbol(name))
+			}
+			return Platform{}, err
+		}
+		platform = Platform{OS: inspect.Os, Architecture: inspect.Architecture}
+	} else {
+		img, err := f.fetchRegistryImage(name)
+		if err != nil {
+			return Platform{}, err
+		}
+
+		configFile, err := img.ConfigFile()
+		if err != nil {
+			return Platform{}, errors.Wrapf(err, "reading config of image %s", style.Symbol(name))
+		}
+		platform = Platform{OS: configFile.OS, Architecture: configFile.Architecture}
+	}
+
+	return withPlatformDefaults(platform), nil
+}
+
+func withPlatformDefaults(platform Platform) Platform {
+	if platform.OS == "" {
+		platform.OS = DefaultOS
+	}
+	if platform.Architecture == "" {
+		platform.Architecture = DefaultArchitecture
+	}
+	return platform
+}
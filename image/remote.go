@@ -0,0 +1,399 @@
+package image
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buildpack/imgutil"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// remoteImage is an image in a registry. Unlike imgutil's remote images, it talks to the registry through the
+// transport it is given rather than http.DefaultTransport, and its layers can be read. imgutil does not let the
+// transport of its images be replaced, so the image is kept here.
+type remoteImage struct {
+	keychain   authn.Keychain
+	transport  http.RoundTripper
+	repoName   string
+	image      v1.Image
+	found      bool
+	prevLayers []v1.Layer
+	prevOnce   *sync.Once
+	createdAt  *time.Time
+}
+
+// NewRemoteImage returns the image repoName from its registry, or an empty image when it does not exist yet. A nil
+// transport uses http.DefaultTransport.
+func NewRemoteImage(repoName string, keychain authn.Keychain, rt http.RoundTripper) (imgutil.Image, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	r := &remoteImage{
+		keychain:  keychain,
+		transport: rt,
+		repoName:  repoName,
+		prevOnce:  &sync.Once{},
+	}
+
+	image, found, err := r.fetch()
+	if err != nil {
+		return nil, err
+	}
+	r.image = image
+	r.found = found
+	return r, nil
+}
+
+// fetch returns the image repoName from the registry and whether it was found, or an empty image when it does not
+// exist.
+func (r *remoteImage) fetch() (v1.Image, bool, error) {
+	ref, auth, err := r.reference()
+	if err != nil {
+		return nil, false, err
+	}
+
+	image, err := remote.Image(ref, remote.WithAuth(auth), remote.WithTransport(r.transport))
+	if err != nil {
+		switch errorCode(err) {
+		case transport.ManifestUnknownErrorCode, transport.NameUnknownErrorCode:
+			return empty.Image, false, nil
+		case transport.UnauthorizedErrorCode:
+			// Registries such as Docker Hub refuse access to repositories that do not exist even with valid
+			// credentials. Without credentials, the registry may as well require them.
+			if auth != authn.Anonymous {
+				return empty.Image, false, nil
+			}
+			return nil, false, errors.Wrapf(err, "no credentials to read image %s", style.Symbol(r.repoName))
+		}
+		return nil, false, errors.Wrapf(err, "connect to repo store %s", style.Symbol(r.repoName))
+	}
+	return image, true, nil
+}
+
+// errorCode returns the code of the first error returned by a registry, or an empty code for other errors.
+func errorCode(err error) transport.ErrorCode {
+	if transportErr, ok := errors.Cause(err).(*transport.Error); ok && len(transportErr.Errors) > 0 {
+		return transportErr.Errors[0].Code
+	}
+	return ""
+}
+
+func (r *remoteImage) reference() (name.Reference, authn.Authenticator, error) {
+	ref, err := name.ParseReference(r.repoName, name.WeakValidation)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "parsing image name %s", style.Symbol(r.repoName))
+	}
+
+	auth, err := r.keychain.Resolve(ref.Context().Registry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ref, auth, nil
+}
+
+func (r *remoteImage) configFile() (*v1.ConfigFile, error) {
+	cfg, err := r.image.ConfigFile()
+	if err != nil || cfg == nil {
+		return nil, fmt.Errorf("failed to get config file for image %s", style.Symbol(r.repoName))
+	}
+	return cfg, nil
+}
+
+func (r *remoteImage) Label(key string) (string, error) {
+	cfg, err := r.configFile()
+	if err != nil {
+		return "", err
+	}
+	return cfg.Config.Labels[key], nil
+}
+
+func (r *remoteImage) Env(key string) (string, error) {
+	cfg, err := r.configFile()
+	if err != nil {
+		return "", err
+	}
+	for _, envVar := range cfg.Config.Env {
+		parts := strings.SplitN(envVar, "=", 2)
+		if parts[0] == key && len(parts) == 2 {
+			return parts[1], nil
+		}
+	}
+	return "", nil
+}
+
+func (r *remoteImage) Rename(name string) {
+	r.repoName = name
+}
+
+func (r *remoteImage) Name() string {
+	return r.repoName
+}
+
+// Found returns whether the image existed in the registry when it was fetched.
+func (r *remoteImage) Found() bool {
+	return r.found
+}
+
+func (r *remoteImage) Digest() (string, error) {
+	hash, err := r.image.Digest()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get digest for image %s", style.Symbol(r.repoName))
+	}
+	return hash.String(), nil
+}
+
+func (r *remoteImage) CreatedAt() (time.Time, error) {
+	cfg, err := r.configFile()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cfg.Created.UTC(), nil
+}
+
+func (r *remoteImage) Rebase(baseTopLayer string, newBase imgutil.Image) error {
+	newBaseRemote, ok := newBase.(*remoteImage)
+	if !ok {
+		return errors.New("expected new base to be a remote image")
+	}
+
+	newImage, err := mutate.Rebase(r.image, &subImage{img: r.image, topDiffID: baseTopLayer}, newBaseRemote.image)
+	if err != nil {
+		return errors.Wrap(err, "rebase")
+	}
+	r.image = newImage
+	return nil
+}
+
+// mutateConfig applies fn to a copy of the image config.
+func (r *remoteImage) mutateConfig(fn func(config *v1.Config)) error {
+	cfg, err := r.configFile()
+	if err != nil {
+		return err
+	}
+
+	config := *cfg.Config.DeepCopy()
+	fn(&config)
+	r.image, err = mutate.Config(r.image, config)
+	return err
+}
+
+func (r *remoteImage) SetLabel(key, val string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		if config.Labels == nil {
+			config.Labels = map[string]string{}
+		}
+		config.Labels[key] = val
+	})
+}
+
+func (r *remoteImage) SetEnv(key, val string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		for i, e := range config.Env {
+			if strings.SplitN(e, "=", 2)[0] == key {
+				config.Env[i] = key + "=" + val
+				return
+			}
+		}
+		config.Env = append(config.Env, key+"="+val)
+	})
+}
+
+func (r *remoteImage) SetWorkingDir(dir string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		config.WorkingDir = dir
+	})
+}
+
+func (r *remoteImage) SetEntrypoint(ep ...string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		config.Entrypoint = ep
+	})
+}
+
+func (r *remoteImage) SetCmd(cmd ...string) error {
+	return r.mutateConfig(func(config *v1.Config) {
+		config.Cmd = cmd
+	})
+}
+
+func (r *remoteImage) TopLayer() (string, error) {
+	layers, err := r.image.Layers()
+	if err != nil {
+		return "", err
+	}
+	if len(layers) == 0 {
+		return "", fmt.Errorf("image %s has no layers", style.Symbol(r.repoName))
+	}
+
+	diffID, err := layers[len(layers)-1].DiffID()
+	if err != nil {
+		return "", err
+	}
+	return diffID.String(), nil
+}
+
+func (r *remoteImage) GetLayer(diffID string) (io.ReadCloser, error) {
+	hash, err := v1.NewHash(diffID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing diff ID %s", style.Symbol(diffID))
+	}
+
+	layer, err := r.image.LayerByDiffID(hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "image %s does not contain layer with diff ID %s", style.Symbol(r.repoName), style.Symbol(diffID))
+	}
+
+	return layer.Uncompressed()
+}
+
+func (r *remoteImage) AddLayer(path string) error {
+	layer, err := tarball.LayerFromFile(path)
+	if err != nil {
+		return err
+	}
+
+	r.image, err = mutate.AppendLayers(r.image, layer)
+	if err != nil {
+		return errors.Wrap(err, "add layer")
+	}
+	return nil
+}
+
+func (r *remoteImage) ReuseLayer(diffID string) error {
+	var outerErr error
+	r.prevOnce.Do(func() {
+		prevImage, _, err := r.fetch()
+		if err != nil {
+			outerErr = err
+			return
+		}
+
+		r.prevLayers, err = prevImage.Layers()
+		if err != nil {
+			outerErr = errors.Wrapf(err, "failed to get layers for previous image %s", style.Symbol(r.repoName))
+		}
+	})
+	if outerErr != nil {
+		return outerErr
+	}
+
+	if len(r.prevLayers) == 0 {
+		return fmt.Errorf("there is no previous image with name %s", style.Symbol(r.repoName))
+	}
+
+	layer, err := findLayerWithDiffID(r.prevLayers, diffID)
+	if err != nil {
+		return err
+	}
+
+	r.image, err = mutate.AppendLayers(r.image, layer)
+	return err
+}
+
+func findLayerWithDiffID(layers []v1.Layer, diffID string) (v1.Layer, error) {
+	for _, layer := range layers {
+		layerDiffID, err := layer.DiffID()
+		if err != nil {
+			return nil, errors.Wrap(err, "get diff ID for previous image layer")
+		}
+		if diffID == layerDiffID.String() {
+			return layer, nil
+		}
+	}
+	return nil, fmt.Errorf("previous image did not have layer with diff ID %s", style.Symbol(diffID))
+}
+
+// SetCreatedAt sets the creation time of the image and of every entry of its history when it is saved, instead of
+// the time of the save.
+func (r *remoteImage) SetCreatedAt(t time.Time) error {
+	r.createdAt = &t
+	return nil
+}
+
+func (r *remoteImage) Save() (string, error) {
+	ref, auth, err := r.reference()
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := r.configFile()
+	if err != nil {
+		return "", err
+	}
+	cfg = cfg.DeepCopy()
+	cfg.Created = v1.Time{Time: time.Now()}
+	if r.createdAt != nil {
+		cfg.Created = v1.Time{Time: *r.createdAt}
+		for i := range cfg.History {
+			cfg.History[i].Created = cfg.Created
+		}
+	}
+
+	r.image, err = mutate.ConfigFile(r.image, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	if err := remote.Write(ref, r.image, remote.WithAuth(auth), remote.WithTransport(r.transport)); err != nil {
+		return "", err
+	}
+
+	return r.Digest()
+}
+
+func (r *remoteImage) Delete() error {
+	return errors.New("remote image does not implement Delete")
+}
+
+// subImage is the part of an image up to and including a layer, as needed by mutate.Rebase.
+type subImage struct {
+	img       v1.Image
+	topDiffID string
+}
+
+func (si *subImage) Layers() ([]v1.Layer, error) {
+	all, err := si.img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range all {
+		d, err := l.DiffID()
+		if err != nil {
+			return nil, err
+		}
+		if d.String() == si.topDiffID {
+			return all[:i+1], nil
+		}
+	}
+	return nil, errors.New("could not find base layer in image")
+}
+
+// errSubImage is returned for everything but the layers of a subImage, which mutate.Rebase does not read.
+var errSubImage = errors.New("only the layers of the base of an image can be read")
+
+func (si *subImage) BlobSet() (map[v1.Hash]struct{}, error)  { return nil, errSubImage }
+func (si *subImage) MediaType() (types.MediaType, error)     { return "", errSubImage }
+func (si *subImage) ConfigName() (v1.Hash, error)            { return v1.Hash{}, errSubImage }
+func (si *subImage) ConfigFile() (*v1.ConfigFile, error)     { return nil, errSubImage }
+func (si *subImage) RawConfigFile() ([]byte, error)          { return nil, errSubImage }
+func (si *subImage) Digest() (v1.Hash, error)                { return v1.Hash{}, errSubImage }
+func (si *subImage) Manifest() (*v1.Manifest, error)         { return nil, errSubImage }
+func (si *subImage) RawManifest() ([]byte, error)            { return nil, errSubImage }
+func (si *subImage) LayerByDigest(v1.Hash) (v1.Layer, error) { return nil, errSubImage }
+func (si *subImage) LayerByDiffID(v1.Hash) (v1.Layer, error) { return nil, errSubImage }
@@ -0,0 +1,92 @@
+package image_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/image"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRemoteImage(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "RemoteImage", testRemoteImage, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testRemoteImage(t *testing.T, when spec.G, it spec.S) {
+	var (
+		server   *httptest.Server
+		status   int
+		code     string
+		repoName string
+	)
+
+	it.Before(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v2/" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"errors": [{"code": "` + code + `", "message": "some message"}]}`))
+		}))
+
+		u, err := url.Parse(server.URL)
+		h.AssertNil(t, err)
+		repoName = u.Host + "/some/image"
+	})
+
+	it.After(func() {
+		server.Close()
+	})
+
+	when("#NewRemoteImage", func() {
+		when("the image does not exist", func() {
+			it.Before(func() {
+				status, code = http.StatusNotFound, "MANIFEST_UNKNOWN"
+			})
+
+			it("returns an image that is not found", func() {
+				img, err := image.NewRemoteImage(repoName, keychain{authn.Anonymous}, nil)
+				h.AssertNil(t, err)
+				h.AssertEq(t, img.Found(), false)
+			})
+		})
+
+		when("the registry refuses access to the image", func() {
+			it.Before(func() {
+				status, code = http.StatusUnauthorized, "UNAUTHORIZED"
+			})
+
+			it("returns an image that is not found when there are credentials for the registry", func() {
+				img, err := image.NewRemoteImage(repoName, keychain{&authn.Basic{Username: "some-user", Password: "some-password"}}, nil)
+				h.AssertNil(t, err)
+				h.AssertEq(t, img.Found(), false)
+			})
+
+			it("fails when there are no credentials for the registry", func() {
+				_, err := image.NewRemoteImage(repoName, keychain{authn.Anonymous}, nil)
+				h.AssertError(t, err, "no credentials to read image '"+repoName+"'")
+			})
+		})
+	})
+}
+
+// keychain resolves every registry to the same authenticator.
+type keychain struct {
+	auth authn.Authenticator
+}
+
+func (k keychain) Resolve(name.Registry) (authn.Authenticator, error) {
+	return k.auth, nil
+}
@@ -0,0 +1,114 @@
+package image
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/style"
+)
+
+// RegistryConfig holds the registry settings honored when pack talks to registries itself.
+type RegistryConfig struct {
+	// InsecureRegistries are registry hosts (e.g. `registry.local:5000`) that may be reached
+	// without TLS verification, falling back to plain HTTP when HTTPS is not available.
+	InsecureRegistries []string
+	// CACertsPath is a PEM bundle of additional certificate authorities to trust.
+	CACertsPath string
+}
+
+func (c RegistryConfig) IsInsecure(registry string) bool {
+	for _, r := range c.InsecureRegistries {
+		if r == registry {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTransport returns a round tripper which trusts the configured CA certificates and
+// tolerates the configured insecure registries.
+func NewTransport(cfg RegistryConfig) (http.RoundTripper, error) {
+	secure := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.CACertsPath != "" {
+		pool, err := certPool(cfg.CACertsPath)
+		if err != nil {
+			return nil, err
+		}
+		secure.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	insecure := secure.Clone()
+	if insecure.TLSClientConfig == nil {
+		insecure.TLSClientConfig = &tls.Config{}
+	}
+	insecure.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec
+
+	return &registryTransport{
+		config:   cfg,
+		secure:   secure,
+		insecure: insecure,
+	}, nil
+}
+
+func certPool(path string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading ca-certs %s", style.Symbol(path))
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in ca-certs %s", style.Symbol(path))
+	}
+	return pool, nil
+}
+
+type registryTransport struct {
+	config   RegistryConfig
+	secure   http.RoundTripper
+	insecure http.RoundTripper
+}
+
+func (t *registryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.config.IsInsecure(req.URL.Host) {
+		return t.secure.RoundTrip(req)
+	}
+
+	resp, err := t.insecure.RoundTrip(req)
+	if err == nil || req.URL.Scheme != "https" {
+		return resp, err
+	}
+
+	// like the docker daemon, fall back to plain HTTP for insecure registries
+	fallback, ferr := httpRequest(req)
+	if ferr != nil {
+		return nil, err
+	}
+	return t.insecure.RoundTrip(fallback)
+}
+
+func httpRequest(req *http.Request) (*http.Request, error) {
+	fallback := req.Clone(req.Context())
+	fallback.URL.Scheme = "http"
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errors.New("request body cannot be replayed")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		fallback.Body = body
+	}
+	return fallback, nil
+}
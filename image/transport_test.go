@@ -0,0 +1,120 @@
+package image_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/image"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestTransport(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "Transport", testTransport, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testTransport(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tlsServer   *httptest.Server
+		plainServer *httptest.Server
+		tmpDir      string
+	)
+
+	it.Before(func() {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		tlsServer = httptest.NewTLSServer(handler)
+		plainServer = httptest.NewServer(handler)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "transport-test")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		tlsServer.Close()
+		plainServer.Close()
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	get := func(transport http.RoundTripper, uri string) error {
+		req, err := http.NewRequest("GET", uri, nil)
+		h.AssertNil(t, err)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	hostOf := func(uri string) string {
+		u, err := url.Parse(uri)
+		h.AssertNil(t, err)
+		return u.Host
+	}
+
+	when("#NewTransport", func() {
+		when("no registry config is provided", func() {
+			it("rejects untrusted certificates", func() {
+				transport, err := image.NewTransport(image.RegistryConfig{})
+				h.AssertNil(t, err)
+
+				h.AssertError(t, get(transport, tlsServer.URL), "certificate")
+			})
+		})
+
+		when("ca-certs are provided", func() {
+			it("trusts the provided certificates", func() {
+				caPath := filepath.Join(tmpDir, "ca.pem")
+				pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+				h.AssertNil(t, ioutil.WriteFile(caPath, pemBytes, 0644))
+
+				transport, err := image.NewTransport(image.RegistryConfig{CACertsPath: caPath})
+				h.AssertNil(t, err)
+
+				h.AssertNil(t, get(transport, tlsServer.URL))
+			})
+
+			it("fails when the file has no certificates", func() {
+				caPath := filepath.Join(tmpDir, "ca.pem")
+				h.AssertNil(t, ioutil.WriteFile(caPath, []byte("not a cert"), 0644))
+
+				_, err := image.NewTransport(image.RegistryConfig{CACertsPath: caPath})
+				h.AssertError(t, err, "no certificates found")
+			})
+		})
+
+		when("the registry is insecure", func() {
+			it("skips certificate verification", func() {
+				transport, err := image.NewTransport(image.RegistryConfig{
+					InsecureRegistries: []string{hostOf(tlsServer.URL)},
+				})
+				h.AssertNil(t, err)
+
+				h.AssertNil(t, get(transport, tlsServer.URL))
+			})
+
+			it("falls back to plain HTTP", func() {
+				host := hostOf(plainServer.URL)
+				transport, err := image.NewTransport(image.RegistryConfig{
+					InsecureRegistries: []string{host},
+				})
+				h.AssertNil(t, err)
+
+				h.AssertNil(t, get(transport, "https://"+host+"/v2/"))
+			})
+		})
+	})
+}
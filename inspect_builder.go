@@ -18,8 +18,13 @@ type BuilderInfo struct {
 	RunImageMirrors []string
 	Buildpacks      []builder.BuildpackMetadata
 	Order           dist.Order
+	BuildpackLayers builder.BuildpackLayers
 	Lifecycle       builder.LifecycleDescriptor
 	CreatedBy       builder.CreatorMetadata
+	// CompatPaths are the paths added to the builder for older lifecycles, if any.
+	CompatPaths []string
+	// Provenance records where the contents of the builder came from, if known.
+	Provenance *builder.Provenance
 }
 
 type BuildpackInfo struct {
@@ -49,7 +54,10 @@ func (c *Client) InspectBuilder(name string, daemon bool) (*BuilderInfo, error)
 		RunImageMirrors: bldr.GetStackInfo().RunImage.Mirrors,
 		Buildpacks:      bldr.GetBuildpacks(),
 		Order:           bldr.GetOrder(),
+		BuildpackLayers: bldr.GetBuildpackLayers(),
 		Lifecycle:       bldr.GetLifecycleDescriptor(),
 		CreatedBy:       bldr.GetCreatedBy(),
+		CompatPaths:     bldr.CompatPaths(),
+		Provenance:      bldr.GetProvenance(),
 	}, nil
 }
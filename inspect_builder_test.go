@@ -89,6 +89,11 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
   "createdBy": {"name": "pack", "version": "1.2.3"}
 }`))
 
+						h.AssertNil(t, builderImage.SetLabel(
+							"io.buildpacks.buildpack.layers",
+							`{"test.bp.one": {"1.0.0": {"layerDigest": "sha256:bp-one-digest", "order": [{"group": [{"id": "buildpack-1-id"}]}]}}}`,
+						))
+
 						h.AssertNil(t, builderImage.SetLabel(
 							"io.buildpacks.buildpack.order",
 							`[{"group": [{"id": "buildpack-1-id", "optional": false}, {"id": "buildpack-2-id", "version": "buildpack-2-version-1", "optional": true}]}]`,
@@ -157,6 +162,14 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 						})
 					})
 
+					it("sets the buildpack layers", func() {
+						builderInfo, err := subject.InspectBuilder("some/builder", useDaemon)
+						h.AssertNil(t, err)
+						bpLayer := builderInfo.BuildpackLayers["test.bp.one"]["1.0.0"]
+						h.AssertEq(t, bpLayer.LayerDigest, "sha256:bp-one-digest")
+						h.AssertEq(t, bpLayer.Order[0].Group[0].ID, "buildpack-1-id")
+					})
+
 					it("sets the lifecycle version", func() {
 						builderInfo, err := subject.InspectBuilder("some/builder", useDaemon)
 						h.AssertNil(t, err)
@@ -0,0 +1,259 @@
+package pack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/buildpackage"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/image"
+	"github.com/buildpack/pack/internal/paths"
+	"github.com/buildpack/pack/style"
+)
+
+type InspectBuildpackOptions struct {
+	// BuildpackName is a path or URI to a buildpack directory or archive or to a buildpackage file, or the name of a
+	// buildpackage image.
+	BuildpackName string
+	// Daemon selects whether buildpackage images are looked up in the docker daemon or in a registry.
+	Daemon bool
+}
+
+// InspectedBuildpack describes a buildpack, or every buildpack contained in a buildpackage.
+type InspectedBuildpack struct {
+	// Default is the inspected buildpack or, for a buildpackage, its default buildpack.
+	Default    dist.BuildpackInfo
+	Stacks     []dist.Stack
+	Buildpacks []BuildpackDetails
+}
+
+type BuildpackDetails struct {
+	Descriptor         dist.BuildpackDescriptor
+	LayerDigest        string
+	LayerSize          int64
+	MissingExecutables []string
+}
+
+// BuildpackLayers returns the buildpacks in the same form as the builder buildpack layers label, which allows
+// the order of the default buildpack to be resolved recursively.
+func (i *InspectedBuildpack) BuildpackLayers() builder.BuildpackLayers {
+	layers := builder.BuildpackLayers{}
+	for _, bp := range i.Buildpacks {
+		bpInfo := bp.Descriptor.Info
+		if _, ok := layers[bpInfo.ID]; !ok {
+			layers[bpInfo.ID] = map[string]builder.BuildpackLayerInfo{}
+		}
+		layers[bpInfo.ID][bpInfo.Version] = builder.BuildpackLayerInfo{
+			LayerDigest: bp.LayerDigest,
+			Order:       bp.Descriptor.Order,
+			Stacks:      bp.Descriptor.Stacks,
+		}
+	}
+	return layers
+}
+
+// Order returns the order of the default buildpack, if it has one.
+func (i *InspectedBuildpack) Order() dist.Order {
+	for _, bp := range i.Buildpacks {
+		if bp.Descriptor.Info == i.Default {
+			return bp.Descriptor.Order
+		}
+	}
+	return nil
+}
+
+func (c *Client) InspectBuildpack(ctx context.Context, opts InspectBuildpackOptions) (*InspectedBuildpack, error) {
+	if isBuildpackPath(opts.BuildpackName) {
+		if buildpackage.IsFile(opts.BuildpackName) {
+			return c.inspectBuildpackageFile(ctx, opts.BuildpackName)
+		}
+		return c.inspectBuildpackBlob(ctx, opts.BuildpackName)
+	}
+
+	return c.inspectBuildpackage(ctx, opts.BuildpackName, opts.Daemon)
+}
+
+func isBuildpackPath(name string) bool {
+	name, _ = blob.SplitSHA256(name)
+	if paths.IsURI(name) {
+		return true
+	}
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func (c *Client) inspectBuildpackBlob(ctx context.Context, pathOrURI string) (*InspectedBuildpack, error) {
+	blob, err := c.downloader.Download(ctx, pathOrURI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "downloading buildpack from %s", style.Symbol(pathOrURI))
+	}
+
+	bp, err := dist.NewBuildpack(blob)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating buildpack from %s", style.Symbol(pathOrURI))
+	}
+
+	tmpDir, err := ioutil.TempDir("", "inspect-buildpack")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	layerTar, err := dist.BuildpackLayer(tmpDir, 0, 0, bp)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := os.Open(layerTar)
+	if err != nil {
+		return nil, err
+	}
+	defer layer.Close()
+
+	details, err := buildpackDetails(bp, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InspectedBuildpack{
+		Default:    bp.Descriptor().Info,
+		Stacks:     bp.Descriptor().Stacks,
+		Buildpacks: []BuildpackDetails{details},
+	}, nil
+}
+
+func (c *Client) inspectBuildpackage(ctx context.Context, name string, daemon bool) (*InspectedBuildpack, error) {
+	img, err := c.imageFetcher.Fetch(ctx, name, daemon, false)
+	if err != nil {
+		if errors.Cause(err) == image.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var md buildpackage.Metadata
+	if ok, err := dist.GetLabel(img, buildpackage.MetadataLabel, &md); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.Errorf("image %s is not a buildpackage: missing label %s", style.Symbol(name), style.Symbol(buildpackage.MetadataLabel))
+	}
+
+	return inspectBuildpackageImage(img, md)
+}
+
+func (c *Client) inspectBuildpackageFile(ctx context.Context, pathOrURI string) (*InspectedBuildpack, error) {
+	blob, err := c.downloader.Download(ctx, pathOrURI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "downloading buildpackage from %s", style.Symbol(pathOrURI))
+	}
+
+	file, err := buildpackage.OpenFile(blob)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading buildpackage file %s", style.Symbol(pathOrURI))
+	}
+
+	var md buildpackage.Metadata
+	if _, err := dist.GetLabel(file, buildpackage.MetadataLabel, &md); err != nil {
+		return nil, err
+	}
+
+	return inspectBuildpackageImage(file, md)
+}
+
+// inspectBuildpackageImage describes the buildpacks of a buildpackage image or file with metadata md.
+func inspectBuildpackageImage(img buildpackageImage, md buildpackage.Metadata) (*InspectedBuildpack, error) {
+	info := &InspectedBuildpack{
+		Default: md.BuildpackInfo,
+		Stacks:  md.Stacks,
+	}
+
+	var bpLayers builder.BuildpackLayers
+	if ok, err := dist.GetLabel(img, builder.BuildpackLayersLabel, &bpLayers); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.Errorf("buildpackage is missing label %s", style.Symbol(builder.BuildpackLayersLabel))
+	}
+
+	for id, versions := range bpLayers {
+		for version, layerInfo := range versions {
+			bpInfo := dist.BuildpackInfo{ID: id, Version: version}
+			bp, err := dist.BuildpackFromLayer(&imageLayerBlob{image: img, diffID: layerInfo.LayerDigest}, bpInfo)
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading buildpack %s", style.Symbol(id+"@"+version))
+			}
+
+			layer, err := img.GetLayer(layerInfo.LayerDigest)
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading layer for buildpack %s", style.Symbol(id+"@"+version))
+			}
+
+			details, err := buildpackDetails(bp, layer)
+			layer.Close()
+			if err != nil {
+				return nil, err
+			}
+			details.LayerDigest = layerInfo.LayerDigest
+
+			info.Buildpacks = append(info.Buildpacks, details)
+		}
+	}
+
+	sort.Slice(info.Buildpacks, func(i, j int) bool {
+		a, b := info.Buildpacks[i].Descriptor.Info, info.Buildpacks[j].Descriptor.Info
+		if a.ID == b.ID {
+			return a.Version < b.Version
+		}
+		return a.ID < b.ID
+	})
+
+	return info, nil
+}
+
+// buildpackDetails describes a buildpack, computing the digest and size of its layer from layer.
+func buildpackDetails(bp dist.Buildpack, layer io.Reader) (BuildpackDetails, error) {
+	bpInfo := bp.Descriptor().Info
+
+	missing, err := dist.MissingExecutables(bp)
+	if err != nil {
+		return BuildpackDetails{}, errors.Wrapf(err, "checking executables of buildpack %s", style.Symbol(bpInfo.ID+"@"+bpInfo.Version))
+	}
+
+	digest, size, err := digestAndSize(layer)
+	if err != nil {
+		return BuildpackDetails{}, errors.Wrapf(err, "reading layer of buildpack %s", style.Symbol(bpInfo.ID+"@"+bpInfo.Version))
+	}
+
+	return BuildpackDetails{
+		Descriptor:         bp.Descriptor(),
+		LayerDigest:        digest,
+		LayerSize:          size,
+		MissingExecutables: missing,
+	}, nil
+}
+
+type imageLayerBlob struct {
+	image  buildpackageImage
+	diffID string
+}
+
+func (b *imageLayerBlob) Open() (io.ReadCloser, error) {
+	return b.image.GetLayer(b.diffID)
+}
+
+func digestAndSize(r io.Reader) (string, int64, error) {
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
@@ -0,0 +1,211 @@
+package pack_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/buildpack/imgutil/fakes"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack"
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/dist"
+	ifakes "github.com/buildpack/pack/internal/fakes"
+	"github.com/buildpack/pack/internal/logging"
+	h "github.com/buildpack/pack/testhelpers"
+	"github.com/buildpack/pack/testmocks"
+)
+
+func TestInspectBuildpack(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "InspectBuildpack", testInspectBuildpack, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testInspectBuildpack(t *testing.T, when spec.G, it spec.S) {
+	var (
+		client           *pack.Client
+		mockController   *gomock.Controller
+		mockDownloader   *testmocks.MockDownloader
+		fakeImageFetcher *ifakes.FakeImageFetcher
+		tmpDir           string
+		out              bytes.Buffer
+	)
+
+	newBuildpack := func(descriptor dist.BuildpackDescriptor, chmod int64) dist.Buildpack {
+		bp, err := ifakes.NewBuildpackFromDescriptor(descriptor, chmod)
+		h.AssertNil(t, err)
+		return bp
+	}
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDownloader = testmocks.NewMockDownloader(mockController)
+		fakeImageFetcher = ifakes.NewFakeImageFetcher()
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "inspect-buildpack-test")
+		h.AssertNil(t, err)
+
+		client, err = pack.NewClient(
+			pack.WithLogger(logging.NewLogWithWriters(&out, &out)),
+			pack.WithDownloader(mockDownloader),
+			pack.WithFetcher(fakeImageFetcher),
+		)
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	when("a buildpack path or uri is provided", func() {
+		it("returns the buildpack info", func() {
+			bp := newBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.2"),
+				Info:   dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+				Stacks: []dist.Stack{{ID: "some.stack.id"}},
+			}, 0755)
+			mockDownloader.EXPECT().Download(gomock.Any(), "https://example.com/bp.one.tgz").Return(bp, nil)
+
+			info, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "https://example.com/bp.one.tgz"})
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, info.Default, dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"})
+			h.AssertEq(t, info.Stacks, []dist.Stack{{ID: "some.stack.id"}})
+			h.AssertEq(t, len(info.Buildpacks), 1)
+			h.AssertEq(t, info.Buildpacks[0].Descriptor.API.String(), "0.2")
+			h.AssertContains(t, info.Buildpacks[0].LayerDigest, "sha256:")
+			h.AssertEq(t, info.Buildpacks[0].LayerSize > 0, true)
+			h.AssertEq(t, len(info.Buildpacks[0].MissingExecutables), 0)
+		})
+
+		it("reports executables that are not executable", func() {
+			bp := newBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.2"),
+				Info:   dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+				Stacks: []dist.Stack{{ID: "some.stack.id"}},
+			}, 0644)
+			mockDownloader.EXPECT().Download(gomock.Any(), "https://example.com/bp.one.tgz").Return(bp, nil)
+
+			info, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "https://example.com/bp.one.tgz"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, info.Buildpacks[0].MissingExecutables, []string{"bin/detect", "bin/build"})
+		})
+	})
+
+	when("a buildpackage image is provided", func() {
+		var (
+			packageImage *fakes.Image
+			layerDigests []string
+		)
+
+		it.Before(func() {
+			packageImage = fakes.NewImage("some/package", "", "")
+
+			metaBp := newBuildpack(dist.BuildpackDescriptor{
+				API:  api.MustParse("0.2"),
+				Info: dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+				Order: dist.Order{{Group: []dist.BuildpackRef{
+					{BuildpackInfo: dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"}},
+				}}},
+			}, 0755)
+			childBp := newBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.2"),
+				Info:   dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"},
+				Stacks: []dist.Stack{{ID: "some.stack.id"}},
+			}, 0755)
+
+			layerDigests = nil
+			for _, bp := range []dist.Buildpack{metaBp, childBp} {
+				layerTar, err := dist.BuildpackLayer(tmpDir, 0, 0, bp)
+				h.AssertNil(t, err)
+				h.AssertNil(t, packageImage.AddLayer(layerTar))
+
+				contents, err := ioutil.ReadFile(layerTar)
+				h.AssertNil(t, err)
+				layerDigests = append(layerDigests, fmt.Sprintf("sha256:%x", sha256.Sum256(contents)))
+			}
+
+			h.AssertNil(t, packageImage.SetLabel("io.buildpacks.buildpackage.metadata",
+				`{"id": "bp.meta", "version": "1.0.0", "stacks": [{"id": "some.stack.id"}]}`))
+			h.AssertNil(t, packageImage.SetLabel("io.buildpacks.buildpack.layers",
+				`{
+  "bp.meta": {"1.0.0": {"layerDigest": "`+layerDigests[0]+`", "order": [{"group": [{"id": "bp.one", "version": "1.2.3"}]}]}},
+  "bp.one": {"1.2.3": {"layerDigest": "`+layerDigests[1]+`"}}
+}`))
+
+			fakeImageFetcher.RemoteImages["some/package"] = packageImage
+		})
+
+		it.After(func() {
+			packageImage.Cleanup()
+		})
+
+		it("returns the buildpacks in the package", func() {
+			info, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "some/package"})
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, info.Default, dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"})
+			h.AssertEq(t, info.Stacks, []dist.Stack{{ID: "some.stack.id"}})
+			h.AssertEq(t, len(info.Buildpacks), 2)
+			h.AssertEq(t, info.Buildpacks[0].Descriptor.Info.ID, "bp.meta")
+			h.AssertEq(t, info.Buildpacks[1].Descriptor.Info.ID, "bp.one")
+			h.AssertEq(t, info.Buildpacks[1].Descriptor.Stacks, []dist.Stack{{ID: "some.stack.id"}})
+			h.AssertEq(t, info.Order()[0].Group[0].ID, "bp.one")
+			h.AssertEq(t, info.BuildpackLayers()["bp.meta"]["1.0.0"].Order, info.Order())
+		})
+
+		it("computes the layer digests and sizes", func() {
+			info, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "some/package"})
+			h.AssertNil(t, err)
+
+			for i, digest := range layerDigests {
+				h.AssertEq(t, info.Buildpacks[i].LayerDigest, digest)
+				h.AssertEq(t, info.Buildpacks[i].LayerSize > 0, true)
+			}
+		})
+
+		it("fetches the image without pulling", func() {
+			_, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "some/package"})
+			h.AssertNil(t, err)
+			h.AssertEq(t, fakeImageFetcher.FetchCalls["some/package"].Daemon, false)
+			h.AssertEq(t, fakeImageFetcher.FetchCalls["some/package"].Pull, false)
+		})
+
+		when("the image is not a buildpackage", func() {
+			it("returns an error", func() {
+				fakeImageFetcher.RemoteImages["some/image"] = fakes.NewImage("some/image", "", "")
+
+				_, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "some/image"})
+				h.AssertError(t, err, "image 'some/image' is not a buildpackage")
+			})
+		})
+
+		when("the image is missing the buildpack layers label", func() {
+			it("returns an error", func() {
+				h.AssertNil(t, packageImage.SetLabel("io.buildpacks.buildpack.layers", ""))
+
+				_, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "some/package"})
+				h.AssertError(t, err, "buildpackage is missing label 'io.buildpacks.buildpack.layers'")
+			})
+		})
+	})
+
+	when("the image does not exist", func() {
+		it("returns nil", func() {
+			info, err := client.InspectBuildpack(context.TODO(), pack.InspectBuildpackOptions{BuildpackName: "missing/package"})
+			h.AssertNil(t, err)
+			h.AssertNil(t, info)
+		})
+	})
+}
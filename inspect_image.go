@@ -0,0 +1,160 @@
+package pack
+
+import (
+	"context"
+
+	"github.com/buildpack/lifecycle/metadata"
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/image"
+	"github.com/buildpack/pack/style"
+)
+
+const buildMetadataLabel = "io.buildpacks.build.metadata"
+
+type ImageInfo struct {
+	StackID    string
+	Buildpacks []metadata.BuildpackMetadata
+	Base       metadata.RunImageMetadata
+	Stack      metadata.StackMetadata
+	Processes  []ProcessInfo
+}
+
+type ProcessInfo struct {
+	Type    string   `json:"type"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Direct  bool     `json:"direct"`
+}
+
+type buildMetadata struct {
+	Processes []ProcessInfo `json:"processes"`
+}
+
+func (c *Client) InspectImage(ctx context.Context, name string, daemon bool) (*ImageInfo, error) {
+	img, err := c.imageFetcher.Fetch(ctx, name, daemon, false)
+	if err != nil {
+		if errors.Cause(err) == image.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var md metadata.AppImageMetadata
+	if ok, err := dist.GetLabel(img, metadata.AppMetadataLabel, &md); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.Errorf("image %s missing label %s", style.Symbol(name), style.Symbol(metadata.AppMetadataLabel))
+	}
+
+	var buildMD buildMetadata
+	if _, err := dist.GetLabel(img, buildMetadataLabel, &buildMD); err != nil {
+		return nil, err
+	}
+
+	stackID, err := img.Label("io.buildpacks.stack.id")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageInfo{
+		StackID:    stackID,
+		Buildpacks: md.Buildpacks,
+		Base:       md.RunImage,
+		Stack:      md.Stack,
+		Processes:  buildMD.Processes,
+	}, nil
+}
+
+type LayerType string
+
+const (
+	RunImageLayer  LayerType = "run-image"
+	BuildpackLayer LayerType = "buildpack"
+	AppLayer       LayerType = "app"
+	ConfigLayer    LayerType = "config"
+	LauncherLayer  LayerType = "launcher"
+	UnknownLayer   LayerType = "unknown"
+)
+
+// ImageLayer describes a layer of an app image and what contributed it.
+type ImageLayer struct {
+	DiffID string
+	// Size is the uncompressed size of the layer for daemon images and its compressed size for registry images.
+	Size int64
+	Type LayerType
+	// Buildpack and Name identify the buildpack layer, when Type is BuildpackLayer.
+	Buildpack dist.BuildpackInfo
+	Name      string
+}
+
+// InspectImageLayers attributes each layer of an app image using its lifecycle metadata: layers up to the run
+// image's top layer belong to the run image, and the rest to buildpacks, the app, the config or the launcher.
+func (c *Client) InspectImageLayers(ctx context.Context, name string, daemon bool) ([]ImageLayer, error) {
+	img, err := c.imageFetcher.Fetch(ctx, name, daemon, false)
+	if err != nil {
+		if errors.Cause(err) == image.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var md metadata.AppImageMetadata
+	if ok, err := dist.GetLabel(img, metadata.AppMetadataLabel, &md); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.Errorf("image %s missing label %s", style.Symbol(name), style.Symbol(metadata.AppMetadataLabel))
+	}
+
+	layers, err := c.layerFetcher.FetchLayers(ctx, name, daemon)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching layers of image %s", style.Symbol(name))
+	}
+
+	runImageLayers := 0
+	for i, layer := range layers {
+		if layer.DiffID == md.RunImage.TopLayer {
+			runImageLayers = i + 1
+			break
+		}
+	}
+
+	var imageLayers []ImageLayer
+	for i, layer := range layers {
+		imageLayer := ImageLayer{DiffID: layer.DiffID, Size: layer.Size, Type: UnknownLayer}
+		if i < runImageLayers {
+			imageLayer.Type = RunImageLayer
+		} else {
+			attributeLayer(&imageLayer, md)
+		}
+		imageLayers = append(imageLayers, imageLayer)
+	}
+
+	return imageLayers, nil
+}
+
+func attributeLayer(layer *ImageLayer, md metadata.AppImageMetadata) {
+	switch layer.DiffID {
+	case md.App.SHA:
+		layer.Type = AppLayer
+		return
+	case md.Config.SHA:
+		layer.Type = ConfigLayer
+		return
+	case md.Launcher.SHA:
+		layer.Type = LauncherLayer
+		return
+	}
+
+	for _, bp := range md.Buildpacks {
+		for name, bpLayer := range bp.Layers {
+			if bpLayer.SHA == layer.DiffID {
+				layer.Type = BuildpackLayer
+				layer.Buildpack = dist.BuildpackInfo{ID: bp.ID, Version: bp.Version}
+				layer.Name = name
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package pack
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/buildpack/imgutil/fakes"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/image"
+	ifakes "github.com/buildpack/pack/internal/fakes"
+	h "github.com/buildpack/pack/testhelpers"
+	"github.com/buildpack/pack/testmocks"
+)
+
+func TestInspectImage(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "InspectImage", testInspectImage, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testInspectImage(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject          *Client
+		fakeImageFetcher *ifakes.FakeImageFetcher
+		mockController   *gomock.Controller
+		mockLayerFetcher *testmocks.MockLayerFetcher
+		appImage         *fakes.Image
+		out              bytes.Buffer
+	)
+
+	it.Before(func() {
+		fakeImageFetcher = ifakes.NewFakeImageFetcher()
+		mockController = gomock.NewController(t)
+		mockLayerFetcher = testmocks.NewMockLayerFetcher(mockController)
+
+		subject = &Client{
+			logger:       ifakes.NewFakeLogger(&out),
+			imageFetcher: fakeImageFetcher,
+			layerFetcher: mockLayerFetcher,
+		}
+
+		appImage = fakes.NewImage("some/app", "", "")
+		h.AssertNil(t, appImage.SetLabel("io.buildpacks.stack.id", "test.stack.id"))
+		h.AssertNil(t, appImage.SetLabel("io.buildpacks.lifecycle.metadata", `{
+  "buildpacks": [
+    {
+      "key": "test.bp.one",
+      "version": "1.0.0",
+      "layers": {
+        "jdk": {"sha": "sha256:jdk-sha", "launch": true}
+      }
+    }
+  ],
+  "app": {"sha": "sha256:app-sha"},
+  "config": {"sha": "sha256:config-sha"},
+  "launcher": {"sha": "sha256:launcher-sha"},
+  "runImage": {"topLayer": "sha256:top-layer", "sha": "sha256:base-digest"},
+  "stack": {"runImage": {"image": "some/run-image", "mirrors": ["some/mirror"]}}
+}`))
+		h.AssertNil(t, appImage.SetLabel("io.buildpacks.build.metadata", `{
+  "processes": [
+    {"type": "web", "command": "bundle exec rackup", "args": ["-p", "8080"], "direct": false}
+  ]
+}`))
+	})
+
+	it.After(func() {
+		appImage.Cleanup()
+		mockController.Finish()
+	})
+
+	for _, useDaemon := range []bool{true, false} {
+		useDaemon := useDaemon
+		when(fmt.Sprintf("daemon is %t", useDaemon), func() {
+			it.Before(func() {
+				if useDaemon {
+					fakeImageFetcher.LocalImages["some/app"] = appImage
+				} else {
+					fakeImageFetcher.RemoteImages["some/app"] = appImage
+				}
+			})
+
+			it("returns the image info", func() {
+				info, err := subject.InspectImage(context.TODO(), "some/app", useDaemon)
+				h.AssertNil(t, err)
+
+				h.AssertEq(t, info.StackID, "test.stack.id")
+				h.AssertEq(t, info.Base.TopLayer, "sha256:top-layer")
+				h.AssertEq(t, info.Base.SHA, "sha256:base-digest")
+				h.AssertEq(t, info.Stack.RunImage.Image, "some/run-image")
+				h.AssertEq(t, info.Stack.RunImage.Mirrors, []string{"some/mirror"})
+				h.AssertEq(t, len(info.Buildpacks), 1)
+				h.AssertEq(t, info.Buildpacks[0].ID, "test.bp.one")
+				h.AssertEq(t, info.Buildpacks[0].Version, "1.0.0")
+				h.AssertEq(t, info.Buildpacks[0].Layers["jdk"].SHA, "sha256:jdk-sha")
+				h.AssertEq(t, info.Processes, []ProcessInfo{
+					{Type: "web", Command: "bundle exec rackup", Args: []string{"-p", "8080"}},
+				})
+			})
+
+			it("fetches the image without pulling", func() {
+				_, err := subject.InspectImage(context.TODO(), "some/app", useDaemon)
+				h.AssertNil(t, err)
+				h.AssertEq(t, fakeImageFetcher.FetchCalls["some/app"].Daemon, useDaemon)
+				h.AssertEq(t, fakeImageFetcher.FetchCalls["some/app"].Pull, false)
+			})
+		})
+	}
+
+	when("the image is not found", func() {
+		it("returns nil", func() {
+			info, err := subject.InspectImage(context.TODO(), "missing/app", true)
+			h.AssertNil(t, err)
+			h.AssertNil(t, info)
+		})
+	})
+
+	when("the image has no lifecycle metadata", func() {
+		it("returns an error", func() {
+			h.AssertNil(t, appImage.SetLabel("io.buildpacks.lifecycle.metadata", ""))
+			fakeImageFetcher.LocalImages["some/app"] = appImage
+
+			_, err := subject.InspectImage(context.TODO(), "some/app", true)
+			h.AssertError(t, err, "missing label 'io.buildpacks.lifecycle.metadata'")
+		})
+	})
+
+	when("#InspectImageLayers", func() {
+		it.Before(func() {
+			fakeImageFetcher.RemoteImages["some/app"] = appImage
+		})
+
+		it("attributes each layer", func() {
+			mockLayerFetcher.EXPECT().FetchLayers(gomock.Any(), "some/app", false).Return([]image.Layer{
+				{DiffID: "sha256:base-layer", Size: 100},
+				{DiffID: "sha256:top-layer", Size: 200},
+				{DiffID: "sha256:jdk-sha", Size: 300},
+				{DiffID: "sha256:launcher-sha", Size: 400},
+				{DiffID: "sha256:app-sha", Size: 500},
+				{DiffID: "sha256:config-sha", Size: 600},
+				{DiffID: "sha256:other-sha", Size: 700},
+			}, nil)
+
+			layers, err := subject.InspectImageLayers(context.TODO(), "some/app", false)
+			h.AssertNil(t, err)
+			h.AssertEq(t, layers, []ImageLayer{
+				{DiffID: "sha256:base-layer", Size: 100, Type: RunImageLayer},
+				{DiffID: "sha256:top-layer", Size: 200, Type: RunImageLayer},
+				{
+					DiffID:    "sha256:jdk-sha",
+					Size:      300,
+					Type:      BuildpackLayer,
+					Buildpack: dist.BuildpackInfo{ID: "test.bp.one", Version: "1.0.0"},
+					Name:      "jdk",
+				},
+				{DiffID: "sha256:launcher-sha", Size: 400, Type: LauncherLayer},
+				{DiffID: "sha256:app-sha", Size: 500, Type: AppLayer},
+				{DiffID: "sha256:config-sha", Size: 600, Type: ConfigLayer},
+				{DiffID: "sha256:other-sha", Size: 700, Type: UnknownLayer},
+			})
+		})
+
+		it("returns nil when the image is not found", func() {
+			layers, err := subject.InspectImageLayers(context.TODO(), "missing/app", true)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(layers), 0)
+		})
+
+		it("returns an error when the layers cannot be fetched", func() {
+			mockLayerFetcher.EXPECT().FetchLayers(gomock.Any(), "some/app", false).Return(nil, errors.New("some error"))
+
+			_, err := subject.InspectImageLayers(context.TODO(), "some/app", false)
+			h.AssertError(t, err, "fetching layers of image 'some/app': some error")
+		})
+	})
+}
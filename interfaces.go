@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/image"
 
 	"github.com/buildpack/imgutil"
 )
@@ -17,10 +18,31 @@ type ImageFetcher interface {
 	Fetch(ctx context.Context, name string, daemon, pull bool) (imgutil.Image, error)
 }
 
+//go:generate mockgen -package testmocks -destination testmocks/mock_layer_fetcher.go github.com/buildpack/pack LayerFetcher
+
+type LayerFetcher interface {
+	// FetchLayers returns the layers of an image, starting from its base, from the daemon if daemon is true or
+	// from a registry otherwise.
+	FetchLayers(ctx context.Context, name string, daemon bool) ([]image.Layer, error)
+	// FetchDiffIDs returns the diff IDs of the layers of an image like FetchLayers, reading only the config of the
+	// image.
+	FetchDiffIDs(ctx context.Context, name string, daemon bool) ([]string, error)
+}
+
+//go:generate mockgen -package testmocks -destination testmocks/mock_platform_fetcher.go github.com/buildpack/pack PlatformFetcher
+
+type PlatformFetcher interface {
+	// FetchPlatform returns the operating system and architecture of an image, from the daemon if daemon is true or
+	// from a registry otherwise.
+	FetchPlatform(ctx context.Context, name string, daemon bool) (image.Platform, error)
+}
+
 //go:generate mockgen -package testmocks -destination testmocks/mock_downloader.go github.com/buildpack/pack Downloader
 
 type Downloader interface {
 	Download(ctx context.Context, pathOrURI string) (blob.Blob, error)
+	// Close releases the blobs downloaded so far, which may be pruned from the download cache from then on.
+	Close() error
 }
 
 //go:generate mockgen -package testmocks -destination testmocks/mock_image_factory.go github.com/buildpack/pack ImageFactory
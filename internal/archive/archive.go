@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/docker/docker/pkg/ioutils"
@@ -115,6 +116,66 @@ func AddFileToTar(tw *tar.Writer, path string, txt string) error {
 	return nil
 }
 
+// ReadSortedTarEntries calls fn with the header and contents of every entry of the tar read from r, sorted by name,
+// so that tars with the same entries are written the same way regardless of the order they were read in. Contents are
+// buffered in a temporary file rather than in memory and are only valid until fn returns.
+func ReadSortedTarEntries(r io.Reader, fn func(header *tar.Header, contents io.Reader) error) error {
+	tmpFile, err := ioutil.TempFile("", "sorted-tar-entries")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp file")
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	type tarEntry struct {
+		header       *tar.Header
+		offset, size int64
+	}
+
+	var (
+		entries []tarEntry
+		offset  int64
+	)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to get next tar entry")
+		}
+
+		size, err := io.Copy(tmpFile, tr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read contents of '%s'", header.Name)
+		}
+
+		entries = append(entries, tarEntry{header: header, offset: offset, size: size})
+		offset += size
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return path.Clean(entries[i].header.Name) < path.Clean(entries[j].header.Name)
+	})
+
+	for _, entry := range entries {
+		if err := fn(entry.header, io.NewSectionReader(tmpFile, entry.offset, entry.size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeHeader clears the fields of a tar header that differ between otherwise identical files.
+func NormalizeHeader(header *tar.Header) {
+	header.ModTime = NormalizedDateTime
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uname = ""
+	header.Gname = ""
+}
+
 var ErrEntryNotExist = errors.New("not exist")
 
 func ReadTarEntry(rc io.Reader, entryPath string) (*tar.Header, []byte, error) {
@@ -206,7 +267,12 @@ func WriteZipToTar(tw *tar.Writer, srcZip, basePath string, uid, gid int, mode i
 	}
 	defer zipReader.Close()
 
-	for _, f := range zipReader.File {
+	files := append([]*zip.File{}, zipReader.File...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	for _, f := range files {
 		var header *tar.Header
 		if f.Mode()&os.ModeSymlink != 0 {
 			target, err := func() (string, error) {
@@ -272,11 +338,9 @@ func finalizeHeader(header *tar.Header, uid, gid int, mode int64) {
 	if mode != -1 {
 		header.Mode = mode
 	}
-	header.ModTime = NormalizedDateTime
+	NormalizeHeader(header)
 	header.Uid = uid
 	header.Gid = gid
-	header.Uname = ""
-	header.Gname = ""
 }
 
 func IsZip(file io.Reader) (bool, error) {
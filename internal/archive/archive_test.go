@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/heroku/color"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
@@ -87,6 +88,43 @@ func testArchive(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("#ReadSortedTarEntries", func() {
+		it("returns the entries sorted by name", func() {
+			tarBuilder := archive.TarBuilder{}
+			tarBuilder.AddFile("b/file", 0644, archive.NormalizedDateTime, []byte("b contents"))
+			tarBuilder.AddDir("b", 0755, archive.NormalizedDateTime)
+			tarBuilder.AddFile("a", 0644, archive.NormalizedDateTime, []byte("a contents"))
+
+			var (
+				names    []string
+				contents []string
+			)
+			err := archive.ReadSortedTarEntries(tarBuilder.Reader(), func(header *tar.Header, r io.Reader) error {
+				buf, err := ioutil.ReadAll(r)
+				if err != nil {
+					return err
+				}
+				names = append(names, header.Name)
+				contents = append(contents, string(buf))
+				return nil
+			})
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, names, []string{"a", "b", "b/file"})
+			h.AssertEq(t, contents, []string{"a contents", "", "b contents"})
+		})
+
+		it("returns the error of the callback", func() {
+			tarBuilder := archive.TarBuilder{}
+			tarBuilder.AddFile("a", 0644, archive.NormalizedDateTime, []byte("a contents"))
+
+			err := archive.ReadSortedTarEntries(tarBuilder.Reader(), func(*tar.Header, io.Reader) error {
+				return errors.New("some error")
+			})
+			h.AssertError(t, err, "some error")
+		})
+	})
+
 	when("#WriteDirToTar", func() {
 		var src string
 		it.Before(func() {
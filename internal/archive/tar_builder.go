@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"io"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
@@ -70,8 +71,13 @@ func (t *TarBuilder) WriteTo(writer io.Writer) (int64, error) {
 	tw := tar.NewWriter(writer)
 	defer tw.Close()
 
+	files := append([]fileEntry{}, t.files...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+
 	var written int64
-	for _, f := range t.files {
+	for _, f := range files {
 		if err := tw.WriteHeader(&tar.Header{
 			Typeflag: f.typeFlag,
 			Name:     f.path,
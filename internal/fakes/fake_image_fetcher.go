@@ -2,6 +2,7 @@ package fakes
 
 import (
 	"context"
+	"sync"
 
 	"github.com/buildpack/imgutil"
 	"github.com/pkg/errors"
@@ -18,6 +19,7 @@ type FakeImageFetcher struct {
 	LocalImages  map[string]imgutil.Image
 	RemoteImages map[string]imgutil.Image
 	FetchCalls   map[string]*FetchArgs
+	mutex        sync.Mutex
 }
 
 func NewFakeImageFetcher() *FakeImageFetcher {
@@ -29,6 +31,9 @@ func NewFakeImageFetcher() *FakeImageFetcher {
 }
 
 func (f *FakeImageFetcher) Fetch(ctx context.Context, name string, daemon, pull bool) (imgutil.Image, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	f.FetchCalls[name] = &FetchArgs{Daemon: daemon, Pull: pull}
 
 	ri, remoteFound := f.RemoteImages[name]
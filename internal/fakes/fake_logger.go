@@ -3,13 +3,15 @@ package fakes
 import (
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/apex/log"
 )
 
 type fakeLog struct {
 	log.Logger
-	w io.Writer
+	w     io.Writer
+	mutex sync.Mutex
 }
 
 // NewFakeLogger create a fake_logger to capture output for testing purposes.
@@ -23,6 +25,9 @@ func NewFakeLogger(w io.Writer) *fakeLog { //nolint:golint,gosimple
 }
 
 func (f *fakeLog) HandleLog(e *log.Entry) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	switch e.Level {
 	case log.WarnLevel:
 		_, _ = fmt.Fprintf(f.w, "Warning: %s\n", e.Message)
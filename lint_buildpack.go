@@ -0,0 +1,56 @@
+package pack
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/style"
+)
+
+type LintBuildpackOptions struct {
+	// BuildpackName is a path or URI to a buildpack directory or archive.
+	BuildpackName string
+	// BuildpackAPI is the buildpack API of the lifecycle the buildpack is meant to be used with. It is optional.
+	BuildpackAPI *api.Version
+}
+
+// LintBuildpack returns the problems found with a buildpack.
+func (c *Client) LintBuildpack(ctx context.Context, opts LintBuildpackOptions) ([]dist.Finding, error) {
+	if err := ensureBPSupport(opts.BuildpackName); err != nil {
+		return nil, err
+	}
+
+	blob, err := c.downloader.Download(ctx, opts.BuildpackName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "downloading buildpack from %s", style.Symbol(opts.BuildpackName))
+	}
+
+	return dist.Lint(blob, dist.LintOptions{BuildpackAPI: opts.BuildpackAPI})
+}
+
+// enforceLint fails when the buildpack from source has findings with dist.SeverityError and logs the other findings
+// as warnings.
+func (c *Client) enforceLint(source string, blob dist.Blob, opts dist.LintOptions) error {
+	findings, err := dist.Lint(blob, opts)
+	if err != nil {
+		return errors.Wrapf(err, "linting buildpack from %s", style.Symbol(source))
+	}
+
+	var messages []string
+	for _, f := range findings {
+		if f.Severity == dist.SeverityError {
+			messages = append(messages, f.Message)
+			continue
+		}
+		c.logger.Warnf("buildpack from %s: %s", style.Symbol(source), f.Message)
+	}
+
+	if len(messages) > 0 {
+		return errors.Errorf("buildpack from %s failed linting: %s", style.Symbol(source), strings.Join(messages, "; "))
+	}
+	return nil
+}
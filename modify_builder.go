@@ -0,0 +1,228 @@
+package pack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/buildpackage"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/image"
+	"github.com/buildpack/pack/style"
+)
+
+type ModifyBuilderOptions struct {
+	BuilderName string
+	// TargetName is the name of the modified builder. The builder is modified in place when it is empty.
+	TargetName string
+	// AddBuildpacks are paths or URIs of buildpacks or buildpackage files, or names of buildpackage images, to add to
+	// the builder.
+	AddBuildpacks []string
+	// RemoveBuildpacks are removed from the builder. The version may be omitted when the builder has only one
+	// version of a buildpack.
+	RemoveBuildpacks []dist.BuildpackInfo
+	// Order replaces the order of the builder when it is not empty.
+	Order dist.Order
+	// Description replaces the description of the builder when it is not empty.
+	Description string
+	// RunImageMirrors replace the run image mirrors of the builder when they are not nil.
+	RunImageMirrors []string
+	// Lifecycle replaces the lifecycle of the builder when a URI or version is provided.
+	Lifecycle builder.LifecycleConfig
+	Publish   bool
+	NoPull    bool
+}
+
+// ModifyBuilder changes an existing builder. Layers of the builder that are not affected by the changes are reused.
+func (c *Client) ModifyBuilder(ctx context.Context, opts ModifyBuilderOptions) error {
+	img, err := c.imageFetcher.Fetch(ctx, opts.BuilderName, !opts.Publish, !opts.NoPull)
+	if err != nil {
+		if errors.Cause(err) == image.ErrNotFound {
+			return fmt.Errorf("builder %s not found", style.Symbol(opts.BuilderName))
+		}
+		return err
+	}
+
+	bldr, err := builder.GetBuilder(img)
+	if err != nil {
+		return errors.Wrapf(err, "invalid builder %s", style.Symbol(opts.BuilderName))
+	}
+
+	if opts.TargetName != "" && opts.TargetName != img.Name() {
+		img.Rename(opts.TargetName)
+	}
+
+	// the provenance of the builder is kept up to date with the changes when it was recorded
+	var provenance *builder.Provenance
+	if bldr.GetProvenance() != nil {
+		p := *bldr.GetProvenance()
+		p.Buildpacks = append([]builder.BuildpackProvenance{}, p.Buildpacks...)
+		provenance = &p
+	}
+
+	if opts.Lifecycle.URI != "" || opts.Lifecycle.Version != "" {
+		platform, err := c.platformFetcher.FetchPlatform(ctx, opts.BuilderName, !opts.Publish)
+		if err != nil {
+			return errors.Wrapf(err, "reading platform of builder %s", style.Symbol(opts.BuilderName))
+		}
+
+		lifecycle, lifecycleProvenance, err := c.fetchLifecycle(ctx, opts.Lifecycle, platform)
+		if err != nil {
+			return errors.Wrap(err, "fetch lifecycle")
+		}
+
+		if err := bldr.SetLifecycle(lifecycle); err != nil {
+			return errors.Wrap(err, "setting lifecycle")
+		}
+
+		if provenance != nil {
+			provenance.Lifecycle = lifecycleProvenance
+		}
+	}
+
+	// The order is replaced first, so that buildpacks it no longer references can be removed.
+	if len(opts.Order) > 0 {
+		bldr.SetOrder(opts.Order)
+	}
+
+	for _, bp := range opts.RemoveBuildpacks {
+		if err := bldr.RemoveBuildpack(bp); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range opts.AddBuildpacks {
+		bps, source, err := c.fetchBuildpacks(ctx, name, !opts.Publish, !opts.NoPull)
+		if err != nil {
+			return err
+		}
+
+		for _, bp := range bps {
+			bldr.AddBuildpack(bp)
+			if provenance != nil {
+				provenance.Buildpacks = addBuildpackProvenance(provenance.Buildpacks, bp.Descriptor().Info, source)
+			}
+		}
+	}
+
+	if opts.Description != "" {
+		bldr.SetDescription(opts.Description)
+	}
+
+	if opts.RunImageMirrors != nil {
+		bldr.SetRunImageMirrors(opts.RunImageMirrors)
+	}
+
+	// buildpacks that were removed are dropped from the provenance when the builder is saved
+	if provenance != nil {
+		bldr.SetProvenance(*provenance)
+	}
+
+	c.logger.Debugf("Modifying builder %s", style.Symbol(bldr.Name()))
+	return bldr.Save(c.logger)
+}
+
+// fetchBuildpacks returns the buildpack at a path or URI, or all buildpacks contained in a buildpackage file or
+// image, along with where they came from.
+func (c *Client) fetchBuildpacks(ctx context.Context, name string, daemon, pull bool) ([]dist.Buildpack, builder.SourceProvenance, error) {
+	if isBuildpackPath(name) {
+		if err := ensureBPSupport(name); err != nil {
+			return nil, builder.SourceProvenance{}, err
+		}
+
+		blob, err := c.downloader.Download(ctx, name)
+		if err != nil {
+			return nil, builder.SourceProvenance{}, errors.Wrapf(err, "downloading buildpack from %s", style.Symbol(name))
+		}
+
+		source, err := sourceProvenance(name, blob)
+		if err != nil {
+			return nil, builder.SourceProvenance{}, errors.Wrapf(err, "computing digest of buildpack from %s", style.Symbol(name))
+		}
+
+		if buildpackage.IsFile(name) {
+			_, bps, err := readBuildpackageFile(name, blob)
+			return bps, source, err
+		}
+
+		bp, err := dist.NewBuildpack(blob)
+		if err != nil {
+			return nil, builder.SourceProvenance{}, errors.Wrapf(err, "creating buildpack from %s", style.Symbol(name))
+		}
+		return []dist.Buildpack{bp}, source, nil
+	}
+
+	img, err := c.imageFetcher.Fetch(ctx, name, daemon, pull)
+	if err != nil {
+		return nil, builder.SourceProvenance{}, errors.Wrapf(err, "fetching buildpackage %s", style.Symbol(name))
+	}
+
+	if ok, err := dist.GetLabel(img, buildpackage.MetadataLabel, &buildpackage.Metadata{}); err != nil {
+		return nil, builder.SourceProvenance{}, err
+	} else if !ok {
+		return nil, builder.SourceProvenance{}, errors.Errorf("image %s is not a buildpackage: missing label %s", style.Symbol(name), style.Symbol(buildpackage.MetadataLabel))
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, builder.SourceProvenance{}, errors.Wrapf(err, "reading digest of buildpackage %s", style.Symbol(name))
+	}
+
+	_, bps, err := readBuildpackage(name, img)
+	return bps, builder.SourceProvenance{URI: name, Digest: digest}, err
+}
+
+// buildpackageImage is a buildpackage image or file.
+type buildpackageImage interface {
+	Label(string) (string, error)
+	GetLayer(string) (io.ReadCloser, error)
+}
+
+// readBuildpackageFile returns the metadata and the buildpacks of the buildpackage file in blob.
+func readBuildpackageFile(name string, blob dist.Blob) (buildpackage.Metadata, []dist.Buildpack, error) {
+	file, err := buildpackage.OpenFile(blob)
+	if err != nil {
+		return buildpackage.Metadata{}, nil, errors.Wrapf(err, "reading buildpackage file %s", style.Symbol(name))
+	}
+	return readBuildpackage(name, file)
+}
+
+// readBuildpackage returns the metadata of a buildpackage and its buildpacks, sorted by ID and version.
+func readBuildpackage(name string, img buildpackageImage) (buildpackage.Metadata, []dist.Buildpack, error) {
+	var md buildpackage.Metadata
+	if _, err := dist.GetLabel(img, buildpackage.MetadataLabel, &md); err != nil {
+		return buildpackage.Metadata{}, nil, err
+	}
+
+	var bpLayers builder.BuildpackLayers
+	if ok, err := dist.GetLabel(img, builder.BuildpackLayersLabel, &bpLayers); err != nil {
+		return buildpackage.Metadata{}, nil, err
+	} else if !ok {
+		return buildpackage.Metadata{}, nil, errors.Errorf("buildpackage %s is missing label %s", style.Symbol(name), style.Symbol(builder.BuildpackLayersLabel))
+	}
+
+	var bps []dist.Buildpack
+	for id, versions := range bpLayers {
+		for version, layerInfo := range versions {
+			bp, err := dist.BuildpackFromLayer(&imageLayerBlob{image: img, diffID: layerInfo.LayerDigest}, dist.BuildpackInfo{ID: id, Version: version})
+			if err != nil {
+				return buildpackage.Metadata{}, nil, errors.Wrapf(err, "reading buildpack %s from buildpackage %s", style.Symbol(id+"@"+version), style.Symbol(name))
+			}
+			bps = append(bps, bp)
+		}
+	}
+
+	sort.Slice(bps, func(i, j int) bool {
+		a, b := bps[i].Descriptor().Info, bps[j].Descriptor().Info
+		if a.ID == b.ID {
+			return a.Version < b.Version
+		}
+		return a.ID < b.ID
+	})
+
+	return md, bps, nil
+}
@@ -0,0 +1,300 @@
+package pack
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpack/imgutil/fakes"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpack/pack/api"
+	"github.com/buildpack/pack/blob"
+	"github.com/buildpack/pack/builder"
+	"github.com/buildpack/pack/dist"
+	"github.com/buildpack/pack/image"
+	ifakes "github.com/buildpack/pack/internal/fakes"
+	h "github.com/buildpack/pack/testhelpers"
+	"github.com/buildpack/pack/testmocks"
+)
+
+func TestModifyBuilder(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "modify_builder", testModifyBuilder, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testModifyBuilder(t *testing.T, when spec.G, it spec.S) {
+	when("#ModifyBuilder", func() {
+		var (
+			mockController   *gomock.Controller
+			mockDownloader   *testmocks.MockDownloader
+			mockPlatform     *testmocks.MockPlatformFetcher
+			fakeImageFetcher *ifakes.FakeImageFetcher
+			builderImage     *fakes.Image
+			subject          *Client
+			out              bytes.Buffer
+			tmpDir           string
+		)
+
+		existingBp := dist.BuildpackInfo{ID: "bp.existing", Version: "1.0.0"}
+		bpOne := dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"}
+
+		it.Before(func() {
+			mockController = gomock.NewController(t)
+			mockDownloader = testmocks.NewMockDownloader(mockController)
+			mockDownloader.EXPECT().Download(gomock.Any(), "https://example.fake/bp-one.tgz").Return(&etagBlob{Blob: blob.NewBlob(filepath.Join("testdata", "buildpack")), etag: `"bp-one"`}, nil).AnyTimes()
+			mockDownloader.EXPECT().Download(gomock.Any(), "file:///some-lifecycle").Return(blob.NewBlob(filepath.Join("testdata", "lifecycle")), nil).AnyTimes()
+
+			mockPlatform = testmocks.NewMockPlatformFetcher(mockController)
+			mockPlatform.EXPECT().FetchPlatform(gomock.Any(), "some/builder", true).Return(image.Platform{OS: "linux", Architecture: "amd64"}, nil).AnyTimes()
+
+			builderImage = ifakes.NewFakeBuilderImage(t, "some/builder", "some.stack.id", "1234", "4321", builder.Metadata{
+				Description: "Some description",
+				Buildpacks:  []builder.BuildpackMetadata{{BuildpackInfo: existingBp, Latest: true}},
+				Stack:       builder.StackMetadata{RunImage: builder.RunImageMetadata{Image: "some/run-image"}},
+				Lifecycle: builder.LifecycleMetadata{
+					LifecycleInfo: builder.LifecycleInfo{Version: builder.VersionMustParse("3.4.5")},
+					API: builder.LifecycleAPI{
+						BuildpackVersion: api.MustParse("0.3"),
+						PlatformVersion:  api.MustParse("0.2"),
+					},
+				},
+			})
+			h.AssertNil(t, builderImage.SetLabel("io.buildpacks.buildpack.layers", `{"bp.existing": {"1.0.0": {"layerDigest": "sha256:existing"}}}`))
+			h.AssertNil(t, builderImage.SetLabel("io.buildpacks.buildpack.order", `[{"group": [{"id": "bp.existing", "version": "1.0.0"}]}]`))
+
+			fakeImageFetcher = ifakes.NewFakeImageFetcher()
+			fakeImageFetcher.LocalImages["some/builder"] = builderImage
+
+			subject = &Client{
+				logger:          ifakes.NewFakeLogger(&out),
+				imageFetcher:    fakeImageFetcher,
+				platformFetcher: mockPlatform,
+				downloader:      mockDownloader,
+			}
+
+			var err error
+			tmpDir, err = ioutil.TempDir("", "modify-builder-test")
+			h.AssertNil(t, err)
+		})
+
+		it.After(func() {
+			mockController.Finish()
+			builderImage.Cleanup()
+			h.AssertNil(t, os.RemoveAll(tmpDir))
+		})
+
+		modifiedBuilder := func() *builder.Builder {
+			t.Helper()
+			h.AssertEq(t, builderImage.IsSaved(), true)
+			bldr, err := builder.GetBuilder(builderImage)
+			h.AssertNil(t, err)
+			return bldr
+		}
+
+		it("adds buildpacks and replaces the order", func() {
+			h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+				BuilderName:   "some/builder",
+				AddBuildpacks: []string{"https://example.fake/bp-one.tgz"},
+				Order: dist.Order{
+					{Group: []dist.BuildpackRef{{BuildpackInfo: bpOne}}},
+					{Group: []dist.BuildpackRef{{BuildpackInfo: existingBp}}},
+				},
+			}))
+
+			bldr := modifiedBuilder()
+			h.AssertEq(t, bldr.GetBuildpacks(), []builder.BuildpackMetadata{
+				{BuildpackInfo: existingBp, Latest: true},
+				{BuildpackInfo: bpOne, Latest: true},
+			})
+			h.AssertEq(t, len(bldr.GetOrder()), 2)
+			h.AssertEq(t, bldr.GetOrder()[0].Group[0].BuildpackInfo, bpOne)
+			h.AssertEq(t, bldr.GetBuildpackLayers()["bp.existing"]["1.0.0"].LayerDigest, "sha256:existing")
+			h.AssertEq(t, bldr.Description(), "Some description")
+
+			layerTar, err := builderImage.FindLayerWithPath("/cnb/buildpacks/bp.one/1.2.3")
+			h.AssertNil(t, err)
+			assertTarHasFile(t, layerTar, "/cnb/buildpacks/bp.one/1.2.3/buildpack.toml")
+		})
+
+		it("removes buildpacks", func() {
+			h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+				BuilderName:      "some/builder",
+				AddBuildpacks:    []string{"https://example.fake/bp-one.tgz"},
+				RemoveBuildpacks: []dist.BuildpackInfo{{ID: "bp.existing"}},
+				Order:            dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: bpOne}}}},
+			}))
+
+			bldr := modifiedBuilder()
+			h.AssertEq(t, bldr.GetBuildpacks(), []builder.BuildpackMetadata{{BuildpackInfo: bpOne, Latest: true}})
+			_, ok := bldr.GetBuildpackLayers()["bp.existing"]
+			h.AssertEq(t, ok, false)
+
+			layerTar, err := builderImage.FindLayerWithPath("/cnb/buildpacks/.wh.bp.existing")
+			h.AssertNil(t, err)
+			assertTarHasFile(t, layerTar, "/cnb/buildpacks/.wh.bp.existing")
+		})
+
+		it("only adds layers for the changes", func() {
+			h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+				BuilderName: "some/builder",
+				Description: "New description",
+			}))
+			h.AssertEq(t, builderImage.NumberOfAddedLayers(), 0)
+
+			h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+				BuilderName:     "some/builder",
+				RunImageMirrors: []string{"some/mirror"},
+			}))
+			_, err := builderImage.FindLayerWithPath("/cnb/stack.toml")
+			h.AssertNil(t, err)
+			_, err = builderImage.FindLayerWithPath("/workspace")
+			h.AssertNotNil(t, err)
+		})
+
+		it("replaces the description, run image mirrors and lifecycle", func() {
+			h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+				BuilderName:     "some/builder",
+				Description:     "New description",
+				RunImageMirrors: []string{"some/mirror"},
+				Lifecycle:       builder.LifecycleConfig{URI: "file:///some-lifecycle"},
+			}))
+
+			bldr := modifiedBuilder()
+			h.AssertEq(t, bldr.Description(), "New description")
+			h.AssertEq(t, bldr.GetStackInfo().RunImage.Mirrors, []string{"some/mirror"})
+			h.AssertEq(t, bldr.GetStackInfo().RunImage.Image, "some/run-image")
+
+			layerTar, err := builderImage.FindLayerWithPath("/cnb/lifecycle")
+			h.AssertNil(t, err)
+			assertTarHasFile(t, layerTar, "/cnb/lifecycle/detector")
+		})
+
+		it("saves the builder under a new name when a target is provided", func() {
+			h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+				BuilderName: "some/builder",
+				TargetName:  "other/builder",
+				Description: "New description",
+			}))
+
+			h.AssertEq(t, builderImage.Name(), "other/builder")
+			h.AssertEq(t, modifiedBuilder().Description(), "New description")
+		})
+
+		it("adds all buildpacks from a buildpackage image", func() {
+			packageImage := fakes.NewImage("some/package", "", "")
+			defer packageImage.Cleanup()
+
+			bp, err := dist.NewBuildpack(blob.NewBlob(filepath.Join("testdata", "buildpack2")))
+			h.AssertNil(t, err)
+			layerTar, err := dist.BuildpackLayer(tmpDir, 0, 0, bp)
+			h.AssertNil(t, err)
+			h.AssertNil(t, packageImage.AddLayer(layerTar))
+			contents, err := ioutil.ReadFile(layerTar)
+			h.AssertNil(t, err)
+
+			h.AssertNil(t, packageImage.SetLabel("io.buildpacks.buildpackage.metadata",
+				`{"id": "some-other-buildpack-id", "version": "some-other-buildpack-version", "stacks": [{"id": "some.stack.id"}]}`))
+			h.AssertNil(t, packageImage.SetLabel("io.buildpacks.buildpack.layers",
+				fmt.Sprintf(`{"some-other-buildpack-id": {"some-other-buildpack-version": {"layerDigest": "sha256:%x"}}}`, sha256.Sum256(contents))))
+			fakeImageFetcher.LocalImages["some/package"] = packageImage
+
+			h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+				BuilderName:   "some/builder",
+				AddBuildpacks: []string{"some/package"},
+			}))
+
+			bldr := modifiedBuilder()
+			h.AssertEq(t, bldr.GetBuildpacks()[1].BuildpackInfo, dist.BuildpackInfo{ID: "some-other-buildpack-id", Version: "some-other-buildpack-version"})
+
+			_, err = builderImage.FindLayerWithPath("/cnb/buildpacks/some-other-buildpack-id/some-other-buildpack-version")
+			h.AssertNil(t, err)
+		})
+
+		when("the builder has provenance", func() {
+			it.Before(func() {
+				h.AssertNil(t, builderImage.SetLabel("io.buildpacks.builder.provenance", `{
+  "buildImage": {"name": "some/build-image"},
+  "lifecycle": {"uri": "https://example.fake/old-lifecycle.tgz", "digest": "sha256:old-lifecycle"},
+  "buildpacks": [{"id": "bp.existing", "version": "1.0.0", "uri": "https://example.fake/existing.tgz", "digest": "sha256:existing"}],
+  "parameters": {"builderName": "some/builder"}
+}`))
+			})
+
+			it("keeps the provenance up to date with the changes", func() {
+				h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+					BuilderName:      "some/builder",
+					AddBuildpacks:    []string{"https://example.fake/bp-one.tgz"},
+					RemoveBuildpacks: []dist.BuildpackInfo{{ID: "bp.existing"}},
+					Order:            dist.Order{{Group: []dist.BuildpackRef{{BuildpackInfo: bpOne}}}},
+					Lifecycle:        builder.LifecycleConfig{URI: "file:///some-lifecycle"},
+				}))
+
+				bldr := modifiedBuilder()
+				provenance := bldr.GetProvenance()
+				h.AssertNotNil(t, provenance)
+				h.AssertEq(t, provenance.BuildImage.Name, "some/build-image")
+				h.AssertEq(t, provenance.Lifecycle.URI, "file:///some-lifecycle")
+				h.AssertContains(t, provenance.Lifecycle.Digest, "sha256:")
+				h.AssertEq(t, len(provenance.Buildpacks), 1)
+				h.AssertEq(t, provenance.Buildpacks[0].BuildpackInfo, bpOne)
+				h.AssertEq(t, provenance.Buildpacks[0].URI, "https://example.fake/bp-one.tgz")
+				h.AssertContains(t, provenance.Buildpacks[0].Digest, "sha256:")
+				h.AssertEq(t, provenance.Buildpacks[0].ETag, `"bp-one"`)
+				h.AssertEq(t, provenance.Buildpacks[0].LayerDigest, bldr.GetBuildpackLayers()["bp.one"]["1.2.3"].LayerDigest)
+			})
+		})
+
+		when("the builder has no provenance", func() {
+			it("does not add one", func() {
+				h.AssertNil(t, subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+					BuilderName:   "some/builder",
+					AddBuildpacks: []string{"https://example.fake/bp-one.tgz"},
+				}))
+
+				h.AssertNil(t, modifiedBuilder().GetProvenance())
+			})
+		})
+
+		when("a removed buildpack is still in the order", func() {
+			it("returns an error", func() {
+				err := subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+					BuilderName:      "some/builder",
+					RemoveBuildpacks: []dist.BuildpackInfo{existingBp},
+				})
+				h.AssertError(t, err, "buildpack 'bp.existing@1.0.0' is used by the order of the builder")
+				h.AssertEq(t, builderImage.IsSaved(), false)
+			})
+		})
+
+		when("the image is not a buildpackage", func() {
+			it("returns an error", func() {
+				notAPackage := fakes.NewImage("some/image", "", "")
+				defer notAPackage.Cleanup()
+				fakeImageFetcher.LocalImages["some/image"] = notAPackage
+
+				err := subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{
+					BuilderName:   "some/builder",
+					AddBuildpacks: []string{"some/image"},
+				})
+				h.AssertError(t, err, "image 'some/image' is not a buildpackage")
+			})
+		})
+
+		when("the builder does not exist", func() {
+			it("returns an error", func() {
+				err := subject.ModifyBuilder(context.TODO(), ModifyBuilderOptions{BuilderName: "missing/builder"})
+				h.AssertError(t, err, "builder 'missing/builder' not found")
+			})
+		})
+	})
+}
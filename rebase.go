@@ -3,6 +3,10 @@ package pack
 import (
 	"context"
 	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/buildpack/imgutil"
 
 	"github.com/buildpack/lifecycle/metadata"
 	"github.com/pkg/errors"
@@ -18,22 +22,54 @@ type RebaseOptions struct {
 	SkipPull          bool
 	RunImage          string
 	AdditionalMirrors map[string][]string
+	// Force rebases the image even when the run image is not compatible with the app image's stack.
+	Force bool
+	// DryRun reports whether the image needs to be rebased without modifying it.
+	DryRun bool
+}
+
+// ErrIncompatibleRunImage is returned when the run image selected for a rebase does not match the app image's stack.
+var ErrIncompatibleRunImage = errors.New("incompatible run image")
+
+// RebaseReport describes the outcome of a rebase. LayersRemoved and LayersAdded are only computed for dry runs.
+type RebaseReport struct {
+	Image         string `json:"image"`
+	RunImage      string `json:"run_image"`
+	RebaseNeeded  bool   `json:"rebase_needed"`
+	OldBaseDigest string `json:"old_base_digest"`
+	NewBaseDigest string `json:"new_base_digest"`
+	LayersRemoved int    `json:"layers_removed"`
+	LayersAdded   int    `json:"layers_added"`
+	// Digest is the digest of the rebased image. It is empty when the image was not saved.
+	Digest string `json:"digest,omitempty"`
 }
 
 func (c *Client) Rebase(ctx context.Context, opts RebaseOptions) error {
+	_, err := c.RebaseWithReport(ctx, opts)
+	return err
+}
+
+// RebaseWithReport rebases an image like Rebase and reports what changed.
+func (c *Client) RebaseWithReport(ctx context.Context, opts RebaseOptions) (*RebaseReport, error) {
+	return c.rebase(ctx, opts, c.imageFetcher, c.logger.Infof)
+}
+
+// rebase rebases an image, fetching its run image with runImageFetcher so that run images can be shared by
+// concurrent rebases. Progress is reported through logf.
+func (c *Client) rebase(ctx context.Context, opts RebaseOptions, runImageFetcher ImageFetcher, logf func(string, ...interface{})) (*RebaseReport, error) {
 	imageRef, err := c.parseTagReference(opts.RepoName)
 	if err != nil {
-		return errors.Wrapf(err, "invalid image name '%s'", opts.RepoName)
+		return nil, errors.Wrapf(err, "invalid image name '%s'", opts.RepoName)
 	}
 
 	appImage, err := c.imageFetcher.Fetch(ctx, opts.RepoName, !opts.Publish, !opts.SkipPull)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	md, err := metadata.GetAppMetadata(appImage)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	runImageName := c.resolveRunImage(
@@ -48,42 +84,180 @@ func (c *Client) Rebase(ctx context.Context, opts RebaseOptions) error {
 		opts.AdditionalMirrors)
 
 	if runImageName == "" {
-		return errors.New("run image must be specified")
+		return nil, errors.New("run image must be specified")
 	}
 
-	baseImage, err := c.imageFetcher.Fetch(ctx, runImageName, !opts.Publish, !opts.SkipPull)
+	baseImage, err := runImageFetcher.Fetch(ctx, runImageName, !opts.Publish, !opts.SkipPull)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := validateRebaseStack(appImage, baseImage); err != nil {
+		if !opts.Force || errors.Cause(err) != ErrIncompatibleRunImage {
+			return nil, err
+		}
+		c.logger.Warnf("Forcing rebase: %s", err)
+	}
+
+	newDigest, err := baseImage.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	newTopLayer, err := baseImage.TopLayer()
+	if err != nil {
+		return nil, err
 	}
 
-	c.logger.Infof("Rebasing %s on run image %s", style.Symbol(appImage.Name()), style.Symbol(baseImage.Name()))
+	report := &RebaseReport{
+		Image:         appImage.Name(),
+		RunImage:      baseImage.Name(),
+		RebaseNeeded:  md.RunImage.TopLayer == "" || md.RunImage.TopLayer != newTopLayer || md.RunImage.SHA != newDigest,
+		OldBaseDigest: md.RunImage.SHA,
+		NewBaseDigest: newDigest,
+	}
+
+	if !report.RebaseNeeded {
+		return report, nil
+	}
+
+	if opts.DryRun {
+		report.LayersRemoved, report.LayersAdded, err = c.swappedLayers(ctx, opts.RepoName, runImageName, md.RunImage.TopLayer, !opts.Publish)
+		if err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+
+	logf("Rebasing %s on run image %s", style.Symbol(appImage.Name()), style.Symbol(baseImage.Name()))
 	if err := appImage.Rebase(md.RunImage.TopLayer, baseImage); err != nil {
-		return err
+		return nil, err
 	}
 
-	md.RunImage.SHA, err = baseImage.Digest()
+	md.RunImage.SHA = newDigest
+	md.RunImage.TopLayer = newTopLayer
+
+	newLabel, err := json.Marshal(md)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := appImage.SetLabel(metadata.AppMetadataLabel, string(newLabel)); err != nil {
+		return nil, err
 	}
 
-	md.RunImage.TopLayer, err = baseImage.TopLayer()
+	report.Digest, err = appImage.Save()
+	if err != nil {
+		return nil, err
+	}
+	logf("New sha: %s", style.Symbol(report.Digest))
+	return report, nil
+}
+
+// swappedLayers returns how many base layers a rebase would remove from the app image and how many run image layers
+// it would add in their place. Layers shared by the old and new base are not counted.
+func (c *Client) swappedLayers(ctx context.Context, appImageName, runImageName, oldTopLayer string, daemon bool) (int, int, error) {
+	appLayers, err := c.layerFetcher.FetchDiffIDs(ctx, appImageName, daemon)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "reading layers of image %s", style.Symbol(appImageName))
+	}
+
+	runLayers, err := c.layerFetcher.FetchDiffIDs(ctx, runImageName, daemon)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "reading layers of image %s", style.Symbol(runImageName))
+	}
+
+	oldBase := 0
+	for i, diffID := range appLayers {
+		if diffID == oldTopLayer {
+			oldBase = i + 1
+			break
+		}
+	}
+
+	shared := 0
+	for shared < oldBase && shared < len(runLayers) && appLayers[shared] == runLayers[shared] {
+		shared++
+	}
+
+	return oldBase - shared, len(runLayers) - shared, nil
+}
+
+// validateRebaseStack checks that the run image has the same stack ID as the app image and provides all of its
+// run mixins. App images without a stack ID are not validated.
+func validateRebaseStack(appImage, runImage imgutil.Image) error {
+	appStackID, err := appImage.Label("io.buildpacks.stack.id")
 	if err != nil {
 		return err
 	}
+	if appStackID == "" {
+		return nil
+	}
 
-	newLabel, err := json.Marshal(md)
+	runStackID, err := runImage.Label("io.buildpacks.stack.id")
 	if err != nil {
 		return err
 	}
+	if runStackID != appStackID {
+		return errors.Wrapf(ErrIncompatibleRunImage,
+			"run image %s has stack %s but app image %s was built on stack %s",
+			style.Symbol(runImage.Name()),
+			style.Symbol(runStackID),
+			style.Symbol(appImage.Name()),
+			style.Symbol(appStackID),
+		)
+	}
 
-	if err := appImage.SetLabel(metadata.AppMetadataLabel, string(newLabel)); err != nil {
+	appMixins, err := stackMixins(appImage)
+	if err != nil {
 		return err
 	}
 
-	sha, err := appImage.Save()
+	runMixins, err := stackMixins(runImage)
 	if err != nil {
 		return err
 	}
-	c.logger.Infof("New sha: %s", style.Symbol(sha))
+
+	var missing []string
+	for mixin := range appMixins {
+		if !runMixins[mixin] {
+			missing = append(missing, mixin)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Wrapf(ErrIncompatibleRunImage,
+			"run image %s is missing required mixins: %s",
+			style.Symbol(runImage.Name()),
+			strings.Join(missing, ", "),
+		)
+	}
+
 	return nil
 }
+
+// stackMixins reads the run mixins from the io.buildpacks.stack.mixins label of an image.
+func stackMixins(img imgutil.Image) (map[string]bool, error) {
+	label, err := img.Label("io.buildpacks.stack.mixins")
+	if err != nil {
+		return nil, err
+	}
+
+	mixins := map[string]bool{}
+	if label == "" {
+		return mixins, nil
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(label), &names); err != nil {
+		return nil, errors.Wrapf(err, "parsing mixins label of image %s", style.Symbol(img.Name()))
+	}
+
+	for _, name := range names {
+		if strings.HasPrefix(name, "build:") {
+			continue
+		}
+		mixins[strings.TrimPrefix(name, "run:")] = true
+	}
+	return mixins, nil
+}
@@ -0,0 +1,106 @@
+package pack
+
+import (
+	"context"
+	"sync"
+
+	"github.com/buildpack/imgutil"
+)
+
+// DefaultRebaseConcurrency is the number of images rebased at the same time when no concurrency is provided.
+const DefaultRebaseConcurrency = 4
+
+type RebaseManyOptions struct {
+	// RepoNames are the images to rebase.
+	RepoNames []string
+	// Concurrency is the maximum number of images rebased at the same time.
+	Concurrency int
+	// RebaseOptions are applied to every image. Its RepoName is ignored.
+	RebaseOptions
+}
+
+// RebaseResult is the outcome of rebasing one image. Report is nil when Err is set.
+type RebaseResult struct {
+	RepoName string
+	Report   *RebaseReport
+	Err      error
+}
+
+// RebaseMany rebases several images concurrently. Run images are only fetched once and shared by all rebases using
+// them. A failure to rebase one image does not stop the others; results are returned in the order of the images.
+// Progress of individual rebases is logged at debug level.
+func (c *Client) RebaseMany(ctx context.Context, opts RebaseManyOptions) []RebaseResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultRebaseConcurrency
+	}
+
+	runImageFetcher := newSharedImageFetcher(c.imageFetcher)
+	results := make([]RebaseResult, len(opts.RepoNames))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rebaseOpts := opts.RebaseOptions
+				rebaseOpts.RepoName = opts.RepoNames[i]
+				report, err := c.rebase(ctx, rebaseOpts, runImageFetcher, c.logger.Debugf)
+				results[i] = RebaseResult{RepoName: rebaseOpts.RepoName, Report: report, Err: err}
+			}
+		}()
+	}
+
+	for i := range opts.RepoNames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// sharedImageFetcher fetches each image at most once, handing the same image (or error) to every caller.
+type sharedImageFetcher struct {
+	fetcher ImageFetcher
+	mutex   sync.Mutex
+	fetches map[sharedFetchKey]*sharedFetch
+}
+
+type sharedFetchKey struct {
+	name   string
+	daemon bool
+	pull   bool
+}
+
+type sharedFetch struct {
+	once  sync.Once
+	image imgutil.Image
+	err   error
+}
+
+func newSharedImageFetcher(fetcher ImageFetcher) *sharedImageFetcher {
+	return &sharedImageFetcher{
+		fetcher: fetcher,
+		fetches: map[sharedFetchKey]*sharedFetch{},
+	}
+}
+
+func (f *sharedImageFetcher) Fetch(ctx context.Context, name string, daemon, pull bool) (imgutil.Image, error) {
+	key := sharedFetchKey{name: name, daemon: daemon, pull: pull}
+
+	f.mutex.Lock()
+	fetch, ok := f.fetches[key]
+	if !ok {
+		fetch = &sharedFetch{}
+		f.fetches[key] = fetch
+	}
+	f.mutex.Unlock()
+
+	fetch.once.Do(func() {
+		fetch.image, fetch.err = f.fetcher.Fetch(ctx, name, daemon, pull)
+	})
+	return fetch.image, fetch.err
+}
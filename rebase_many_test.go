@@ -0,0 +1,112 @@
+package pack
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/buildpack/imgutil"
+	"github.com/buildpack/imgutil/fakes"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	ifakes "github.com/buildpack/pack/internal/fakes"
+	h "github.com/buildpack/pack/testhelpers"
+)
+
+func TestRebaseMany(t *testing.T) {
+	color.Disable(true)
+	defer func() { color.Disable(false) }()
+	spec.Run(t, "RebaseMany", testRebaseMany, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+type countingImageFetcher struct {
+	ImageFetcher
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+func (f *countingImageFetcher) Fetch(ctx context.Context, name string, daemon, pull bool) (imgutil.Image, error) {
+	f.mutex.Lock()
+	f.counts[name]++
+	f.mutex.Unlock()
+	return f.ImageFetcher.Fetch(ctx, name, daemon, pull)
+}
+
+func testRebaseMany(t *testing.T, when spec.G, it spec.S) {
+	var (
+		fakeImageFetcher *ifakes.FakeImageFetcher
+		fetcher          *countingImageFetcher
+		subject          *Client
+		appImages        []*fakes.Image
+		fakeRunImage     *fakes.Image
+		out              bytes.Buffer
+	)
+
+	it.Before(func() {
+		fakeImageFetcher = ifakes.NewFakeImageFetcher()
+		fetcher = &countingImageFetcher{ImageFetcher: fakeImageFetcher, counts: map[string]int{}}
+
+		appImages = nil
+		for _, name := range []string{"some/app1", "some/app2", "some/app3"} {
+			appImage := fakes.NewImage(name, "", "")
+			h.AssertNil(t, appImage.SetLabel("io.buildpacks.lifecycle.metadata",
+				`{"runImage":{"topLayer":"old-top-layer-sha","sha":"old-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+			fakeImageFetcher.LocalImages[name] = appImage
+			appImages = append(appImages, appImage)
+		}
+
+		fakeRunImage = fakes.NewImage("some/run", "run-image-top-layer-sha", "run-image-digest")
+		fakeImageFetcher.LocalImages["some/run"] = fakeRunImage
+
+		subject = &Client{
+			logger:       ifakes.NewFakeLogger(&out),
+			imageFetcher: fetcher,
+		}
+	})
+
+	it.After(func() {
+		for _, img := range appImages {
+			img.Cleanup()
+		}
+		fakeRunImage.Cleanup()
+	})
+
+	when("#RebaseMany", func() {
+		it("rebases every image and fetches the run image once", func() {
+			results := subject.RebaseMany(context.TODO(), RebaseManyOptions{
+				RepoNames:     []string{"some/app1", "some/app2", "some/app3"},
+				Concurrency:   2,
+				RebaseOptions: RebaseOptions{SkipPull: true},
+			})
+
+			h.AssertEq(t, len(results), 3)
+			for i, result := range results {
+				h.AssertEq(t, result.RepoName, appImages[i].Name())
+				h.AssertNil(t, result.Err)
+				h.AssertEq(t, result.Report.RebaseNeeded, true)
+				h.AssertEq(t, result.Report.OldBaseDigest, "old-digest")
+				h.AssertEq(t, result.Report.NewBaseDigest, "run-image-digest")
+				h.AssertEq(t, appImages[i].Base(), "some/run")
+				h.AssertEq(t, appImages[i].IsSaved(), true)
+			}
+			h.AssertEq(t, fetcher.counts["some/run"], 1)
+		})
+
+		it("continues when an image fails to rebase", func() {
+			results := subject.RebaseMany(context.TODO(), RebaseManyOptions{
+				RepoNames:     []string{"some/app1", "missing/app", "some/app2"},
+				RebaseOptions: RebaseOptions{SkipPull: true},
+			})
+
+			h.AssertEq(t, len(results), 3)
+			h.AssertNil(t, results[0].Err)
+			h.AssertError(t, results[1].Err, "image 'missing/app' does not exist on the daemon")
+			h.AssertEq(t, results[1].RepoName, "missing/app")
+			h.AssertNil(t, results[2].Err)
+			h.AssertEq(t, appImages[1].Base(), "some/run")
+		})
+	})
+}
@@ -6,12 +6,15 @@ import (
 	"testing"
 
 	"github.com/buildpack/imgutil/fakes"
+	"github.com/golang/mock/gomock"
 	"github.com/heroku/color"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
 	ifakes "github.com/buildpack/pack/internal/fakes"
 	h "github.com/buildpack/pack/testhelpers"
+	"github.com/buildpack/pack/testmocks"
 )
 
 func TestRebase(t *testing.T) {
@@ -71,10 +74,11 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 					})
 
 					it("uses the run image provided by the user", func() {
-						h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+						err := subject.Rebase(context.TODO(), RebaseOptions{
 							RunImage: "custom/run",
 							RepoName: "some/app",
-						}))
+						})
+						h.AssertNil(t, err)
 						h.AssertEq(t, fakeAppImage.Base(), "custom/run")
 						lbl, _ := fakeAppImage.Label("io.buildpacks.lifecycle.metadata")
 						h.AssertContains(t, lbl, `"runImage":{"topLayer":"custom-base-top-layer-sha","sha":"custom-base-digest"`)
@@ -85,9 +89,10 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 			when("run image is NOT provided by the user", func() {
 				when("the image has a label with a run image specified", func() {
 					it("uses the run image provided in the App image label", func() {
-						h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+						err := subject.Rebase(context.TODO(), RebaseOptions{
 							RepoName: "some/app",
-						}))
+						})
+						h.AssertNil(t, err)
 						h.AssertEq(t, fakeAppImage.Base(), "some/run")
 						lbl, _ := fakeAppImage.Label("io.buildpacks.lifecycle.metadata")
 						h.AssertContains(t, lbl, `"runImage":{"topLayer":"run-image-top-layer-sha","sha":"run-image-digest"`)
@@ -101,9 +106,10 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 						})
 
 						it("chooses a matching mirror from the app image label", func() {
-							h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+							err := subject.Rebase(context.TODO(), RebaseOptions{
 								RepoName: "example.com/some/app",
-							}))
+							})
+							h.AssertNil(t, err)
 							h.AssertEq(t, fakeAppImage.Base(), "example.com/some/run")
 							lbl, _ := fakeAppImage.Label("io.buildpacks.lifecycle.metadata")
 							h.AssertContains(t, lbl, `"runImage":{"topLayer":"mirror-top-layer-sha","sha":"mirror-digest"`)
@@ -125,12 +131,13 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 						})
 
 						it("chooses a matching local mirror first", func() {
-							h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+							err := subject.Rebase(context.TODO(), RebaseOptions{
 								RepoName: "example.com/some/app",
 								AdditionalMirrors: map[string][]string{
 									"some/run": {"example.com/some/local-run"},
 								},
-							}))
+							})
+							h.AssertNil(t, err)
 							h.AssertEq(t, fakeAppImage.Base(), "example.com/some/local-run")
 							lbl, _ := fakeAppImage.Label("io.buildpacks.lifecycle.metadata")
 							h.AssertContains(t, lbl, `"runImage":{"topLayer":"local-mirror-top-layer-sha","sha":"local-mirror-digest"`)
@@ -149,6 +156,140 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 
+			when("the image is already based on the run image", func() {
+				it.Before(func() {
+					h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+						`{"runImage":{"topLayer":"run-image-top-layer-sha","sha":"run-image-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+				})
+
+				it("does not rebase or save the image", func() {
+					report, err := subject.RebaseWithReport(context.TODO(), RebaseOptions{
+						RepoName: "some/app",
+						SkipPull: true,
+					})
+					h.AssertNil(t, err)
+					h.AssertEq(t, report.RebaseNeeded, false)
+					h.AssertEq(t, report.Digest, "")
+					h.AssertEq(t, fakeAppImage.Base(), "")
+					h.AssertEq(t, fakeAppImage.IsSaved(), false)
+				})
+			})
+
+			when("dry run is true", func() {
+				var (
+					mockController   *gomock.Controller
+					mockLayerFetcher *testmocks.MockLayerFetcher
+				)
+
+				it.Before(func() {
+					mockController = gomock.NewController(t)
+					mockLayerFetcher = testmocks.NewMockLayerFetcher(mockController)
+					subject.layerFetcher = mockLayerFetcher
+
+					h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+						`{"runImage":{"topLayer":"old-top-layer-sha","sha":"old-run-image-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+				})
+
+				it.After(func() {
+					mockController.Finish()
+				})
+
+				it("reports the changes without rebasing the image", func() {
+					mockLayerFetcher.EXPECT().FetchDiffIDs(gomock.Any(), "some/app", true).Return([]string{
+						"shared-layer-sha",
+						"old-top-layer-sha",
+						"app-layer-sha",
+					}, nil)
+					mockLayerFetcher.EXPECT().FetchDiffIDs(gomock.Any(), "some/run", true).Return([]string{
+						"shared-layer-sha",
+						"new-layer-sha",
+						"run-image-top-layer-sha",
+					}, nil)
+
+					report, err := subject.RebaseWithReport(context.TODO(), RebaseOptions{
+						RepoName: "some/app",
+						SkipPull: true,
+						DryRun:   true,
+					})
+					h.AssertNil(t, err)
+					h.AssertEq(t, report, &RebaseReport{
+						Image:         "some/app",
+						RunImage:      "some/run",
+						RebaseNeeded:  true,
+						OldBaseDigest: "old-run-image-digest",
+						NewBaseDigest: "run-image-digest",
+						LayersRemoved: 1,
+						LayersAdded:   2,
+					})
+					h.AssertEq(t, fakeAppImage.Base(), "")
+					h.AssertEq(t, fakeAppImage.IsSaved(), false)
+				})
+			})
+
+			when("the app image has a stack", func() {
+				it.Before(func() {
+					h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.stack.id", "some.stack"))
+					h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.stack.mixins", `["curl", "build:gcc", "run:tzdata"]`))
+				})
+
+				when("the run image is compatible", func() {
+					it.Before(func() {
+						h.AssertNil(t, fakeRunImage.SetLabel("io.buildpacks.stack.id", "some.stack"))
+						h.AssertNil(t, fakeRunImage.SetLabel("io.buildpacks.stack.mixins", `["curl", "tzdata", "git"]`))
+					})
+
+					it("rebases the image", func() {
+						err := subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+						})
+						h.AssertNil(t, err)
+						h.AssertEq(t, fakeAppImage.Base(), "some/run")
+					})
+				})
+
+				when("the run image has a different stack", func() {
+					it.Before(func() {
+						h.AssertNil(t, fakeRunImage.SetLabel("io.buildpacks.stack.id", "other.stack"))
+					})
+
+					it("returns an error", func() {
+						err := subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+						})
+						h.AssertError(t, err, "run image 'some/run' has stack 'other.stack' but app image 'some/app' was built on stack 'some.stack'")
+						h.AssertEq(t, errors.Cause(err) == ErrIncompatibleRunImage, true)
+						h.AssertEq(t, fakeAppImage.Base(), "")
+					})
+
+					when("force is true", func() {
+						it("rebases the image with a warning", func() {
+							err := subject.Rebase(context.TODO(), RebaseOptions{
+								RepoName: "some/app",
+								Force:    true,
+							})
+							h.AssertNil(t, err)
+							h.AssertEq(t, fakeAppImage.Base(), "some/run")
+							h.AssertContains(t, out.String(), "Warning: Forcing rebase: run image 'some/run' has stack 'other.stack'")
+						})
+					})
+				})
+
+				when("the run image is missing mixins", func() {
+					it.Before(func() {
+						h.AssertNil(t, fakeRunImage.SetLabel("io.buildpacks.stack.id", "some.stack"))
+						h.AssertNil(t, fakeRunImage.SetLabel("io.buildpacks.stack.mixins", `["curl"]`))
+					})
+
+					it("returns an error", func() {
+						err := subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+						})
+						h.AssertError(t, err, "run image 'some/run' is missing required mixins: tzdata")
+						h.AssertEq(t, fakeAppImage.Base(), "")
+					})
+				})
+			})
+
 			when("publish", func() {
 				var (
 					fakeRemoteRunImage *fakes.Image
@@ -166,10 +307,11 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 				when("is false", func() {
 					when("skip pull is false", func() {
 						it("updates the local image", func() {
-							h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+							err := subject.Rebase(context.TODO(), RebaseOptions{
 								RepoName: "some/app",
 								SkipPull: false,
-							}))
+							})
+							h.AssertNil(t, err)
 							h.AssertEq(t, fakeAppImage.Base(), "some/run")
 							lbl, _ := fakeAppImage.Label("io.buildpacks.lifecycle.metadata")
 							h.AssertContains(t, lbl, `"runImage":{"topLayer":"remote-top-layer-sha","sha":"remote-digest"`)
@@ -178,10 +320,11 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 
 					when("skip pull is true", func() {
 						it("uses local image", func() {
-							h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+							err := subject.Rebase(context.TODO(), RebaseOptions{
 								RepoName: "some/app",
 								SkipPull: true,
-							}))
+							})
+							h.AssertNil(t, err)
 							h.AssertEq(t, fakeAppImage.Base(), "some/run")
 							lbl, _ := fakeAppImage.Label("io.buildpacks.lifecycle.metadata")
 							h.AssertContains(t, lbl, `"runImage":{"topLayer":"run-image-top-layer-sha","sha":"run-image-digest"`)
@@ -196,10 +339,11 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 
 					when("skip pull is anything", func() {
 						it("uses remote image", func() {
-							h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+							err := subject.Rebase(context.TODO(), RebaseOptions{
 								RepoName: "some/app",
 								Publish:  true,
-							}))
+							})
+							h.AssertNil(t, err)
 							h.AssertEq(t, fakeAppImage.Base(), "some/run")
 							lbl, _ := fakeAppImage.Label("io.buildpacks.lifecycle.metadata")
 							h.AssertContains(t, lbl, `"runImage":{"topLayer":"remote-top-layer-sha","sha":"remote-digest"`)
@@ -36,6 +36,20 @@ func (m *MockDownloader) EXPECT() *MockDownloaderMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method
+func (m *MockDownloader) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockDownloaderMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDownloader)(nil).Close))
+}
+
 // Download mocks base method
 func (m *MockDownloader) Download(arg0 context.Context, arg1 string) (blob.Blob, error) {
 	m.ctrl.T.Helper()
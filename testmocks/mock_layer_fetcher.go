@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/buildpack/pack (interfaces: LayerFetcher)
+
+// Package testmocks is a generated GoMock package.
+package testmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	image "github.com/buildpack/pack/image"
+)
+
+// MockLayerFetcher is a mock of LayerFetcher interface
+type MockLayerFetcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockLayerFetcherMockRecorder
+}
+
+// MockLayerFetcherMockRecorder is the mock recorder for MockLayerFetcher
+type MockLayerFetcherMockRecorder struct {
+	mock *MockLayerFetcher
+}
+
+// NewMockLayerFetcher creates a new mock instance
+func NewMockLayerFetcher(ctrl *gomock.Controller) *MockLayerFetcher {
+	mock := &MockLayerFetcher{ctrl: ctrl}
+	mock.recorder = &MockLayerFetcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLayerFetcher) EXPECT() *MockLayerFetcherMockRecorder {
+	return m.recorder
+}
+
+// FetchDiffIDs mocks base method
+func (m *MockLayerFetcher) FetchDiffIDs(arg0 context.Context, arg1 string, arg2 bool) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDiffIDs", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDiffIDs indicates an expected call of FetchDiffIDs
+func (mr *MockLayerFetcherMockRecorder) FetchDiffIDs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDiffIDs", reflect.TypeOf((*MockLayerFetcher)(nil).FetchDiffIDs), arg0, arg1, arg2)
+}
+
+// FetchLayers mocks base method
+func (m *MockLayerFetcher) FetchLayers(arg0 context.Context, arg1 string, arg2 bool) ([]image.Layer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchLayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]image.Layer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchLayers indicates an expected call of FetchLayers
+func (mr *MockLayerFetcherMockRecorder) FetchLayers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLayers", reflect.TypeOf((*MockLayerFetcher)(nil).FetchLayers), arg0, arg1, arg2)
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/buildpack/pack (interfaces: PlatformFetcher)
+
+// Package testmocks is a generated GoMock package.
+package testmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	image "github.com/buildpack/pack/image"
+)
+
+// MockPlatformFetcher is a mock of PlatformFetcher interface
+type MockPlatformFetcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPlatformFetcherMockRecorder
+}
+
+// MockPlatformFetcherMockRecorder is the mock recorder for MockPlatformFetcher
+type MockPlatformFetcherMockRecorder struct {
+	mock *MockPlatformFetcher
+}
+
+// NewMockPlatformFetcher creates a new mock instance
+func NewMockPlatformFetcher(ctrl *gomock.Controller) *MockPlatformFetcher {
+	mock := &MockPlatformFetcher{ctrl: ctrl}
+	mock.recorder = &MockPlatformFetcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPlatformFetcher) EXPECT() *MockPlatformFetcherMockRecorder {
+	return m.recorder
+}
+
+// FetchPlatform mocks base method
+func (m *MockPlatformFetcher) FetchPlatform(arg0 context.Context, arg1 string, arg2 bool) (image.Platform, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchPlatform", arg0, arg1, arg2)
+	ret0, _ := ret[0].(image.Platform)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchPlatform indicates an expected call of FetchPlatform
+func (mr *MockPlatformFetcherMockRecorder) FetchPlatform(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPlatform", reflect.TypeOf((*MockPlatformFetcher)(nil).FetchPlatform), arg0, arg1, arg2)
+}